@@ -2,34 +2,110 @@ package main
 
 import (
 	"context"
-
+	"fmt"
+	"net/http"
+	"os"
+	// Embeds the zone database so TIME_ZONE and zoneinfo claims resolve on
+	// runtimes that ship without one.
+	_ "time/tzdata"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	
+
 	adapter "github.com/awslabs/aws-lambda-go-api-proxy/chi"
 	"github.com/go-chi/chi/v5"
 
+	"github.com/joaoleau/muquirango/internal/auth"
 	"github.com/joaoleau/muquirango/internal/config"
-	"github.com/joaoleau/muquirango/internal/router"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/cursor"
 	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/router"
+	"go.uber.org/zap"
 )
 
 var chiLambda *adapter.ChiLambda
 
 func main() {
-    db, table := config.DynamoClient(context.Background())
-    chiRouter := chi.NewRouter()
-    
-	repositories := &router.Repositories{
-		TransactionRepo: repository.NewTransactionRepository(db, table),
+	ctx := context.Background()
+	chiRouter := chi.NewRouter()
+
+	verifier, err := auth.NewVerifier(config.AuthConfig())
+	if err != nil {
+		logger.Error("failed to configure authentication: ", err)
+		os.Exit(1)
+	}
+
+	repositories, err := newRepositories(ctx)
+	if err != nil {
+		logger.Error("failed to configure storage: ", err)
+		os.Exit(1)
+	}
+
+	router.RegisterRoutes(chiRouter, repositories, verifier, cursor.NewCodec(config.CursorSecret()), config.BaseCurrency())
+
+	if addr := config.HTTPAddr(); addr != "" {
+		logger.Info("Serving HTTP", zap.String("addr", addr))
+		if err := http.ListenAndServe(addr, chiRouter); err != nil {
+			logger.Error("HTTP server stopped: ", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	router.RegisterRoutes(chiRouter, repositories)
-    chiLambda = adapter.New(chiRouter)
-    lambda.Start(handler)
+	chiLambda = adapter.New(chiRouter)
+	lambda.Start(handler)
+}
+
+func newRepositories(ctx context.Context) (*router.Repositories, error) {
+	switch backend := config.StorageBackend(); backend {
+	case config.StorageDynamoDB:
+		db, table := config.DynamoClient(ctx)
+		return &router.Repositories{
+			TransactionRepo:  repository.NewTransactionRepository(db, table),
+			CategoryRepo:     repository.NewCategoryRepository(db, table),
+			BudgetRepo:       repository.NewBudgetRepository(db, table),
+			RecurrenceRepo:   repository.NewRecurrenceRepository(db, table),
+			InstallmentRepo:  repository.NewInstallmentRepository(db, table),
+			AccountRepo:      repository.NewAccountRepository(db, table),
+			ExchangeRateRepo: repository.NewExchangeRateRepository(db, table),
+		}, nil
+
+	case config.StorageSQLite:
+		db, err := config.SQLiteClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.MigrateSQLite(ctx, db); err != nil {
+			return nil, err
+		}
+		return &router.Repositories{
+			TransactionRepo:  repository.NewSQLiteTransactionRepository(db),
+			CategoryRepo:     repository.NewSQLiteCategoryRepository(db),
+			BudgetRepo:       repository.NewSQLiteBudgetRepository(db),
+			RecurrenceRepo:   repository.NewSQLiteRecurrenceRepository(db),
+			InstallmentRepo:  repository.NewSQLiteInstallmentRepository(db),
+			AccountRepo:      repository.NewSQLiteAccountRepository(db),
+			ExchangeRateRepo: repository.NewSQLiteExchangeRateRepository(db),
+		}, nil
+
+	case config.StorageMemory:
+		transactions := repository.NewMemoryTransactionRepository()
+		return &router.Repositories{
+			TransactionRepo:  transactions,
+			CategoryRepo:     repository.NewMemoryCategoryRepository(),
+			BudgetRepo:       repository.NewMemoryBudgetRepository(),
+			RecurrenceRepo:   repository.NewMemoryRecurrenceRepository(),
+			InstallmentRepo:  repository.NewMemoryInstallmentRepository(transactions),
+			AccountRepo:      repository.NewMemoryAccountRepository(),
+			ExchangeRateRepo: repository.NewMemoryExchangeRateRepository(),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown storage backend '%s'", backend)
+	}
 }
 
 func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-    return chiLambda.ProxyWithContext(ctx, request)
+	return chiLambda.ProxyWithContext(ctx, request)
 }
@@ -0,0 +1,79 @@
+// Command rebuild-aggregates recomputes the monthly aggregates of each owner
+// named on the command line from their transactions, repairing aggregates
+// that drifted. Run it while those owners are not writing:
+//
+//	rebuild-aggregates alice bob
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joaoleau/muquirango/internal/config"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: rebuild-aggregates owner...")
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	transactions, err := newTransactionRepository(ctx)
+	if err != nil {
+		logger.Error("failed to configure storage: ", err)
+		os.Exit(1)
+	}
+
+	if err := rebuild(ctx, transactions, flag.Args()); err != nil {
+		os.Exit(1)
+	}
+}
+
+// rebuild recomputes the aggregates of every owner, going on past owners
+// that fail so one of them does not hold up the rest.
+func rebuild(ctx context.Context, transactions repository.TransactionRepository, owners []string) error {
+	var errs []error
+	for _, owner := range owners {
+		aggregates, err := transactions.RebuildMonthlyAggregates(ctx, owner)
+		if err != nil {
+			logger.Error("failed to rebuild monthly aggregates: ", err, zap.String("owner", owner))
+			errs = append(errs, fmt.Errorf("owner '%s': %w", owner, err))
+			continue
+		}
+		logger.Info("Monthly aggregates rebuilt", zap.String("owner", owner), zap.Int("count", len(aggregates)))
+	}
+	return errors.Join(errs...)
+}
+
+func newTransactionRepository(ctx context.Context) (repository.TransactionRepository, error) {
+	switch backend := config.StorageBackend(); backend {
+	case config.StorageDynamoDB:
+		db, table := config.DynamoClient(ctx)
+		return repository.NewTransactionRepository(db, table), nil
+
+	case config.StorageSQLite:
+		db, err := config.SQLiteClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.MigrateSQLite(ctx, db); err != nil {
+			return nil, err
+		}
+		return repository.NewSQLiteTransactionRepository(db), nil
+
+	default:
+		return nil, fmt.Errorf("storage backend '%s' keeps no aggregates to rebuild", backend)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/repository/repositorytest"
+)
+
+// Aggregates are only stored, and so can only drift, on DynamoDB.
+func TestRebuildRecomputesDriftedAggregates(t *testing.T) {
+	ctx := context.Background()
+	db, table := repositorytest.OpenDynamoDBLocal(t)
+	transactions := repository.NewTransactionRepository(db, table)
+
+	october := time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)
+	for _, owner := range []string{"alice", "alice", "bob"} {
+		if _, err := transactions.NewTransaction(ctx, repositorytest.NewTransaction(owner, october), nil); err != nil {
+			t.Fatalf("NewTransaction: %v", err)
+		}
+	}
+
+	// Overwrite October with wrong sums and leave a month no transaction
+	// backs, as a lost or repeated write would.
+	drift := func(owner string, month string, purchases int) {
+		aggregate := model.MonthlyAggregate{OwnerID: owner, Month: month, Currency: "BRL", Purchases: purchases, PurchaseCount: 1}
+		aggregate.SetKeys()
+		item, err := attributevalue.MarshalMap(&aggregate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(table), Item: item}); err != nil {
+			t.Fatalf("PutItem: %v", err)
+		}
+	}
+	drift("alice", "2026-10", 99)
+	drift("alice", "2026-08", 500)
+	drift("bob", "2026-10", 99)
+
+	if err := rebuild(ctx, transactions, []string{"alice"}); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+
+	aggregates, err := transactions.ListMonthlyAggregates(ctx, "alice", "2026-01", "2026-12")
+	if err != nil {
+		t.Fatalf("ListMonthlyAggregates: %v", err)
+	}
+	if len(aggregates) != 1 || aggregates[0].Month != "2026-10" || aggregates[0].Purchases != 3000 || aggregates[0].PurchaseCount != 2 {
+		t.Fatalf("alice's aggregates after the rebuild: %+v", aggregates)
+	}
+
+	// Owners not named are left alone.
+	aggregates, err = transactions.ListMonthlyAggregates(ctx, "bob", "2026-10", "2026-10")
+	if err != nil {
+		t.Fatalf("ListMonthlyAggregates: %v", err)
+	}
+	if len(aggregates) != 1 || aggregates[0].Purchases != 99 {
+		t.Fatalf("bob's aggregates after alice's rebuild: %+v", aggregates)
+	}
+}
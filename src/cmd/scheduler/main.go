@@ -0,0 +1,99 @@
+// Command scheduler materializes due recurring transactions. On Lambda it
+// runs on every scheduled EventBridge event; with -local it runs once and
+// exits, which is what cron or a developer machine needs. Each recurrence is
+// due by the day in the time zone of the owner who saved it; recurrences
+// saved without one fall back to the TIME_ZONE of the deployment. Runs
+// should therefore come at least daily, and hourly keeps occurrences from
+// waiting on owners far from TIME_ZONE.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+	// Embeds the zone database so TIME_ZONE resolves on runtimes that ship
+	// without one.
+	_ "time/tzdata"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/joaoleau/muquirango/internal/config"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/recurrence"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+var materializer *recurrence.Materializer
+
+func main() {
+	local := flag.Bool("local", false, "run once and exit instead of waiting for Lambda events")
+	date := flag.String("date", "", "materialize as if it were noon of this date (YYYY-MM-DD) in TIME_ZONE, with -local")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	var err error
+	materializer, err = newMaterializer(ctx)
+	if err != nil {
+		logger.Error("failed to configure storage: ", err)
+		os.Exit(1)
+	}
+
+	if !*local {
+		lambda.Start(handler)
+		return
+	}
+
+	now := time.Now().In(config.TimeZone())
+	if *date != "" {
+		day, err := time.ParseInLocation(recurrence.DateLayout, *date, config.TimeZone())
+		if err != nil {
+			logger.Error("invalid -date: ", err)
+			os.Exit(1)
+		}
+		now = day.Add(12 * time.Hour)
+	}
+
+	if _, err := materializer.Run(ctx, now); err != nil {
+		logger.Error("failed to materialize recurrences: ", err)
+		os.Exit(1)
+	}
+}
+
+func newMaterializer(ctx context.Context) (*recurrence.Materializer, error) {
+	switch backend := config.StorageBackend(); backend {
+	case config.StorageDynamoDB:
+		db, table := config.DynamoClient(ctx)
+		return recurrence.NewMaterializer(
+			repository.NewRecurrenceRepository(db, table),
+			repository.NewTransactionRepository(db, table),
+		), nil
+
+	case config.StorageSQLite:
+		db, err := config.SQLiteClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.MigrateSQLite(ctx, db); err != nil {
+			return nil, err
+		}
+		return recurrence.NewMaterializer(
+			repository.NewSQLiteRecurrenceRepository(db),
+			repository.NewSQLiteTransactionRepository(db),
+		), nil
+
+	default:
+		return nil, fmt.Errorf("storage backend '%s' is not supported by the scheduler", backend)
+	}
+}
+
+func handler(ctx context.Context, event events.EventBridgeEvent) error {
+	logger.Info("Received scheduled event", zap.String("event_id", event.ID), zap.Time("time", event.Time))
+
+	_, err := materializer.Run(ctx, event.Time.In(config.TimeZone()))
+	return err
+}
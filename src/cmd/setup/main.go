@@ -0,0 +1,21 @@
+// Command setup creates the DynamoDB table and its indexes.
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/joaoleau/muquirango/internal/config"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+func main() {
+	ctx := context.Background()
+	db, table := config.DynamoClient(ctx)
+
+	if err := repository.CreateTable(ctx, db, table); err != nil {
+		logger.Error("failed to set up table: ", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+type ownerKey struct{}
+
+type locationKey struct{}
+
+// WithOwner returns a copy of ctx carrying the identity that owns the ledger
+// being accessed.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// OwnerFromContext returns the owner identity stored by WithOwner.
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	owner, ok := ctx.Value(ownerKey{}).(string)
+	return owner, ok && owner != ""
+}
+
+// WithLocation returns a copy of ctx carrying the time zone of the owner,
+// which decides the day a transaction falls on.
+func WithLocation(ctx context.Context, location *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, location)
+}
+
+// LocationFromContext returns the time zone stored by WithLocation, or UTC.
+func LocationFromContext(ctx context.Context) *time.Location {
+	if location, ok := ctx.Value(locationKey{}).(*time.Location); ok && location != nil {
+		return location
+	}
+	return time.UTC
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"go.uber.org/zap"
+)
+
+// Config holds the key material used to validate bearer tokens. At least one
+// of HMACSecret, RSAPublicKeyFile or JWKSFile must be provided. Location is
+// the time zone of users whose token carries no zoneinfo claim; nil means
+// UTC.
+type Config struct {
+	HMACSecret       string
+	RSAPublicKeyFile string
+	JWKSFile         string
+	Issuer           string
+	Audience         string
+	Leeway           time.Duration
+	Location         *time.Location
+}
+
+// Identity is what a valid token says about its bearer.
+type Identity struct {
+	Subject string
+	// Location is the time zone of the zoneinfo claim, or the configured
+	// default when the claim is missing or names no known zone.
+	Location *time.Location
+}
+
+// claims are the registered claims plus the OpenID Connect zoneinfo claim,
+// an IANA time zone name like America/Sao_Paulo.
+type claims struct {
+	jwt.RegisteredClaims
+	Zoneinfo string `json:"zoneinfo,omitempty"`
+}
+
+// Verifier validates HS256 and RS256 tokens against locally configured keys,
+// so it never needs network access.
+type Verifier struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	jwks       map[string]*rsa.PublicKey
+	parser     *jwt.Parser
+	location   *time.Location
+}
+
+func NewVerifier(cfg Config) (*Verifier, error) {
+	v := &Verifier{jwks: map[string]*rsa.PublicKey{}, location: cfg.Location}
+	if v.location == nil {
+		v.location = time.UTC
+	}
+
+	if cfg.HMACSecret != "" {
+		v.hmacSecret = []byte(cfg.HMACSecret)
+	}
+
+	if cfg.RSAPublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RSA public key: %w", err)
+		}
+		v.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA public key: %w", err)
+		}
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.jwks = keys
+	}
+
+	if v.hmacSecret == nil && v.rsaKey == nil && len(v.jwks) == 0 {
+		return nil, errors.New("no JWT verification key configured")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(options...)
+
+	return v, nil
+}
+
+// Subject validates the raw token and returns its subject claim.
+func (v *Verifier) Subject(raw string) (string, error) {
+	identity, err := v.Identify(raw)
+	if err != nil {
+		return "", err
+	}
+	return identity.Subject, nil
+}
+
+// Identify validates the raw token and returns the identity it carries.
+func (v *Verifier) Identify(raw string) (*Identity, error) {
+	parsed := &claims{}
+	if _, err := v.parser.ParseWithClaims(raw, parsed, v.key); err != nil {
+		return nil, err
+	}
+	if parsed.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	identity := &Identity{Subject: parsed.Subject, Location: v.location}
+	if parsed.Zoneinfo != "" {
+		location, err := time.LoadLocation(parsed.Zoneinfo)
+		if err != nil {
+			logger.Error("Ignoring unknown zoneinfo claim", err, zap.String("zoneinfo", parsed.Zoneinfo))
+		} else {
+			identity.Location = location
+		}
+	}
+	return identity, nil
+}
+
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if v.hmacSecret == nil {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return v.hmacSecret, nil
+
+	case jwt.SigningMethodRS256.Alg():
+		if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+			if key, ok := v.jwks[kid]; ok {
+				return key, nil
+			}
+			return nil, fmt.Errorf("unknown key id '%s'", kid)
+		}
+		if v.rsaKey != nil {
+			return v.rsaKey, nil
+		}
+		if len(v.jwks) == 1 {
+			for _, key := range v.jwks {
+				return key, nil
+			}
+		}
+		return nil, errors.New("RS256 token without a resolvable key")
+	}
+
+	return nil, fmt.Errorf("unexpected signing method '%s'", token.Method.Alg())
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS file: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key '%s': %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key '%s': %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS file contains no RSA signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+)
+
+// Middleware validates the bearer token of every request and stores its
+// subject claim and time zone in the request context. Missing, malformed or
+// expired tokens are rejected with 401.
+func Middleware(v *Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := bearerToken(r)
+			if !ok {
+				logger.Info("Rejected request without bearer token")
+				unauthorized(w, "missing bearer token")
+				return
+			}
+
+			identity, err := v.Identify(raw)
+			if err != nil {
+				logger.Error("Rejected invalid bearer token", err)
+				unauthorized(w, "invalid or expired token")
+				return
+			}
+
+			ctx := WithOwner(r.Context(), identity.Subject)
+			ctx = WithLocation(ctx, identity.Location)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="muquirango"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": message, "code": "unauthenticated"})
+}
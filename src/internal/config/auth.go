@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/auth"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+)
+
+var (
+	JWT_HS256_SECRET          = "JWT_HS256_SECRET"
+	JWT_RS256_PUBLIC_KEY_FILE = "JWT_RS256_PUBLIC_KEY_FILE"
+	JWT_JWKS_FILE             = "JWT_JWKS_FILE"
+	JWT_ISSUER                = "JWT_ISSUER"
+	JWT_AUDIENCE              = "JWT_AUDIENCE"
+	JWT_LEEWAY                = "JWT_LEEWAY"
+	TIME_ZONE                 = "TIME_ZONE"
+)
+
+// AuthConfig reads the JWT verification settings from the environment.
+func AuthConfig() auth.Config {
+	leeway, err := time.ParseDuration(os.Getenv(JWT_LEEWAY))
+	if err != nil {
+		leeway = 30 * time.Second
+	}
+
+	return auth.Config{
+		HMACSecret:       os.Getenv(JWT_HS256_SECRET),
+		RSAPublicKeyFile: os.Getenv(JWT_RS256_PUBLIC_KEY_FILE),
+		JWKSFile:         os.Getenv(JWT_JWKS_FILE),
+		Issuer:           os.Getenv(JWT_ISSUER),
+		Audience:         os.Getenv(JWT_AUDIENCE),
+		Leeway:           leeway,
+		Location:         TimeZone(),
+	}
+}
+
+// TimeZone returns the time zone named by TIME_ZONE, like America/Sao_Paulo,
+// used for users whose token names none. It falls back to UTC when unset or
+// unknown.
+func TimeZone() *time.Location {
+	name := os.Getenv(TIME_ZONE)
+	if name == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		logger.Error("Unknown TIME_ZONE, using UTC", err)
+		return time.UTC
+	}
+	return location
+}
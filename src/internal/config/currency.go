@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+)
+
+var BASE_CURRENCY = "BASE_CURRENCY"
+
+// defaultBaseCurrency is used when BASE_CURRENCY is unset or invalid.
+const defaultBaseCurrency = "BRL"
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// BaseCurrency returns the ISO 4217 code named by BASE_CURRENCY, like USD:
+// the currency reports convert every amount to. It falls back to BRL when
+// unset or malformed.
+func BaseCurrency() string {
+	currency := strings.ToUpper(strings.TrimSpace(os.Getenv(BASE_CURRENCY)))
+	if currency == "" {
+		return defaultBaseCurrency
+	}
+	if !currencyCode.MatchString(currency) {
+		logger.Error("Invalid BASE_CURRENCY, using BRL", fmt.Errorf("'%s' is not an ISO 4217 code", currency))
+		return defaultBaseCurrency
+	}
+	return currency
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"crypto/rand"
+	"errors"
+	"os"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+)
+
+var CURSOR_SECRET = "CURSOR_SECRET"
+
+// CursorSecret returns the key used to sign pagination cursors. Without
+// CURSOR_SECRET a random key is generated, which invalidates cursors on
+// every cold start.
+func CursorSecret() []byte {
+	if secret := os.Getenv(CURSOR_SECRET); secret != "" {
+		return []byte(secret)
+	}
+
+	logger.Error("CURSOR_SECRET not set, using an ephemeral key", errors.New("missing cursor secret"))
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return secret
+}
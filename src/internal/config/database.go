@@ -10,7 +10,7 @@ import (
 )
 
 var client *dynamodb.Client
-var tableName string = "Muquirango" 
+var tableName string = "Muquirango"
 
 // func init() {
 // 	cfg, err := config.LoadDefaultConfig(context.Background())
@@ -9,22 +9,22 @@ import (
 )
 
 var (
-	log *zap.Logger
+	log        *zap.Logger
 	LOG_OUTPUT = "LOG_OUTPUT"
-	LOG_LEVEL = "LOG_LEVEL"
+	LOG_LEVEL  = "LOG_LEVEL"
 )
 
 func init() {
-	logConfig := zap.Config {
+	logConfig := zap.Config{
 		OutputPaths: []string{getOutputLogs()},
-		Level: zap.NewAtomicLevelAt(getLevelLogs()),
-		Encoding: "json",
+		Level:       zap.NewAtomicLevelAt(getLevelLogs()),
+		Encoding:    "json",
 		EncoderConfig: zapcore.EncoderConfig{
-			LevelKey: "level",
-			TimeKey: "time",
-			MessageKey: "message",
-			EncodeTime: zapcore.ISO8601TimeEncoder,
-			EncodeLevel: zapcore.LowercaseLevelEncoder,
+			LevelKey:     "level",
+			TimeKey:      "time",
+			MessageKey:   "message",
+			EncodeTime:   zapcore.ISO8601TimeEncoder,
+			EncodeLevel:  zapcore.LowercaseLevelEncoder,
 			EncodeCaller: zapcore.ShortCallerEncoder,
 		},
 	}
@@ -32,12 +32,12 @@ func init() {
 	log, _ = logConfig.Build()
 }
 
-func Info(message string, tags...zap.Field) {
+func Info(message string, tags ...zap.Field) {
 	log.Info(message, tags...)
 	log.Sync()
 }
 
-func Error(message string, err error, tags...zap.Field) {
+func Error(message string, err error, tags ...zap.Field) {
 	tags = append(tags, zap.NamedError("error", err))
 	log.Error(message, tags...)
 	log.Sync()
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	StorageDynamoDB = "dynamodb"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+var (
+	STORAGE_BACKEND = "STORAGE_BACKEND"
+	SQLITE_PATH     = "SQLITE_PATH"
+	HTTP_ADDR       = "HTTP_ADDR"
+)
+
+// StorageBackend returns the configured persistence backend, defaulting to
+// DynamoDB.
+func StorageBackend() string {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv(STORAGE_BACKEND)))
+	if backend == "" {
+		return StorageDynamoDB
+	}
+	return backend
+}
+
+// HTTPAddr returns the address to serve on when running outside Lambda.
+// An empty value means the binary runs as a Lambda function.
+func HTTPAddr() string {
+	return strings.TrimSpace(os.Getenv(HTTP_ADDR))
+}
+
+// SQLiteClient opens the SQLite database file configured by SQLITE_PATH.
+func SQLiteClient(ctx context.Context) (*sql.DB, error) {
+	path := strings.TrimSpace(os.Getenv(SQLITE_PATH))
+	if path == "" {
+		path = "muquirango.db"
+	}
+
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	// SQLite allows a single writer; serialising connections avoids
+	// SQLITE_BUSY under concurrent requests.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"regexp"
+
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+type CreateAccountInput struct {
+	Name           string            `json:"name"`
+	Kind           model.AccountKind `json:"kind"`
+	Currency       string            `json:"currency,omitempty"`
+	OpeningBalance int               `json:"opening_balance"`
+	ClosingDay     int               `json:"closing_day,omitempty"`
+	DueDay         int               `json:"due_day,omitempty"`
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Za-z]{3}$`)
+
+// IsCurrencyCode reports whether value is shaped like an ISO 4217 code.
+func IsCurrencyCode(value string) bool {
+	return currencyCode.MatchString(value)
+}
+
+func (i *CreateAccountInput) Validate() error {
+	check := validation.Checker{}
+	check.Required("name", i.Name)
+	check.MaxLength("name", i.Name, maxNameLength)
+	check.OneOf("kind", string(i.Kind),
+		string(model.AccountKindChecking),
+		string(model.AccountKindSavings),
+		string(model.AccountKindCash),
+		string(model.AccountKindCreditCard),
+		string(model.AccountKindInvestment),
+	)
+	if i.Currency != "" {
+		check.Matches("currency", i.Currency, currencyCode, "an ISO 4217 code such as BRL")
+	}
+
+	if i.Kind == model.AccountKindCreditCard {
+		check.Between("closing_day", i.ClosingDay, 1, 31)
+		check.Between("due_day", i.DueDay, 1, 31)
+	} else {
+		check.Check(i.ClosingDay == 0, "closing_day", "only applies to credit cards")
+		check.Check(i.DueDay == 0, "due_day", "only applies to credit cards")
+	}
+	return check.Err()
+}
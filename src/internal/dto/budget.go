@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+type CreateBudgetInput struct {
+	Name       string                 `json:"name"`
+	CategoryID *string                `json:"category_id,omitempty"`
+	Type       *model.TransactionType `json:"type,omitempty"`
+	Limit      int                    `json:"limit"`
+	StartMonth string                 `json:"start_month"`
+	EndMonth   *string                `json:"end_month,omitempty"`
+	Rollover   bool                   `json:"rollover"`
+}
+
+func (i *CreateBudgetInput) Validate() error {
+	check := validation.Checker{}
+	check.Required("name", i.Name)
+	check.MaxLength("name", i.Name, maxNameLength)
+	check.Check((i.CategoryID == nil) != (i.Type == nil), "category_id", "exactly one of category_id or type is required")
+	if i.Type != nil {
+		check.OneOf("type", string(*i.Type), flowTypes...)
+	}
+	check.Positive("limit", i.Limit)
+	check.Date("start_month", i.StartMonth, model.MonthLayout, "2026-01")
+	if i.EndMonth != nil {
+		check.Date("end_month", *i.EndMonth, model.MonthLayout, "2026-01")
+		check.Check(*i.EndMonth >= i.StartMonth, "end_month", "must not be before start_month")
+	}
+	return check.Err()
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+// PayStatementInput pays a card statement from account_id. A zero amount
+// pays whatever remains.
+type PayStatementInput struct {
+	AccountID string `json:"account_id"`
+	Amount    int    `json:"amount,omitempty"`
+}
+
+func (i *PayStatementInput) Validate() error {
+	check := validation.Checker{}
+	check.Required("account_id", i.AccountID)
+	check.Check(i.Amount >= 0, "amount", "must not be negative")
+	return check.Err()
+}
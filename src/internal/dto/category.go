@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+type CreateCategoryInput struct {
+	Name     string  `json:"name"`
+	Color    *string `json:"color,omitempty"`
+	Icon     *string `json:"icon,omitempty"`
+	ParentID *string `json:"parent_id,omitempty"`
+}
+
+func (i *CreateCategoryInput) Validate() error {
+	check := validation.Checker{}
+	check.Required("name", i.Name)
+	check.MaxLength("name", i.Name, maxNameLength)
+	return check.Err()
+}
@@ -0,0 +1,99 @@
+package dto
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+// CreateExchangeRateInput is one exchange rate: what one unit of base was
+// worth in quote on date. Rate is a positive decimal sent as a JSON number
+// or string.
+type CreateExchangeRateInput struct {
+	Base  string      `json:"base"`
+	Quote string      `json:"quote"`
+	Date  string      `json:"date"`
+	Rate  json.Number `json:"rate"`
+}
+
+func (i *CreateExchangeRateInput) Validate() error {
+	check := validation.Checker{}
+	i.check(&check, "")
+	return check.Err()
+}
+
+// check validates the fields of i, naming each one after prefix.
+func (i *CreateExchangeRateInput) check(check *validation.Checker, prefix string) {
+	check.Matches(prefix+"base", i.Base, currencyCode, "an ISO 4217 code such as USD")
+	check.Matches(prefix+"quote", i.Quote, currencyCode, "an ISO 4217 code such as BRL")
+	check.Check(model.NormalizeCurrency(i.Base) != model.NormalizeCurrency(i.Quote), prefix+"quote", "must differ from base")
+	check.Date(prefix+"date", i.Date, "2006-01-02", dateExample)
+	_, ok := model.ParseRate(i.Rate.String())
+	check.Check(ok, prefix+"rate", "must be a positive decimal like 5.4321")
+}
+
+// exchangeRateColumns are the columns of an exchange rate CSV file, in
+// order.
+var exchangeRateColumns = []string{"date", "base", "quote", "rate"}
+
+// ParseExchangeRatesCSV reads exchange rates from CSV lines of date, base,
+// quote and rate, like 2026-01-31,USD,BRL,5.4321. A first line naming the
+// columns is skipped. Every bad line is reported, each field named after
+// its line, like "line 3: rate".
+func ParseExchangeRatesCSV(r io.Reader) ([]CreateExchangeRateInput, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	check := validation.Checker{}
+	inputs := []CreateExchangeRateInput{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			check.Add(fmt.Sprintf("line %d", parseErr.Line), parseErr.Err.Error())
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		// A byte order mark is what spreadsheets put at the start of a
+		// file saved as UTF-8.
+		record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), exchangeRateColumns[0]) {
+			continue
+		}
+		if len(record) != len(exchangeRateColumns) {
+			check.Add(fmt.Sprintf("line %d", line), "must have the columns "+strings.Join(exchangeRateColumns, ","))
+			continue
+		}
+
+		input := CreateExchangeRateInput{
+			Date:  strings.TrimSpace(record[0]),
+			Base:  strings.TrimSpace(record[1]),
+			Quote: strings.TrimSpace(record[2]),
+			Rate:  json.Number(strings.TrimSpace(record[3])),
+		}
+		input.check(&check, fmt.Sprintf("line %d: ", line))
+		inputs = append(inputs, input)
+	}
+
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, validation.Errors{{Field: "body", Message: "holds no exchange rates"}}
+	}
+	return inputs, nil
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+type UpdateInstallmentPlanInput struct {
+	Title       string   `json:"title"`
+	Description *string  `json:"description,omitempty"`
+	CategoryID  *string  `json:"category_id,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+func (i *UpdateInstallmentPlanInput) Validate() error {
+	check := validation.Checker{}
+	check.Required("title", i.Title)
+	check.MaxLength("title", i.Title, maxTitleLength)
+	check.MaxLength("description", description(i.Description), maxDescriptionLength)
+	return check.Err()
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+// Length limits for free text that ends up in listings.
+const (
+	maxTitleLength       = 200
+	maxNameLength        = 100
+	maxDescriptionLength = 2000
+)
+
+const dateExample = "2026-01-31"
+
+// flowTypes are the transaction types that bring money in or take it out,
+// as opposed to moving it between accounts.
+var flowTypes = []string{
+	string(model.TransactionTypePurchase),
+	string(model.TransactionTypeIncome),
+	string(model.TransactionTypeInvestment),
+}
+
+var transactionTypes = []string{
+	string(model.TransactionTypePurchase),
+	string(model.TransactionTypeIncome),
+	string(model.TransactionTypeInvestment),
+	string(model.TransactionTypeTransfer),
+}
+
+func description(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
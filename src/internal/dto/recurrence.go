@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+type CreateRecurrenceInput struct {
+	RRule       string                `json:"rrule"`
+	StartDate   string                `json:"start_date"`
+	Type        model.TransactionType `json:"type"`
+	Title       string                `json:"title"`
+	Description *string               `json:"description,omitempty"`
+	Amount      int                   `json:"amount"`
+	Currency    string                `json:"currency,omitempty"`
+	CategoryID  *string               `json:"category_id,omitempty"`
+	AccountID   *string               `json:"account_id,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+}
+
+// UpdateRecurrenceInput replaces a recurrence. Version is the version the
+// client read; it may come in the If-Match header instead.
+type UpdateRecurrenceInput struct {
+	CreateRecurrenceInput
+	Version *int `json:"version,omitempty"`
+}
+
+// Validate checks the fields on their own. The rule itself is parsed by the
+// handler, which needs the result.
+func (i *CreateRecurrenceInput) Validate() error {
+	check := validation.Checker{}
+	check.Required("rrule", i.RRule)
+	check.Date("start_date", i.StartDate, "2006-01-02", dateExample)
+	check.OneOf("type", string(i.Type), flowTypes...)
+	check.Required("title", i.Title)
+	check.MaxLength("title", i.Title, maxTitleLength)
+	check.MaxLength("description", description(i.Description), maxDescriptionLength)
+	check.Positive("amount", i.Amount)
+	if i.Currency != "" {
+		check.Matches("currency", i.Currency, currencyCode, "an ISO 4217 code such as BRL")
+	}
+	return check.Err()
+}
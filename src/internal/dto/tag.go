@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+type RenameTagInput struct {
+	Name string `json:"name"`
+}
+
+func (i *RenameTagInput) Validate() error {
+	check := validation.Checker{}
+	check.Required("name", i.Name)
+	check.MaxLength("name", i.Name, maxNameLength)
+	return check.Err()
+}
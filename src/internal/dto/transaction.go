@@ -1,12 +1,119 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/validation"
 )
 
 type CreateTransactionInput struct {
-	Type        model.TransactionType `json:"type"`
-	Title       string                `json:"title"`
-	Description *string               `json:"description,omitempty"`
-	Amount      int                   `json:"amount"`
+	Type         model.TransactionType `json:"type"`
+	Title        string                `json:"title"`
+	Description  *string               `json:"description,omitempty"`
+	Amount       int                   `json:"amount"`
+	Currency     string                `json:"currency,omitempty"`
+	CategoryID   *string               `json:"category_id,omitempty"`
+	AccountID    *string               `json:"account_id,omitempty"`
+	Tags         []string              `json:"tags,omitempty"`
+	Installments int                   `json:"installments,omitempty"`
+	ToAccountID  *string               `json:"to_account_id,omitempty"`
+	// OccurredAt dates the transaction; it defaults to now. See
+	// ParseTimestamp for the accepted forms.
+	OccurredAt *string `json:"occurred_at,omitempty"`
+}
+
+// UpdateTransactionInput replaces a transaction. Version is the version the
+// client read; it may come in the If-Match header instead.
+type UpdateTransactionInput struct {
+	CreateTransactionInput
+	Version *int `json:"version,omitempty"`
+}
+
+func (i *CreateTransactionInput) Validate() error {
+	check := validation.Checker{}
+	check.OneOf("type", string(i.Type), transactionTypes...)
+	check.Required("title", i.Title)
+	check.MaxLength("title", i.Title, maxTitleLength)
+	check.MaxLength("description", description(i.Description), maxDescriptionLength)
+	check.Positive("amount", i.Amount)
+	if i.Currency != "" {
+		check.Matches("currency", i.Currency, currencyCode, "an ISO 4217 code such as BRL")
+	}
+	check.Check(i.Installments >= 0, "installments", "must not be negative")
+	if i.OccurredAt != nil {
+		checkTimestamp(&check, "occurred_at", *i.OccurredAt)
+	}
+
+	if i.Installments > 1 {
+		check.Check(i.Type == model.TransactionTypePurchase, "installments", "only purchases can be split into installments")
+		check.Check(i.Amount >= i.Installments, "amount", "is too small to split into that many installments")
+	}
+
+	if i.Type == model.TransactionTypeTransfer {
+		check.Check(i.AccountID != nil, "account_id", "is required on transfers")
+		check.Check(i.ToAccountID != nil, "to_account_id", "is required on transfers")
+		check.Check(i.AccountID == nil || i.ToAccountID == nil || *i.AccountID != *i.ToAccountID, "to_account_id", "must differ from account_id")
+		check.Check(i.CategoryID == nil, "category_id", "is not accepted on transfers")
+	} else {
+		check.Check(i.ToAccountID == nil, "to_account_id", "is only accepted on transfers")
+	}
+	return check.Err()
+}
+
+// PatchTransactionInput is a JSON Merge Patch of a transaction. Only the
+// fields sent are validated; type, title, amount and currency cannot be
+// removed.
+type PatchTransactionInput struct {
+	model.TransactionPatch
+	// OccurredAt is the raw date; the handler parses it into the patch.
+	OccurredAt model.Optional[string] `json:"occurred_at"`
+	Version    *int                   `json:"version,omitempty"`
+}
+
+func (i *PatchTransactionInput) Validate() error {
+	check := validation.Checker{}
+	if i.Type.Set {
+		check.Check(!i.Type.Null(), "type", "cannot be removed")
+		check.OneOf("type", string(i.Type.Or(model.TransactionTypePurchase)), flowTypes...)
+	}
+	if i.Title.Set {
+		check.Check(!i.Title.Null(), "title", "cannot be removed")
+		check.Required("title", i.Title.Or("-"))
+		check.MaxLength("title", i.Title.Or(""), maxTitleLength)
+	}
+	check.MaxLength("description", i.Description.Or(""), maxDescriptionLength)
+	if i.Amount.Set {
+		check.Check(!i.Amount.Null(), "amount", "cannot be removed")
+		check.Positive("amount", i.Amount.Or(1))
+	}
+	if i.Currency.Set {
+		check.Check(!i.Currency.Null(), "currency", "cannot be removed")
+		check.Matches("currency", i.Currency.Or("BRL"), currencyCode, "an ISO 4217 code such as BRL")
+	}
+	if i.OccurredAt.Set {
+		check.Check(!i.OccurredAt.Null(), "occurred_at", "cannot be removed")
+		if i.OccurredAt.Value != nil {
+			checkTimestamp(&check, "occurred_at", *i.OccurredAt.Value)
+		}
+	}
+	return check.Err()
+}
+
+// ParseTimestamp reads value as a date like 2026-01-31, meaning the start of
+// that day in loc, or as an RFC 3339 timestamp, returned in loc.
+func ParseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+func checkTimestamp(check *validation.Checker, field string, value string) {
+	_, err := ParseTimestamp(value, time.UTC)
+	check.Check(err == nil, field, "must be a date like "+dateExample+" or an RFC 3339 timestamp")
 }
@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+// defaultCurrency is the base currency of requests served without one set
+// by BaseCurrency.
+const defaultCurrency = "BRL"
+
+// firstDate is the start of the range listed when a balance needs every
+// transaction up to a date.
+const firstDate = "0001-01-01"
+
+// lastDate is the end of the range listed when any transaction will do.
+const lastDate = "9999-12-31"
+
+type AccountHandler struct {
+	ctx          context.Context
+	repository   repository.AccountRepository
+	transactions repository.TransactionRepository
+}
+
+func NewAccountHandler(ctx context.Context, repo repository.AccountRepository, transactions repository.TransactionRepository) *AccountHandler {
+	return &AccountHandler{
+		ctx:          ctx,
+		repository:   repo,
+		transactions: transactions,
+	}
+}
+
+func (e *AccountHandler) NewAccount(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to create a new account")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := Deserialize[dto.CreateAccountInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	account := &model.Account{
+		OwnerID:        owner,
+		ID:             uuid.NewString(),
+		Name:           input.Name,
+		Kind:           input.Kind,
+		Currency:       normalizeCurrency(input.Currency, baseCurrency(r)),
+		OpeningBalance: input.OpeningBalance,
+		ClosingDay:     input.ClosingDay,
+		DueDay:         input.DueDay,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	account.SetKeys()
+
+	savedAccount, err := e.repository.NewAccount(e.ctx, account)
+	if err != nil {
+		logger.Error("Failed to save new account", err, zap.String("account_id", account.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Account created successfully", zap.String("account_id", savedAccount.ID))
+	ResponseWithData(w, http.StatusCreated, savedAccount)
+}
+
+func (e *AccountHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to list all accounts")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	accounts, err := e.repository.ListAccounts(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch accounts", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Accounts retrieved successfully", zap.Int("count", len(accounts)))
+	ResponseWithData(w, http.StatusOK, accounts)
+}
+
+func (e *AccountHandler) GetAccountByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch account by ID", zap.String("account_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := e.repository.GetAccountByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Account not found", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Account retrieved successfully", zap.String("account_id", id))
+	ResponseWithData(w, http.StatusOK, account)
+}
+
+func (e *AccountHandler) UpdateAccountByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to update account", zap.String("account_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	current, err := e.repository.GetAccountByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Account not found", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	input, err := Deserialize[dto.CreateAccountInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	account := &model.Account{
+		OwnerID:        owner,
+		ID:             current.ID,
+		Name:           input.Name,
+		Kind:           input.Kind,
+		Currency:       normalizeCurrency(input.Currency, baseCurrency(r)),
+		OpeningBalance: input.OpeningBalance,
+		ClosingDay:     input.ClosingDay,
+		DueDay:         input.DueDay,
+		CreatedAt:      current.CreatedAt,
+		UpdatedAt:      time.Now().UTC(),
+	}
+	account.SetKeys()
+
+	updatedAccount, err := e.repository.UpdateAccount(e.ctx, account)
+	if err != nil {
+		logger.Error("Failed to update account", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Account updated successfully", zap.String("account_id", id))
+	ResponseWithData(w, http.StatusOK, updatedAccount)
+}
+
+// DeleteAccountByID removes an account that no transaction refers to.
+func (e *AccountHandler) DeleteAccountByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to delete account", zap.String("account_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := e.repository.GetAccountByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Account not found", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	page, err := e.transactions.ListTransactions(e.ctx, owner, firstDate, lastDate, repository.ListOptions{
+		Limit:     1,
+		AccountID: id,
+	})
+	if err != nil {
+		logger.Error("Failed to fetch account transactions", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+	if len(page.Items) > 0 {
+		ResponseWithError(w, conflictf("account '%s' still has transactions", id))
+		return
+	}
+
+	_, err = e.repository.DeleteAccount(e.ctx, account)
+	if err != nil {
+		logger.Error("Failed to delete account", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Account deleted successfully", zap.String("account_id", id))
+	ResponseNoContent(w)
+}
+
+// GetAccountBalance returns the balance at the end of the date query
+// parameter, defaulting to today.
+func (e *AccountHandler) GetAccountBalance(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch account balance", zap.String("account_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = requestNow(r).Format(dateLayout)
+	}
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		ResponseWithError(w, invalidf("date must look like 2026-01-31, got '%s'", date))
+		return
+	}
+
+	account, err := e.repository.GetAccountByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Account not found", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	transactions, err := e.accountTransactions(owner, id, firstDate, date)
+	if err != nil {
+		logger.Error("Failed to fetch account transactions", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	balance := account.OpeningBalance
+	for i := range transactions {
+		balance += transactions[i].BalanceEffect()
+	}
+
+	logger.Info("Account balance computed successfully", zap.String("account_id", id), zap.String("date", date))
+	ResponseWithData(w, http.StatusOK, model.AccountBalance{
+		AccountID: id,
+		Date:      date,
+		Currency:  account.Currency,
+		Balance:   balance,
+	})
+}
+
+// GetAccountStatement lists the transactions of an account between the
+// startDate and endDate query parameters, defaulting to the current month
+// so far, each with the balance right after it.
+func (e *AccountHandler) GetAccountStatement(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch account statement", zap.String("account_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	loc := requestLocation(r)
+	now := requestNow(r)
+
+	startDate, err := dateParam(query, "startDate", now.AddDate(0, 0, 1-now.Day()).Format(dateLayout), loc)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	endDate, err := dateParam(query, "endDate", now.Format(dateLayout), loc)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if endDate < startDate {
+		ResponseWithError(w, invalidf("endDate is before startDate"))
+		return
+	}
+
+	account, err := e.repository.GetAccountByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Account not found", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	transactions, err := e.accountTransactions(owner, id, firstDate, endDate)
+	if err != nil {
+		logger.Error("Failed to fetch account transactions", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	statement := model.AccountStatement{
+		AccountID:      id,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		Currency:       account.Currency,
+		OpeningBalance: account.OpeningBalance,
+		Entries:        []model.StatementEntry{},
+	}
+	balance := account.OpeningBalance
+	for _, transaction := range transactions {
+		balance += transaction.BalanceEffect()
+		if transaction.OccurredAt.Format("2006-01-02") < startDate {
+			statement.OpeningBalance = balance
+			continue
+		}
+		statement.Entries = append(statement.Entries, model.StatementEntry{
+			Transaction: transaction,
+			Balance:     balance,
+		})
+	}
+	statement.ClosingBalance = balance
+
+	logger.Info("Account statement computed successfully", zap.String("account_id", id), zap.Int("entries", len(statement.Entries)))
+	ResponseWithData(w, http.StatusOK, statement)
+}
+
+func (e *AccountHandler) accountTransactions(owner string, id string, startDate string, endDate string) ([]model.Transaction, error) {
+	return repository.ListAllTransactionsWith(e.ctx, e.transactions, owner, startDate, endDate, repository.ListOptions{
+		AccountID: id,
+	})
+}
+
+func normalizeCurrency(currency string, base string) string {
+	if currency == "" {
+		return base
+	}
+	return model.NormalizeCurrency(currency)
+}
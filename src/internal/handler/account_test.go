@@ -0,0 +1,81 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+// account creates an account for alice from body and fails the test unless
+// it is stored.
+func (s *server) account(body map[string]any) model.Account {
+	s.t.Helper()
+
+	r := s.do(http.MethodPost, "/api/account/", body)
+	expect(s.t, r, http.StatusCreated, "")
+	return decode[model.Account](s.t, r)
+}
+
+// spend records a transaction of kind on account.
+func (s *server) spend(account string, kind model.TransactionType, amount int, occurredAt string) model.Transaction {
+	s.t.Helper()
+
+	body := map[string]any{"type": kind, "title": string(kind), "amount": amount, "occurred_at": occurredAt, "account_id": account}
+	r := s.do(http.MethodPost, "/api/transaction/", body)
+	expect(s.t, r, http.StatusCreated, "")
+	return decode[model.Transaction](s.t, r)
+}
+
+func TestAccountBalanceAsOfDate(t *testing.T) {
+	s := newServer(t)
+	checking := s.account(map[string]any{"name": "Checking", "kind": "CHECKING", "opening_balance": 100000})
+	s.spend(checking.ID, model.TransactionTypeIncome, 50000, "2026-01-05")
+	s.spend(checking.ID, model.TransactionTypePurchase, 20000, "2026-01-20")
+	s.spend(checking.ID, model.TransactionTypePurchase, 5000, "2026-02-10")
+	s.record(model.TransactionTypePurchase, 99999, "", "2026-01-10", "")
+
+	cases := []struct {
+		date    string
+		balance int
+	}{
+		{"2025-12-31", 100000},
+		{"2026-01-05", 150000},
+		{"2026-01-19", 150000},
+		{"2026-01-31", 130000},
+		{"2026-02-10", 125000},
+	}
+	for _, c := range cases {
+		r := s.do(http.MethodGet, "/api/account/"+checking.ID+"/balance?date="+c.date, nil)
+		expect(t, r, http.StatusOK, "")
+		balance := decode[model.AccountBalance](t, r)
+		if balance.Balance != c.balance || balance.Date != c.date || balance.Currency != "BRL" {
+			t.Fatalf("balance on %s = %+v, want %d", c.date, balance, c.balance)
+		}
+	}
+}
+
+func TestTransferMovesBalancesButNotReports(t *testing.T) {
+	s := newServer(t)
+	checking := s.account(map[string]any{"name": "Checking", "kind": "CHECKING", "opening_balance": 100000})
+	savings := s.account(map[string]any{"name": "Savings", "kind": "SAVINGS"})
+	s.spend(checking.ID, model.TransactionTypeIncome, 10000, "2026-03-01")
+
+	body := map[string]any{"type": "TRANSFER", "title": "Savings", "amount": 30000, "occurred_at": "2026-03-05", "account_id": checking.ID, "to_account_id": savings.ID}
+	expect(t, s.do(http.MethodPost, "/api/transaction/", body), http.StatusCreated, "")
+
+	for id, want := range map[string]int{checking.ID: 80000, savings.ID: 30000} {
+		r := s.do(http.MethodGet, "/api/account/"+id+"/balance?date=2026-03-31", nil)
+		expect(t, r, http.StatusOK, "")
+		if balance := decode[model.AccountBalance](t, r); balance.Balance != want {
+			t.Fatalf("balance of %s = %d, want %d", id, balance.Balance, want)
+		}
+	}
+
+	r := s.do(http.MethodGet, "/api/report/monthly?year=2026", nil)
+	expect(t, r, http.StatusOK, "")
+	march := decode[model.MonthlyReport](t, r).Months[2]
+	if march.Income != 10000 || march.Purchases != 0 || march.Investments != 0 || march.Net != 10000 {
+		t.Fatalf("March summed to %+v, want only the income", march)
+	}
+}
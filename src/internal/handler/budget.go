@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+type BudgetHandler struct {
+	ctx          context.Context
+	repository   repository.BudgetRepository
+	categories   repository.CategoryRepository
+	transactions repository.TransactionRepository
+	rates        repository.ExchangeRateRepository
+}
+
+func NewBudgetHandler(ctx context.Context, repo repository.BudgetRepository, categories repository.CategoryRepository, transactions repository.TransactionRepository, rates repository.ExchangeRateRepository) *BudgetHandler {
+	return &BudgetHandler{
+		ctx:          ctx,
+		repository:   repo,
+		categories:   categories,
+		transactions: transactions,
+		rates:        rates,
+	}
+}
+
+func (e *BudgetHandler) NewBudget(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to create a new budget")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := Deserialize[dto.CreateBudgetInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	budget := &model.Budget{
+		OwnerID:    owner,
+		ID:         uuid.NewString(),
+		Name:       input.Name,
+		CategoryID: input.CategoryID,
+		Type:       input.Type,
+		Limit:      input.Limit,
+		StartMonth: input.StartMonth,
+		EndMonth:   input.EndMonth,
+		Rollover:   input.Rollover,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	budget.SetKeys()
+
+	if err := e.validate(owner, budget); err != nil {
+		logger.Error("Invalid budget", err, zap.String("budget_id", budget.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	savedBudget, err := e.repository.NewBudget(e.ctx, budget)
+	if err != nil {
+		logger.Error("Failed to save new budget", err, zap.String("budget_id", budget.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Budget created successfully", zap.String("budget_id", savedBudget.ID))
+	ResponseWithData(w, http.StatusCreated, savedBudget)
+}
+
+func (e *BudgetHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to list all budgets")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	budgets, err := e.repository.ListBudgets(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch budgets", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Budgets retrieved successfully", zap.Int("count", len(budgets)))
+	ResponseWithData(w, http.StatusOK, budgets)
+}
+
+func (e *BudgetHandler) GetBudgetByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch budget by ID", zap.String("budget_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	budget, err := e.repository.GetBudgetByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Budget not found", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Budget retrieved successfully", zap.String("budget_id", id))
+	ResponseWithData(w, http.StatusOK, budget)
+}
+
+func (e *BudgetHandler) UpdateBudgetByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to update budget", zap.String("budget_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	current, err := e.repository.GetBudgetByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Budget not found", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	input, err := Deserialize[dto.CreateBudgetInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	budget := &model.Budget{
+		OwnerID:    owner,
+		ID:         current.ID,
+		Name:       input.Name,
+		CategoryID: input.CategoryID,
+		Type:       input.Type,
+		Limit:      input.Limit,
+		StartMonth: input.StartMonth,
+		EndMonth:   input.EndMonth,
+		Rollover:   input.Rollover,
+		CreatedAt:  current.CreatedAt,
+		UpdatedAt:  time.Now().UTC(),
+	}
+	budget.SetKeys()
+
+	if err := e.validate(owner, budget); err != nil {
+		logger.Error("Invalid budget", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	updatedBudget, err := e.repository.UpdateBudget(e.ctx, budget)
+	if err != nil {
+		logger.Error("Failed to update budget", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Budget updated successfully", zap.String("budget_id", id))
+	ResponseWithData(w, http.StatusOK, updatedBudget)
+}
+
+func (e *BudgetHandler) DeleteBudgetByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to delete budget", zap.String("budget_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	budget, err := e.repository.GetBudgetByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Budget not found", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	_, err = e.repository.DeleteBudget(e.ctx, budget)
+	if err != nil {
+		logger.Error("Failed to delete budget", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Budget deleted successfully", zap.String("budget_id", id))
+	ResponseNoContent(w)
+}
+
+// GetBudgetStatus reports spent, remaining and percentage used for the month
+// query parameter, defaulting to the current month. Limits are in the base
+// currency, so spending in other currencies is converted to it.
+func (e *BudgetHandler) GetBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch budget status", zap.String("budget_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = requestNow(r).Format(model.MonthLayout)
+	}
+	monthStart, err := model.ParseMonth(month)
+	if err != nil {
+		ResponseWithError(w, invalidf("%s", err))
+		return
+	}
+
+	budget, err := e.repository.GetBudgetByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Budget not found", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+	if !budget.Covers(month) {
+		ResponseWithError(w, invalidf("budget '%s' does not cover %s", id, month))
+		return
+	}
+
+	categoryIDs, err := e.categoryTree(owner, budget.CategoryID)
+	if err != nil {
+		logger.Error("Failed to resolve budget categories", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	// Rollover needs every month since the budget started; otherwise only
+	// the requested month matters.
+	from := monthStart
+	if budget.Rollover {
+		from, _ = model.ParseMonth(budget.StartMonth)
+	}
+	to := monthStart.AddDate(0, 1, -1)
+
+	transactions, err := repository.ListAllTransactions(e.ctx, e.transactions, owner, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		logger.Error("Failed to fetch transactions", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	base := baseCurrency(r)
+	converter, err := newAmountConverter(e.ctx, e.rates, owner, base, base)
+	if err != nil {
+		logger.Error("Failed to fetch exchange rates", err, zap.String("budget_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	spent := map[string]int{}
+	for _, transaction := range transactions {
+		if !budget.Counts(transaction, categoryIDs) {
+			continue
+		}
+		amount, err := converter.amount(transaction)
+		if err != nil {
+			ResponseWithError(w, err)
+			return
+		}
+		spent[transaction.OccurredAt.Format(model.MonthLayout)] += amount
+	}
+
+	status := budget.Status(month, spent)
+	status.Currency = base
+
+	logger.Info("Budget status computed successfully", zap.String("budget_id", id), zap.String("month", month))
+	ResponseWithData(w, http.StatusOK, status)
+}
+
+// validate ensures a budget category belongs to owner. Field checks happen
+// in dto.CreateBudgetInput.
+func (e *BudgetHandler) validate(owner string, budget *model.Budget) error {
+	if budget.CategoryID != nil {
+		if _, err := e.categories.GetCategoryByID(e.ctx, owner, *budget.CategoryID); err != nil {
+			return reference("category_id", err)
+		}
+	}
+	return nil
+}
+
+// categoryTree returns root and all of its descendants, so a budget on
+// "Food" also counts purchases filed under "Food > Groceries".
+func (e *BudgetHandler) categoryTree(owner string, root *string) (map[string]bool, error) {
+	tree := map[string]bool{}
+	if root == nil {
+		return tree, nil
+	}
+
+	categories, err := e.categories.ListCategories(e.ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	tree[*root] = true
+	for grew := true; grew; {
+		grew = false
+		for _, category := range categories {
+			if !tree[category.ID] && category.ParentID != nil && tree[*category.ParentID] {
+				tree[category.ID] = true
+				grew = true
+			}
+		}
+	}
+	return tree, nil
+}
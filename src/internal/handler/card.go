@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+type CardHandler struct {
+	ctx          context.Context
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+}
+
+func NewCardHandler(ctx context.Context, accounts repository.AccountRepository, transactions repository.TransactionRepository) *CardHandler {
+	return &CardHandler{
+		ctx:          ctx,
+		accounts:     accounts,
+		transactions: transactions,
+	}
+}
+
+type paidStatement struct {
+	Statement *model.CardStatement `json:"statement"`
+	Payment   *model.Transfer      `json:"payment"`
+}
+
+// GetCardStatement returns the statement closing in the month query
+// parameter, defaulting to the current month.
+func (e *CardHandler) GetCardStatement(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch card statement", zap.String("account_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	card, month, ok := e.loadCard(w, r, owner, id)
+	if !ok {
+		return
+	}
+
+	statement, err := e.statement(owner, card, month, requestNow(r).Format(dateLayout))
+	if err != nil {
+		logger.Error("Failed to fetch card transactions", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Card statement computed successfully", zap.String("account_id", id), zap.String("month", statement.Month))
+	ResponseWithData(w, http.StatusOK, statement)
+}
+
+// PayCardStatement records a transfer from another account into the card
+// covering the statement closing in the month query parameter.
+func (e *CardHandler) PayCardStatement(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to pay card statement", zap.String("account_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := Deserialize[dto.PayStatementInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	card, month, ok := e.loadCard(w, r, owner, id)
+	if !ok {
+		return
+	}
+
+	statement, err := e.statement(owner, card, month, requestNow(r).Format(dateLayout))
+	if err != nil {
+		logger.Error("Failed to fetch card transactions", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	amount := input.Amount
+	if amount == 0 {
+		amount = statement.Remaining
+	}
+	if statement.Remaining == 0 {
+		ResponseWithError(w, conflictf("statement %s has nothing left to pay", statement.Month))
+		return
+	}
+	if amount > statement.Remaining {
+		ResponseWithError(w, invalidf("amount must be between 1 and the remaining %d", statement.Remaining))
+		return
+	}
+
+	source, err := e.accounts.GetAccountByID(e.ctx, owner, input.AccountID)
+	if err != nil {
+		logger.Error("Paying account not found", err, zap.String("account_id", input.AccountID))
+		ResponseWithError(w, reference("account_id", err))
+		return
+	}
+	if source.ID == card.ID {
+		ResponseWithError(w, invalidf("a card cannot pay its own statement"))
+		return
+	}
+	if source.Currency != card.Currency {
+		ResponseWithError(w, invalidf("cannot pay a %s statement from a %s account", card.Currency, source.Currency))
+		return
+	}
+
+	title := fmt.Sprintf("%s statement %s", card.Name, statement.Month)
+	now := time.Now().UTC()
+	payment := &model.Transfer{
+		From: model.Transaction{OwnerID: owner, ID: uuid.NewString(), Title: title, Money: model.Money{Amount: amount, Currency: card.Currency}, AccountID: &source.ID, OccurredAt: now.In(requestLocation(r)), CreatedAt: now},
+		To:   model.Transaction{OwnerID: owner, ID: uuid.NewString(), Title: title, Money: model.Money{Amount: amount, Currency: card.Currency}, AccountID: &card.ID},
+	}
+	payment.Link()
+
+	savedPayment, err := e.transactions.NewTransfer(e.ctx, payment, nil)
+	if err != nil {
+		logger.Error("Failed to save statement payment", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	statement, err = e.statement(owner, card, month, requestNow(r).Format(dateLayout))
+	if err != nil {
+		logger.Error("Failed to fetch card transactions", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Card statement paid successfully", zap.String("account_id", id), zap.String("month", statement.Month), zap.Int("amount", amount))
+	ResponseWithData(w, http.StatusCreated, paidStatement{
+		Statement: statement,
+		Payment:   savedPayment,
+	})
+}
+
+// loadCard fetches the credit card id and parses the month query
+// parameter, answering the request itself when either is invalid.
+func (e *CardHandler) loadCard(w http.ResponseWriter, r *http.Request, owner string, id string) (*model.Account, time.Time, bool) {
+	value := r.URL.Query().Get("month")
+	if value == "" {
+		value = requestNow(r).Format(model.MonthLayout)
+	}
+	month, err := model.ParseMonth(value)
+	if err != nil {
+		ResponseWithError(w, invalidf("%s", err))
+		return nil, time.Time{}, false
+	}
+
+	card, err := e.accounts.GetAccountByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Card not found", err, zap.String("account_id", id))
+		ResponseWithError(w, err)
+		return nil, time.Time{}, false
+	}
+	if card.Kind != model.AccountKindCreditCard {
+		ResponseWithError(w, fmt.Errorf("account '%s' is not a credit card: %w", id, repository.ErrNotFound))
+		return nil, time.Time{}, false
+	}
+	return card, month, true
+}
+
+func (e *CardHandler) statement(owner string, card *model.Account, month time.Time, today string) (*model.CardStatement, error) {
+	_, closing, _ := card.Cycle(month)
+
+	endDate := max(closing.Format("2006-01-02"), today)
+	transactions, err := repository.ListAllTransactionsWith(e.ctx, e.transactions, owner, firstDate, endDate, repository.ListOptions{
+		AccountID: card.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewCardStatement(card, month, transactions, today), nil
+}
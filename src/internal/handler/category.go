@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+type CategoryHandler struct {
+	ctx          context.Context
+	repository   repository.CategoryRepository
+	transactions repository.TransactionRepository
+}
+
+func NewCategoryHandler(ctx context.Context, repo repository.CategoryRepository, transactions repository.TransactionRepository) *CategoryHandler {
+	return &CategoryHandler{
+		ctx:          ctx,
+		repository:   repo,
+		transactions: transactions,
+	}
+}
+
+type deletedCategory struct {
+	Category               *model.Category `json:"category"`
+	ReassignedTransactions int             `json:"reassigned_transactions"`
+}
+
+func (e *CategoryHandler) NewCategory(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to create a new category")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := Deserialize[dto.CreateCategoryInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	category := &model.Category{
+		OwnerID:   owner,
+		ID:        uuid.NewString(),
+		Name:      input.Name,
+		Color:     input.Color,
+		Icon:      input.Icon,
+		ParentID:  input.ParentID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	category.SetKeys()
+
+	if err := e.validate(owner, category); err != nil {
+		logger.Error("Invalid category", err, zap.String("category_id", category.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	savedCategory, err := e.repository.NewCategory(e.ctx, category)
+	if err != nil {
+		logger.Error("Failed to save new category", err, zap.String("category_id", category.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Category created successfully", zap.String("category_id", savedCategory.ID))
+	ResponseWithData(w, http.StatusCreated, savedCategory)
+}
+
+func (e *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to list all categories")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	categories, err := e.repository.ListCategories(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch categories", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Categories retrieved successfully", zap.Int("count", len(categories)))
+	ResponseWithData(w, http.StatusOK, categories)
+}
+
+func (e *CategoryHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch category by ID", zap.String("category_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := e.repository.GetCategoryByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Category not found", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Category retrieved successfully", zap.String("category_id", id))
+	ResponseWithData(w, http.StatusOK, category)
+}
+
+func (e *CategoryHandler) UpdateCategoryByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to update category", zap.String("category_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	current, err := e.repository.GetCategoryByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Category not found", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	input, err := Deserialize[dto.CreateCategoryInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	category := &model.Category{
+		OwnerID:   owner,
+		ID:        current.ID,
+		Name:      input.Name,
+		Color:     input.Color,
+		Icon:      input.Icon,
+		ParentID:  input.ParentID,
+		CreatedAt: current.CreatedAt,
+		UpdatedAt: time.Now().UTC(),
+	}
+	category.SetKeys()
+
+	if err := e.validate(owner, category); err != nil {
+		logger.Error("Invalid category", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	updatedCategory, err := e.repository.UpdateCategory(e.ctx, category)
+	if err != nil {
+		logger.Error("Failed to update category", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Category updated successfully", zap.String("category_id", id))
+	ResponseWithData(w, http.StatusOK, updatedCategory)
+}
+
+// DeleteCategoryByID removes a category. Its transactions move to the
+// category given by the reassignTo query parameter, or become uncategorized
+// when it is absent; its subcategories move up to its own parent. The
+// category is only deleted once all its transactions moved, so a request
+// that failed halfway finishes the move when sent again.
+func (e *CategoryHandler) DeleteCategoryByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to delete category", zap.String("category_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := e.repository.GetCategoryByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Category not found", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	var reassignTo *string
+	if target := r.URL.Query().Get("reassignTo"); target != "" {
+		if target == id {
+			ResponseWithError(w, invalidf("cannot reassign transactions to the deleted category"))
+			return
+		}
+		if _, err := e.repository.GetCategoryByID(e.ctx, owner, target); err != nil {
+			logger.Error("Reassignment category not found", err, zap.String("category_id", target))
+			if errors.Is(err, repository.ErrNotFound) {
+				err = invalidf("reassignTo category '%s' does not exist", target)
+			}
+			ResponseWithError(w, err)
+			return
+		}
+		reassignTo = &target
+	}
+
+	if err := e.reparentChildren(owner, category); err != nil {
+		logger.Error("Failed to move subcategories", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	reassigned, err := e.transactions.ReassignCategory(e.ctx, owner, id, reassignTo)
+	if err != nil {
+		logger.Error("Failed to reassign transactions", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	_, err = e.repository.DeleteCategory(e.ctx, category)
+	if err != nil {
+		logger.Error("Failed to delete category", err, zap.String("category_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Category deleted successfully", zap.String("category_id", id), zap.Int("reassigned", reassigned))
+	ResponseWithData(w, http.StatusOK, deletedCategory{
+		Category:               category,
+		ReassignedTransactions: reassigned,
+	})
+}
+
+// validate checks that the category parent exists without creating a cycle
+// in the hierarchy.
+func (e *CategoryHandler) validate(owner string, category *model.Category) error {
+	seen := map[string]bool{category.ID: true}
+	for parentID := category.ParentID; parentID != nil; {
+		if seen[*parentID] {
+			return invalidf("category '%s' cannot be its own ancestor", category.ID)
+		}
+		seen[*parentID] = true
+
+		parent, err := e.repository.GetCategoryByID(e.ctx, owner, *parentID)
+		if err != nil {
+			return reference("parent_id", err)
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+func (e *CategoryHandler) reparentChildren(owner string, category *model.Category) error {
+	categories, err := e.repository.ListCategories(e.ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range categories {
+		if child.ParentID == nil || *child.ParentID != category.ID {
+			continue
+		}
+
+		child.ParentID = category.ParentID
+		child.UpdatedAt = time.Now().UTC()
+		if _, err := e.repository.UpdateCategory(e.ctx, &child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
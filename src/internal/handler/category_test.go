@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/handler"
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+// category creates a category for alice and fails the test unless it is
+// stored.
+func (s *server) category(name string, parentID string) model.Category {
+	s.t.Helper()
+
+	body := map[string]any{"name": name}
+	if parentID != "" {
+		body["parent_id"] = parentID
+	}
+	r := s.do(http.MethodPost, "/api/category/", body)
+	expect(s.t, r, http.StatusCreated, "")
+	return decode[model.Category](s.t, r)
+}
+
+func TestNewCategory(t *testing.T) {
+	s := newServer(t)
+	food := s.category("Food", "")
+	groceries := s.category("Groceries", food.ID)
+	if groceries.ParentID == nil || *groceries.ParentID != food.ID {
+		t.Fatalf("Groceries has parent %v, want %s", groceries.ParentID, food.ID)
+	}
+
+	r := s.do(http.MethodGet, "/api/category/"+groceries.ID, nil)
+	expect(t, r, http.StatusOK, "")
+	if got := decode[model.Category](t, r); got.Name != "Groceries" {
+		t.Fatalf("GET returned %+v", got)
+	}
+
+	expect(t, s.do(http.MethodPost, "/api/category/", map[string]any{"name": ""}), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+	expect(t, s.do(http.MethodPost, "/api/category/", map[string]any{"name": "Orphan", "parent_id": "missing"}), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+	expect(t, s.send(token(t, "bob"), http.MethodGet, "/api/category/"+food.ID, nil), http.StatusNotFound, "")
+}
+
+func TestNewTransactionUnknownCategory(t *testing.T) {
+	s := newServer(t)
+
+	r := s.do(http.MethodPost, "/api/transaction/", map[string]any{"type": "PURCHASE", "title": "Lunch", "amount": 100, "category_id": "missing"})
+	expect(t, r, http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+	if len(r.Errors) != 1 || r.Errors[0].Field != "category_id" {
+		t.Fatalf("errors %+v do not name category_id", r.Errors)
+	}
+
+	// Another owner's category is as unknown as a missing one.
+	r = s.send(token(t, "bob"), http.MethodPost, "/api/category/", map[string]any{"name": "Bob's"})
+	expect(t, r, http.StatusCreated, "")
+	bobs := decode[model.Category](t, r)
+	expect(t, s.do(http.MethodPost, "/api/transaction/", map[string]any{"type": "PURCHASE", "title": "Lunch", "amount": 100, "category_id": bobs.ID}), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+}
+
+func TestDeleteCategoryReassignsTransactions(t *testing.T) {
+	s := newServer(t)
+	food, travel := s.category("Food", ""), s.category("Travel", "")
+	snacks := s.category("Snacks", food.ID)
+	lunch := s.record(model.TransactionTypePurchase, 2500, "", "2026-03-02", food.ID)
+	s.record(model.TransactionTypePurchase, 9000, "", "2026-03-03", travel.ID)
+
+	path := "/api/category/" + food.ID
+	expect(t, s.do(http.MethodDelete, path+"?reassignTo="+food.ID, nil), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+	expect(t, s.do(http.MethodDelete, path+"?reassignTo=missing", nil), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+
+	r := s.do(http.MethodDelete, path+"?reassignTo="+travel.ID, nil)
+	expect(t, r, http.StatusOK, "")
+	deleted := decode[struct {
+		ReassignedTransactions int `json:"reassigned_transactions"`
+	}](t, r)
+	if deleted.ReassignedTransactions != 1 {
+		t.Fatalf("reassigned %d transactions, want 1", deleted.ReassignedTransactions)
+	}
+
+	expect(t, s.do(http.MethodGet, path, nil), http.StatusNotFound, "")
+	r = s.do(http.MethodGet, "/api/transaction/"+lunch.ID, nil)
+	expect(t, r, http.StatusOK, "")
+	if moved := decode[model.Transaction](t, r); moved.CategoryID == nil || *moved.CategoryID != travel.ID {
+		t.Fatalf("lunch filed under %v, want %s", moved.CategoryID, travel.ID)
+	}
+	r = s.do(http.MethodGet, "/api/category/"+snacks.ID, nil)
+	expect(t, r, http.StatusOK, "")
+	if orphan := decode[model.Category](t, r); orphan.ParentID != nil {
+		t.Fatalf("Snacks still has parent %s", *orphan.ParentID)
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+type baseCurrencyKey struct{}
+
+// BaseCurrency makes currency the base currency of every request it wraps:
+// the one new accounts and amounts without an account default to, and the
+// one reports convert every amount to.
+func BaseCurrency(currency string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), baseCurrencyKey{}, currency)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// baseCurrency is the currency set by BaseCurrency, or defaultCurrency.
+func baseCurrency(r *http.Request) string {
+	if currency, ok := r.Context().Value(baseCurrencyKey{}).(string); ok && currency != "" {
+		return currency
+	}
+	return defaultCurrency
+}
+
+// reportCurrency reads the currency query parameter a report converts its
+// amounts to, defaulting to the base currency.
+func reportCurrency(r *http.Request) (string, error) {
+	value := r.URL.Query().Get("currency")
+	if value == "" {
+		return baseCurrency(r), nil
+	}
+	if !dto.IsCurrencyCode(value) {
+		return "", invalidf("currency must be an ISO 4217 code such as BRL, got '%s'", value)
+	}
+	return model.NormalizeCurrency(value), nil
+}
+
+// accountCurrency resolves the currency of an amount filed under accountID.
+// An account fixes it, and a requested currency must then be the same; an
+// amount without one is in requested, or in base when none was.
+func accountCurrency(ctx context.Context, accounts repository.AccountRepository, owner string, accountID *string, requested string, base string) (string, error) {
+	requested = model.NormalizeCurrency(requested)
+	if accountID == nil {
+		if requested == "" {
+			return base, nil
+		}
+		return requested, nil
+	}
+
+	account, err := accounts.GetAccountByID(ctx, owner, *accountID)
+	if err != nil {
+		return "", reference("account_id", err)
+	}
+	if requested != "" && requested != account.Currency {
+		return "", validation.Errors{{Field: "currency", Message: fmt.Sprintf("must be %s, the currency of the account", account.Currency)}}
+	}
+	return account.Currency, nil
+}
+
+// amountConverter converts the amounts of transactions into one currency
+// for reports, each with the exchange rate of the day it occurred.
+type amountConverter struct {
+	rates    *model.Converter
+	base     string
+	currency string
+}
+
+// newAmountConverter loads the exchange rates of owner to convert amounts
+// into currency. Amounts stored without a currency are taken as base.
+func newAmountConverter(ctx context.Context, rates repository.ExchangeRateRepository, owner string, base string, currency string) (*amountConverter, error) {
+	stored, err := rates.ListExchangeRates(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return &amountConverter{
+		rates:    model.NewConverter(stored),
+		base:     base,
+		currency: currency,
+	}, nil
+}
+
+// amount returns the amount of transaction in the report currency. A
+// missing rate is the client's to enter, so it answers 422.
+func (c *amountConverter) amount(transaction model.Transaction) (int, error) {
+	money := transaction.Money
+	if money.Currency == "" {
+		money.Currency = c.base
+	}
+
+	date := transaction.OccurredAt.Format(dateLayout)
+	amount, ok := c.rates.Convert(money, c.currency, date)
+	if !ok {
+		return 0, invalidf("no exchange rate from %s to %s on or before %s, add one under /api/exchange-rate", money.Currency, c.currency, date)
+	}
+	return amount, nil
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/auth"
+	"github.com/joaoleau/muquirango/internal/dto"
+)
+
+const dateLayout = "2006-01-02"
+
+// requestLocation is the time zone of the caller. Days, in sort keys, date
+// filters and reports alike, are days in this zone.
+func requestLocation(r *http.Request) *time.Location {
+	return auth.LocationFromContext(r.Context())
+}
+
+// requestNow is the current time in the time zone of the caller.
+func requestNow(r *http.Request) time.Time {
+	return time.Now().In(requestLocation(r))
+}
+
+// dateParam reads the query parameter name as a day in loc, or returns
+// fallback when it is missing. A day like 2026-01-31 is taken as is and an
+// RFC 3339 timestamp selects the day it falls on in loc.
+func dateParam(query url.Values, name string, fallback string, loc *time.Location) (string, error) {
+	value := query.Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	date, err := dto.ParseTimestamp(value, loc)
+	if err != nil {
+		return "", invalidf("%s must be a date like 2026-01-31 or an RFC 3339 timestamp, got '%s'", name, value)
+	}
+	return date.Format(dateLayout), nil
+}
+
+// dateHint reads the occurredAt query parameter, still accepting the
+// createdAt name it had before transactions were dated apart from their
+// creation.
+func dateHint(query url.Values) string {
+	if date := query.Get("occurredAt"); date != "" {
+		return date
+	}
+	return query.Get("createdAt")
+}
+
+// parseOccurredAt reads the date a client sent as a moment in loc, or
+// returns fallback when it sent none. The value has been validated with the
+// rest of the body.
+func parseOccurredAt(value *string, fallback time.Time, loc *time.Location) time.Time {
+	if value == nil {
+		return fallback
+	}
+	date, err := dto.ParseTimestamp(*value, loc)
+	if err != nil {
+		return fallback
+	}
+	return date
+}
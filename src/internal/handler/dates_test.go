@@ -0,0 +1,70 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+// zonedToken signs a token for owner whose zoneinfo claim is zone.
+func zonedToken(t *testing.T, owner string, zone string) string {
+	claims := jwt.MapClaims{
+		"sub":      owner,
+		"exp":      time.Now().Add(time.Hour).Unix(),
+		"zoneinfo": zone,
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestEveningPurchaseKeepsTheLocalDate(t *testing.T) {
+	s := newServer(t)
+	saoPaulo := zonedToken(t, "alice", "America/Sao_Paulo")
+
+	// 22:00 on March 31 in São Paulo is already April 1 in UTC.
+	r := s.send(saoPaulo, http.MethodPost, "/api/transaction/", map[string]any{"type": "PURCHASE", "title": "Dinner", "amount": 8000, "occurred_at": "2026-04-01T01:00:00Z"})
+	expect(t, r, http.StatusCreated, "")
+	dinner := decode[model.Transaction](t, r)
+	if got := dinner.OccurredAt.Format(time.RFC3339); got != "2026-03-31T22:00:00-03:00" {
+		t.Fatalf("occurred_at = %s, want the local time", got)
+	}
+
+	list := func(query string) []model.Transaction {
+		r := s.send(saoPaulo, http.MethodGet, "/api/transaction/?"+query, nil)
+		expect(t, r, http.StatusOK, "")
+		return decode[[]model.Transaction](t, r)
+	}
+	if found := list("startDate=2026-03-31&endDate=2026-03-31"); len(found) != 1 || found[0].ID != dinner.ID {
+		t.Fatalf("March 31 lists %v, want the dinner", found)
+	}
+	if found := list("startDate=2026-04-01&endDate=2026-04-01"); len(found) != 0 {
+		t.Fatalf("April 1 lists %v, want nothing", found)
+	}
+	// A timestamp filter selects the day it falls on in the caller's zone.
+	if found := list("startDate=2026-04-01T01:00:00Z&endDate=2026-03-31"); len(found) != 1 {
+		t.Fatalf("a filter from 01:00 UTC on April 1 lists %v, want the dinner", found)
+	}
+
+	r = s.send(saoPaulo, http.MethodGet, "/api/transaction/"+dinner.ID+"?occurredAt=2026-03-31", nil)
+	expect(t, r, http.StatusOK, "")
+
+	r = s.send(saoPaulo, http.MethodGet, "/api/report/monthly?year=2026", nil)
+	expect(t, r, http.StatusOK, "")
+	report := decode[model.MonthlyReport](t, r)
+	if march, april := report.Months[2], report.Months[3]; march.Purchases != 8000 || april.Purchases != 0 {
+		t.Fatalf("dinner reported in March %+v and April %+v, want March", march, april)
+	}
+
+	// The same moment sent by an owner on UTC is dated April 1.
+	r = s.send(token(t, "bob"), http.MethodPost, "/api/transaction/", map[string]any{"type": "PURCHASE", "title": "Dinner", "amount": 8000, "occurred_at": "2026-04-01T01:00:00Z"})
+	expect(t, r, http.StatusCreated, "")
+	if got := decode[model.Transaction](t, r).OccurredAt.Format("2006-01-02"); got != "2026-04-01" {
+		t.Fatalf("bob's dinner is dated %s, want 2026-04-01", got)
+	}
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ExchangeRateHandler manages the exchange rates reports convert amounts
+// with. Rates are entered one at a time or imported from a CSV file; a rate
+// for a pair and day already stored is replaced.
+type ExchangeRateHandler struct {
+	ctx        context.Context
+	repository repository.ExchangeRateRepository
+}
+
+func NewExchangeRateHandler(ctx context.Context, repo repository.ExchangeRateRepository) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		ctx:        ctx,
+		repository: repo,
+	}
+}
+
+type importedRates struct {
+	Imported int                  `json:"imported"`
+	Rates    []model.ExchangeRate `json:"rates"`
+}
+
+func (e *ExchangeRateHandler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to list all exchange rates")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	rates, err := e.repository.ListExchangeRates(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch exchange rates", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Exchange rates retrieved successfully", zap.Int("count", len(rates)))
+	ResponseWithData(w, http.StatusOK, rates)
+}
+
+func (e *ExchangeRateHandler) NewExchangeRate(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to create a new exchange rate")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := Deserialize[dto.CreateExchangeRateInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	rates := newExchangeRates(owner, []dto.CreateExchangeRateInput{*input}, time.Now().UTC())
+	savedRates, err := e.repository.PutExchangeRates(e.ctx, rates)
+	if err != nil {
+		logger.Error("Failed to save new exchange rate", err, zap.String("sk", rates[0].SK))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Exchange rate created successfully", zap.String("sk", savedRates[0].SK))
+	ResponseWithData(w, http.StatusCreated, savedRates[0])
+}
+
+// ImportExchangeRates stores every rate of a CSV body with the columns
+// date, base, quote and rate. Nothing is stored unless every line is valid.
+func (e *ExchangeRateHandler) ImportExchangeRates(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to import exchange rates")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	inputs, err := dto.ParseExchangeRatesCSV(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if !errors.As(err, &tooLarge) {
+			logger.Error("Invalid exchange rates file", err)
+		}
+		ResponseWithError(w, err)
+		return
+	}
+
+	rates := newExchangeRates(owner, inputs, time.Now().UTC())
+	savedRates, err := e.repository.PutExchangeRates(e.ctx, rates)
+	if err != nil {
+		logger.Error("Failed to import exchange rates", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Exchange rates imported successfully", zap.Int("count", len(savedRates)))
+	ResponseWithData(w, http.StatusCreated, importedRates{
+		Imported: len(savedRates),
+		Rates:    savedRates,
+	})
+}
+
+func (e *ExchangeRateHandler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	rate := &model.ExchangeRate{
+		Base:  model.NormalizeCurrency(chi.URLParam(r, "base")),
+		Quote: model.NormalizeCurrency(chi.URLParam(r, "quote")),
+		Date:  chi.URLParam(r, "date"),
+	}
+	logger.Info("Received request to delete exchange rate", zap.String("base", rate.Base), zap.String("quote", rate.Quote), zap.String("date", rate.Date))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	rate.OwnerID = owner
+	rate.SetKeys()
+
+	_, err := e.repository.DeleteExchangeRate(e.ctx, rate)
+	if err != nil {
+		logger.Error("Failed to delete exchange rate", err, zap.String("sk", rate.SK))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Exchange rate deleted successfully", zap.String("sk", rate.SK))
+	ResponseNoContent(w)
+}
+
+// newExchangeRates builds the keyed rates of owner from validated inputs.
+func newExchangeRates(owner string, inputs []dto.CreateExchangeRateInput, now time.Time) []model.ExchangeRate {
+	rates := make([]model.ExchangeRate, len(inputs))
+	for i, input := range inputs {
+		rates[i] = model.ExchangeRate{
+			OwnerID:   owner,
+			Base:      model.NormalizeCurrency(input.Base),
+			Quote:     model.NormalizeCurrency(input.Quote),
+			Date:      input.Date,
+			Rate:      input.Rate.String(),
+			UpdatedAt: now,
+		}
+		rates[i].SetKeys()
+	}
+	return rates
+}
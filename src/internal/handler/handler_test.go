@@ -0,0 +1,156 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joaoleau/muquirango/internal/auth"
+	"github.com/joaoleau/muquirango/internal/cursor"
+	"github.com/joaoleau/muquirango/internal/handler"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/router"
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+const testSecret = "test-secret"
+
+// server serves the whole API on in-memory repositories, in BRL.
+type server struct {
+	t   *testing.T
+	mux *chi.Mux
+}
+
+func newServer(t *testing.T) *server {
+	verifier, err := auth.NewVerifier(auth.Config{HMACSecret: testSecret})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	transactions := repository.NewMemoryTransactionRepository()
+	repos := &router.Repositories{
+		TransactionRepo:  transactions,
+		CategoryRepo:     repository.NewMemoryCategoryRepository(),
+		BudgetRepo:       repository.NewMemoryBudgetRepository(),
+		RecurrenceRepo:   repository.NewMemoryRecurrenceRepository(),
+		InstallmentRepo:  repository.NewMemoryInstallmentRepository(transactions),
+		AccountRepo:      repository.NewMemoryAccountRepository(),
+		ExchangeRateRepo: repository.NewMemoryExchangeRateRepository(),
+	}
+
+	mux := chi.NewRouter()
+	router.RegisterRoutes(mux, repos, verifier, cursor.NewCodec([]byte("cursor-secret")), "BRL")
+	return &server{t: t, mux: mux}
+}
+
+// token signs a token for owner valid for an hour.
+func token(t *testing.T, owner string) string {
+	claims := jwt.RegisteredClaims{
+		Subject:   owner,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+// response is the decoded body of an answer, with Data left raw so each
+// test decodes it into the type it expects.
+type response struct {
+	Status int
+	Header http.Header
+	Data   json.RawMessage         `json:"data"`
+	Error  string                  `json:"error"`
+	Code   string                  `json:"code"`
+	Errors []validation.FieldError `json:"errors"`
+}
+
+// do sends a request as alice. header holds name and value pairs.
+func (s *server) do(method string, path string, body any, header ...string) response {
+	return s.send(token(s.t, "alice"), method, path, body, header...)
+}
+
+// send sends a request with bearer as its token, none when empty.
+func (s *server) send(bearer string, method string, path string, body any, header ...string) response {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			s.t.Fatalf("encode %s %s: %v", method, path, err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+
+	out := response{Status: rec.Code, Header: rec.Header()}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+			s.t.Fatalf("%s %s answered %d with %q: %v", method, path, rec.Code, rec.Body.String(), err)
+		}
+	}
+	return out
+}
+
+// decode reads the data of r into a T.
+func decode[T any](t *testing.T, r response) T {
+	t.Helper()
+
+	var data T
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		t.Fatalf("decode %s: %v", r.Data, err)
+	}
+	return data
+}
+
+// expect fails unless r answered status with code, when code is set.
+func expect(t *testing.T, r response, status int, code string) {
+	t.Helper()
+
+	if r.Status != status || (code != "" && r.Code != code) {
+		t.Fatalf("answered %d %q (%s), want %d %q", r.Status, r.Code, r.Error, status, code)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newServer(t)
+
+	cases := []struct {
+		name   string
+		bearer string
+	}{
+		{"Missing", ""},
+		{"Malformed", "not-a-token"},
+		{"WrongSecret", func() string {
+			signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+				Subject:   "alice",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			}).SignedString([]byte("another-secret"))
+			return signed
+		}()},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := s.send(c.bearer, http.MethodGet, "/api/transaction/", nil)
+			expect(t, r, http.StatusUnauthorized, handler.CodeUnauthenticated)
+		})
+	}
+
+	r := s.do(http.MethodGet, "/api/transaction/", nil)
+	expect(t, r, http.StatusOK, "")
+}
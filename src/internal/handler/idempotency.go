@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader names the header a client sets to retry a create
+// safely: every request with the same key gets the response of the first.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ReplayedHeader marks a response replayed from an earlier request.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyTTL is how long a key is remembered after its first use.
+const idempotencyTTL = 24 * time.Hour
+
+const maxIdempotencyKeyLength = 255
+
+// newIdempotencyRecord builds the record of a create sent with key, or
+// returns nil when key is empty. The fingerprint of input ties the key to
+// the request, so reusing it for another one is refused instead of being
+// answered with the wrong response.
+func newIdempotencyRecord(owner string, key string, input any, now time.Time) (*model.IdempotencyRecord, error) {
+	if key == "" {
+		return nil, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, invalidf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)
+	}
+
+	body, err := Serialize(input)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := sha256.Sum256(body)
+
+	record := &model.IdempotencyRecord{
+		OwnerID:     owner,
+		Key:         key,
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+		Status:      http.StatusCreated,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(idempotencyTTL).Unix(),
+	}
+	record.SetKeys()
+	return record, nil
+}
+
+// replay answers with the response stored for the key of record and reports
+// whether it did. It returns false when nothing is stored, so the create can
+// go ahead.
+func (e *TransactionHandler) replay(w http.ResponseWriter, record *model.IdempotencyRecord) bool {
+	stored, err := e.repository.GetIdempotencyRecord(e.ctx, record.OwnerID, record.Key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to fetch idempotency record", err, zap.String("idempotency_key", record.Key))
+		ResponseWithError(w, err)
+		return true
+	}
+	if stored.Fingerprint != record.Fingerprint {
+		ResponseWithError(w, invalidf("%s '%s' was already used for a different request", IdempotencyKeyHeader, record.Key))
+		return true
+	}
+
+	// Only a single transaction is answered with its version as ETag.
+	var created struct {
+		Version *int `json:"version"`
+	}
+	if json.Unmarshal([]byte(stored.Response), &created) == nil && created.Version != nil {
+		setETag(w, *created.Version)
+	}
+
+	logger.Info("Replaying stored response", zap.String("idempotency_key", record.Key))
+	w.Header().Set(ReplayedHeader, "true")
+	ResponseWithData(w, stored.Status, json.RawMessage(stored.Response))
+	return true
+}
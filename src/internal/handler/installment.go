@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+// InstallmentHandler manages installment plans as a group. Plans are
+// created through NewTransaction; the remaining installments of a plan are
+// the ones dated after today.
+type InstallmentHandler struct {
+	ctx        context.Context
+	repository repository.InstallmentRepository
+	categories repository.CategoryRepository
+}
+
+func NewInstallmentHandler(ctx context.Context, repo repository.InstallmentRepository, categories repository.CategoryRepository) *InstallmentHandler {
+	return &InstallmentHandler{
+		ctx:        ctx,
+		repository: repo,
+		categories: categories,
+	}
+}
+
+type cancelledPlan struct {
+	Plan                  *model.InstallmentPlan `json:"plan"`
+	CancelledInstallments int                    `json:"cancelled_installments"`
+}
+
+func (e *InstallmentHandler) ListInstallmentPlans(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to list all installment plans")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	plans, err := e.repository.ListInstallmentPlans(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch installment plans", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Installment plans retrieved successfully", zap.Int("count", len(plans)))
+	ResponseWithData(w, http.StatusOK, plans)
+}
+
+// GetInstallmentPlanByID returns a plan with every installment still
+// stored.
+func (e *InstallmentHandler) GetInstallmentPlanByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch installment plan by ID", zap.String("plan_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := e.repository.GetInstallmentPlanByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Installment plan not found", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	if plan.Transactions, err = e.repository.ListInstallments(e.ctx, plan); err != nil {
+		logger.Error("Failed to fetch installments", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Installment plan retrieved successfully", zap.String("plan_id", id))
+	ResponseWithData(w, http.StatusOK, plan)
+}
+
+// UpdateInstallmentPlanByID changes the title, description, category and
+// tags of a plan and of its remaining installments. Installments already
+// due keep what they were billed with. Cancelled plans answer 409.
+func (e *InstallmentHandler) UpdateInstallmentPlanByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to update installment plan", zap.String("plan_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := e.repository.GetInstallmentPlanByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Installment plan not found", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+	if plan.CancelledAt != nil {
+		ResponseWithError(w, conflictf("installment plan '%s' is cancelled", id))
+		return
+	}
+
+	input, err := Deserialize[dto.UpdateInstallmentPlanInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	if input.CategoryID != nil {
+		if _, err := e.categories.GetCategoryByID(e.ctx, owner, *input.CategoryID); err != nil {
+			logger.Error("Invalid category", err, zap.String("plan_id", id))
+			ResponseWithError(w, reference("category_id", err))
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	plan.Title = input.Title
+	plan.Description = input.Description
+	plan.CategoryID = input.CategoryID
+	plan.Tags = model.NormalizeTags(input.Tags)
+	plan.UpdatedAt = now
+
+	installments, err := e.repository.ListInstallments(e.ctx, plan)
+	if err != nil {
+		logger.Error("Failed to fetch installments", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	today := requestNow(r)
+	due, changed := splitInstallments(installments, today)
+	for i := range changed {
+		plan.ApplyTo(&changed[i])
+		changed[i].UpdatedAt = now
+	}
+
+	updatedPlan, err := e.repository.UpdateInstallmentPlan(e.ctx, plan, changed)
+	if err != nil {
+		logger.Error("Failed to update installment plan", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+	updatedPlan.Transactions = append(due, changed...)
+
+	logger.Info("Installment plan updated successfully", zap.String("plan_id", id))
+	ResponseWithData(w, http.StatusOK, updatedPlan)
+}
+
+// CancelInstallmentPlanByID deletes the remaining installments of a plan
+// and marks it cancelled. Installments already due are kept. Cancelling it
+// again answers 409, keeping the time it was first cancelled.
+func (e *InstallmentHandler) CancelInstallmentPlanByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to cancel installment plan", zap.String("plan_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := e.repository.GetInstallmentPlanByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Installment plan not found", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+	if plan.CancelledAt != nil {
+		ResponseWithError(w, conflictf("installment plan '%s' is cancelled", id))
+		return
+	}
+
+	installments, err := e.repository.ListInstallments(e.ctx, plan)
+	if err != nil {
+		logger.Error("Failed to fetch installments", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	kept, deleted := splitInstallments(installments, requestNow(r))
+	plan.CancelledAt = &now
+	plan.UpdatedAt = now
+	cancelled, err := e.repository.CancelInstallmentPlan(e.ctx, plan, deleted)
+	if err != nil {
+		logger.Error("Failed to cancel installment plan", err, zap.String("plan_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+	cancelled.Transactions = kept
+
+	logger.Info("Installment plan cancelled successfully", zap.String("plan_id", id), zap.Int("cancelled", len(deleted)))
+	ResponseWithData(w, http.StatusOK, cancelledPlan{
+		Plan:                  cancelled,
+		CancelledInstallments: len(deleted),
+	})
+}
+
+// splitInstallments separates the installments already due from the
+// remaining ones, keeping their order.
+func splitInstallments(installments []model.Transaction, today time.Time) ([]model.Transaction, []model.Transaction) {
+	due, rest := []model.Transaction{}, []model.Transaction{}
+	for _, installment := range installments {
+		if remaining(installment, today) {
+			rest = append(rest, installment)
+		} else {
+			due = append(due, installment)
+		}
+	}
+	return due, rest
+}
+
+// remaining reports whether installment falls due after today, the current
+// time of the caller.
+func remaining(installment model.Transaction, today time.Time) bool {
+	return installment.OccurredAt.Format("2006-01-02") > today.Format("2006-01-02")
+}
@@ -0,0 +1,35 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/handler"
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+func TestCancelledInstallmentPlanConflicts(t *testing.T) {
+	s := newServer(t)
+	r := s.do(http.MethodPost, "/api/transaction/", map[string]any{"type": "PURCHASE", "title": "Sofa", "amount": 3000, "installments": 3, "occurred_at": "2100-01-10"})
+	expect(t, r, http.StatusCreated, "")
+	path := "/api/installment/" + decode[model.InstallmentPlan](t, r).ID
+
+	r = s.do(http.MethodDelete, path, nil)
+	expect(t, r, http.StatusOK, "")
+	cancelled := decode[struct {
+		Plan                  model.InstallmentPlan `json:"plan"`
+		CancelledInstallments int                   `json:"cancelled_installments"`
+	}](t, r)
+	if cancelled.Plan.CancelledAt == nil || cancelled.CancelledInstallments != 3 {
+		t.Fatalf("cancel returned %+v", cancelled)
+	}
+
+	expect(t, s.do(http.MethodDelete, path, nil), http.StatusConflict, handler.CodeConflict)
+	expect(t, s.do(http.MethodPut, path, map[string]any{"title": "Couch"}), http.StatusConflict, handler.CodeConflict)
+
+	r = s.do(http.MethodGet, path, nil)
+	expect(t, r, http.StatusOK, "")
+	if stored := decode[model.InstallmentPlan](t, r); stored.Title != "Sofa" || !stored.CancelledAt.Equal(*cancelled.Plan.CancelledAt) {
+		t.Fatalf("stored plan %+v changed after it was cancelled", stored)
+	}
+}
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/recurrence"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/validation"
+	"go.uber.org/zap"
+)
+
+// Edit scopes accepted by UpdateRecurrenceByID.
+const (
+	// ScopeFuture applies an edit only to occurrences not created yet.
+	ScopeFuture = "future"
+	// ScopeThisAndFuture also rewrites the occurrences already created on
+	// or after the from query parameter.
+	ScopeThisAndFuture = "this_and_future"
+)
+
+type RecurrenceHandler struct {
+	ctx          context.Context
+	repository   repository.RecurrenceRepository
+	categories   repository.CategoryRepository
+	accounts     repository.AccountRepository
+	transactions repository.TransactionRepository
+}
+
+func NewRecurrenceHandler(ctx context.Context, repo repository.RecurrenceRepository, categories repository.CategoryRepository, accounts repository.AccountRepository, transactions repository.TransactionRepository) *RecurrenceHandler {
+	return &RecurrenceHandler{
+		ctx:          ctx,
+		repository:   repo,
+		categories:   categories,
+		accounts:     accounts,
+		transactions: transactions,
+	}
+}
+
+type updatedRecurrence struct {
+	Recurrence          *model.Recurrence `json:"recurrence"`
+	UpdatedTransactions int               `json:"updated_transactions"`
+}
+
+// NewRecurrence stores a template. Occurrences from start_date onwards,
+// including any already in the past, are created by the next scheduler
+// run. Each one is due once its date begins in the caller's time zone.
+func (e *RecurrenceHandler) NewRecurrence(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to create a new recurrence")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := Deserialize[dto.CreateRecurrenceInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	item := newRecurrence(owner, uuid.NewString(), input, now)
+	item.TimeZone = requestLocation(r).String()
+
+	rule, start, err := e.validate(owner, item, baseCurrency(r))
+	if err != nil {
+		logger.Error("Invalid recurrence", err, zap.String("recurrence_id", item.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	item.NextOccurrence = recurrence.NextOccurrence(rule, start, start.AddDate(0, 0, -1))
+	if item.NextOccurrence == nil {
+		ResponseWithError(w, validation.Errors{{Field: "rrule", Message: "has no occurrences"}})
+		return
+	}
+	item.SetKeys()
+
+	savedRecurrence, err := e.repository.NewRecurrence(e.ctx, item)
+	if err != nil {
+		logger.Error("Failed to save new recurrence", err, zap.String("recurrence_id", item.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Recurrence created successfully", zap.String("recurrence_id", savedRecurrence.ID))
+	setETag(w, savedRecurrence.Version)
+	ResponseWithData(w, http.StatusCreated, savedRecurrence)
+}
+
+func (e *RecurrenceHandler) ListRecurrences(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to list all recurrences")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	recurrences, err := e.repository.ListRecurrences(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch recurrences", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Recurrences retrieved successfully", zap.Int("count", len(recurrences)))
+	ResponseWithData(w, http.StatusOK, recurrences)
+}
+
+func (e *RecurrenceHandler) GetRecurrenceByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to fetch recurrence by ID", zap.String("recurrence_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := e.repository.GetRecurrenceByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Recurrence not found", err, zap.String("recurrence_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Recurrence retrieved successfully", zap.String("recurrence_id", id))
+	setETag(w, item.Version)
+	ResponseWithData(w, http.StatusOK, item)
+}
+
+// UpdateRecurrenceByID replaces a template. The schedule always changes
+// from the next occurrence not yet created; with the scope query parameter
+// set to this_and_future, transactions already created on or after the from
+// date are rewritten with the new template too. Those are rewritten before
+// the template is saved, so when the rewrite fails halfway the recurrence
+// keeps its version and resending the same request finishes the job.
+func (e *RecurrenceHandler) UpdateRecurrenceByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to update recurrence", zap.String("recurrence_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	scope := r.URL.Query().Get("scope")
+	from := r.URL.Query().Get("from")
+	switch scope {
+	case "", ScopeFuture:
+	case ScopeThisAndFuture:
+		if _, err := time.Parse(recurrence.DateLayout, from); err != nil {
+			ResponseWithError(w, invalidf("scope '%s' needs a from date like 2026-01-31", scope))
+			return
+		}
+	default:
+		ResponseWithError(w, invalidf("unknown scope '%s'", scope))
+		return
+	}
+
+	current, err := e.repository.GetRecurrenceByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Recurrence not found", err, zap.String("recurrence_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	input, err := Deserialize[dto.UpdateRecurrenceInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	version, err := requestVersion(r, input.Version)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if version != current.Version {
+		e.respondStale(w, current, fmt.Errorf("recurrence with ID '%s': %w", id, repository.ErrVersionMismatch))
+		return
+	}
+
+	now := time.Now().UTC()
+	item := newRecurrence(owner, current.ID, &input.CreateRecurrenceInput, now)
+	item.CreatedAt = current.CreatedAt
+	item.Version = version
+	item.TimeZone = requestLocation(r).String()
+
+	rule, start, err := e.validate(owner, item, baseCurrency(r))
+	if err != nil {
+		logger.Error("Invalid recurrence", err, zap.String("recurrence_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	// Everything before the current next occurrence has been created
+	// already; an ended rule resumes after today.
+	today := requestNow(r)
+	resume := today
+	if current.NextOccurrence != nil {
+		resume, _ = time.Parse(recurrence.DateLayout, *current.NextOccurrence)
+	}
+	item.NextOccurrence = recurrence.NextOccurrence(rule, start, resume.AddDate(0, 0, -1))
+	item.SetKeys()
+
+	updated := 0
+	if scope == ScopeThisAndFuture {
+		updated, err = e.rewriteOccurrences(owner, item, from, today.Format(recurrence.DateLayout), now)
+		if err != nil {
+			logger.Error("Failed to update created occurrences", err, zap.String("recurrence_id", id))
+			ResponseWithError(w, err)
+			return
+		}
+	}
+
+	updatedItem, err := e.repository.UpdateRecurrence(e.ctx, item)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		current, getErr := e.repository.GetRecurrenceByID(e.ctx, owner, id)
+		if getErr != nil {
+			logger.Error("Recurrence not found", getErr, zap.String("recurrence_id", id))
+			ResponseWithError(w, getErr)
+			return
+		}
+		e.respondStale(w, current, err)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to update recurrence", err, zap.String("recurrence_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Recurrence updated successfully", zap.String("recurrence_id", id), zap.Int("updated_transactions", updated))
+	setETag(w, updatedItem.Version)
+	ResponseWithData(w, http.StatusOK, updatedRecurrence{
+		Recurrence:          updatedItem,
+		UpdatedTransactions: updated,
+	})
+}
+
+// DeleteRecurrenceByID stops a recurrence. Transactions it already created
+// are kept.
+func (e *RecurrenceHandler) DeleteRecurrenceByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to delete recurrence", zap.String("recurrence_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := e.repository.GetRecurrenceByID(e.ctx, owner, id)
+	if err != nil {
+		logger.Error("Recurrence not found", err, zap.String("recurrence_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	_, err = e.repository.DeleteRecurrence(e.ctx, item)
+	if err != nil {
+		logger.Error("Failed to delete recurrence", err, zap.String("recurrence_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Recurrence deleted successfully", zap.String("recurrence_id", id))
+	ResponseNoContent(w)
+}
+
+// respondStale answers an update based on another version than current with
+// 409 and the recurrence as it is stored now.
+func (e *RecurrenceHandler) respondStale(w http.ResponseWriter, current *model.Recurrence, err error) {
+	logger.Error("Recurrence changed by another request", err, zap.String("recurrence_id", current.ID))
+	setETag(w, current.Version)
+	ResponseWithErrorData(w, err, current)
+}
+
+// rewriteOccurrences copies the template of item onto the transactions it
+// created between the dates from and until.
+func (e *RecurrenceHandler) rewriteOccurrences(owner string, item *model.Recurrence, from string, until string, now time.Time) (int, error) {
+	transactions, err := repository.ListAllTransactions(e.ctx, e.transactions, owner, from, until)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for i := range transactions {
+		transaction := &transactions[i]
+		if transaction.RecurrenceID == nil || *transaction.RecurrenceID != item.ID {
+			continue
+		}
+
+		item.ApplyTo(transaction)
+		transaction.UpdatedAt = now
+		transaction.SetKeys()
+		if _, err := e.transactions.UpdateTransaction(e.ctx, transaction); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func (e *RecurrenceHandler) validate(owner string, item *model.Recurrence, base string) (*recurrence.Rule, time.Time, error) {
+	rule, err := recurrence.Parse(item.RRule)
+	if err != nil {
+		return nil, time.Time{}, validation.Errors{{Field: "rrule", Message: err.Error()}}
+	}
+	start, err := time.Parse(recurrence.DateLayout, item.StartDate)
+	if err != nil {
+		return nil, time.Time{}, invalidf("start_date must be a date like 2026-01-31, got '%s'", item.StartDate)
+	}
+	if item.CategoryID != nil {
+		if _, err := e.categories.GetCategoryByID(e.ctx, owner, *item.CategoryID); err != nil {
+			return nil, time.Time{}, reference("category_id", err)
+		}
+	}
+	if item.Currency, err = accountCurrency(e.ctx, e.accounts, owner, item.AccountID, item.Currency, base); err != nil {
+		return nil, time.Time{}, err
+	}
+	return rule, start, nil
+}
+
+func newRecurrence(owner string, id string, input *dto.CreateRecurrenceInput, now time.Time) *model.Recurrence {
+	return &model.Recurrence{
+		OwnerID:     owner,
+		ID:          id,
+		RRule:       input.RRule,
+		StartDate:   input.StartDate,
+		Type:        input.Type,
+		Title:       input.Title,
+		Description: input.Description,
+		Money:       model.Money{Amount: input.Amount, Currency: input.Currency},
+		CategoryID:  input.CategoryID,
+		AccountID:   input.AccountID,
+		Tags:        model.NormalizeTags(input.Tags),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
@@ -0,0 +1,42 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/handler"
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+func TestUpdateRecurrenceVersion(t *testing.T) {
+	s := newServer(t)
+	body := map[string]any{"rrule": "FREQ=MONTHLY;BYMONTHDAY=5", "start_date": "2026-01-05", "type": "PURCHASE", "title": "Rent", "amount": 150000}
+
+	r := s.do(http.MethodPost, "/api/recurrence/", body)
+	expect(t, r, http.StatusCreated, "")
+	created := decode[model.Recurrence](t, r)
+	if r.Header.Get("ETag") != `"1"` || created.TimeZone != "UTC" {
+		t.Fatalf("created with ETag %q and zone %q", r.Header.Get("ETag"), created.TimeZone)
+	}
+
+	path := "/api/recurrence/" + created.ID
+	body["title"] = "Rent and condo"
+	expect(t, s.do(http.MethodPut, path, body), http.StatusPreconditionRequired, handler.CodeVersionRequired)
+
+	r = s.do(http.MethodPut, path, body, "If-Match", `"1"`)
+	expect(t, r, http.StatusOK, "")
+	if r.Header.Get("ETag") != `"2"` {
+		t.Fatalf("updated with ETag %q, want \"2\"", r.Header.Get("ETag"))
+	}
+
+	// A client still holding version 1 gets the stored recurrence back.
+	body["title"] = "Rent"
+	r = s.do(http.MethodPut, path, body, "If-Match", `"1"`)
+	expect(t, r, http.StatusConflict, handler.CodeConflict)
+	if current := decode[model.Recurrence](t, r); current.Title != "Rent and condo" || current.Version != 2 {
+		t.Fatalf("conflict returned %+v", current)
+	}
+	if r.Header.Get("ETag") != `"2"` {
+		t.Fatalf("conflict with ETag %q, want \"2\"", r.Header.Get("ETag"))
+	}
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ReportHandler aggregates transactions server-side so clients do not have
+// to page through them and sum amounts themselves.
+type ReportHandler struct {
+	ctx          context.Context
+	transactions repository.TransactionRepository
+	categories   repository.CategoryRepository
+	rates        repository.ExchangeRateRepository
+}
+
+func NewReportHandler(ctx context.Context, transactions repository.TransactionRepository, categories repository.CategoryRepository, rates repository.ExchangeRateRepository) *ReportHandler {
+	return &ReportHandler{
+		ctx:          ctx,
+		transactions: transactions,
+		categories:   categories,
+		rates:        rates,
+	}
+}
+
+// defaultTopTransactions is how many of the largest purchases the category
+// report lists unless the top query parameter asks otherwise.
+const defaultTopTransactions = 5
+
+// GetMonthlyReport totals income, purchases and investments per month of
+// the year query parameter, defaulting to the current year. Amounts are
+// converted to the currency query parameter, or to the base currency. The
+// monthly aggregates answer it unless amounts need converting or the year
+// has none, as in ledgers written before aggregates were kept, in which
+// case the transactions of the year are read.
+func (e *ReportHandler) GetMonthlyReport(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to fetch monthly report")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	year, err := yearParam(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	currency, err := reportCurrency(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	report := model.NewMonthlyReport(year, currency)
+	aggregates, err := e.transactions.ListMonthlyAggregates(e.ctx, owner, report.Months[0].Month, report.Months[11].Month)
+	if err != nil {
+		logger.Error("Failed to fetch monthly aggregates", err, zap.Int("year", year))
+		ResponseWithError(w, err)
+		return
+	}
+
+	// Aggregates sum whole months, while amounts are converted with the
+	// rate of the day they occurred, so they only serve a year recorded in
+	// the report currency alone. A year without any may predate them, so its
+	// transactions are read to tell.
+	if len(aggregates) > 0 && inCurrency(aggregates, baseCurrency(r), currency) {
+		for _, aggregate := range aggregates {
+			report.AddAggregate(aggregate)
+		}
+		logger.Info("Monthly report computed successfully", zap.Int("year", year), zap.Int("aggregates", len(aggregates)))
+		ResponseWithData(w, http.StatusOK, report)
+		return
+	}
+
+	startDate := fmt.Sprintf("%04d-01-01", year)
+	endDate := fmt.Sprintf("%04d-12-31", year)
+	transactions, err := repository.ListAllTransactions(e.ctx, e.transactions, owner, startDate, endDate)
+	if err != nil {
+		logger.Error("Failed to fetch transactions", err, zap.Int("year", year))
+		ResponseWithError(w, err)
+		return
+	}
+
+	converter, err := newAmountConverter(e.ctx, e.rates, owner, baseCurrency(r), currency)
+	if err != nil {
+		logger.Error("Failed to fetch exchange rates", err, zap.Int("year", year))
+		ResponseWithError(w, err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Type == model.TransactionTypeTransfer {
+			continue
+		}
+		amount, err := converter.amount(transaction)
+		if err != nil {
+			ResponseWithError(w, err)
+			return
+		}
+		report.Add(transaction, amount)
+	}
+
+	logger.Info("Monthly report computed successfully", zap.Int("year", year), zap.Int("transactions", len(transactions)))
+	ResponseWithData(w, http.StatusOK, report)
+}
+
+// GetCategoryReport breaks the purchases from the startDate through the
+// endDate query parameters, the current month by default, down by category
+// and lists the top largest of them. With compare=true every total is set
+// next to that of the previous period of the same length.
+func (e *ReportHandler) GetCategoryReport(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to fetch category report")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	loc := requestLocation(r)
+	now := requestNow(r)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	startDate, err := dateParam(query, "startDate", monthStart.Format(dateLayout), loc)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	endDate, err := dateParam(query, "endDate", monthStart.AddDate(0, 1, -1).Format(dateLayout), loc)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if endDate < startDate {
+		ResponseWithError(w, invalidf("endDate %s is before startDate %s", endDate, startDate))
+		return
+	}
+
+	top := defaultTopTransactions
+	if value := query.Get("top"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > maxPageSize {
+			ResponseWithError(w, invalidf("top must be between 0 and %d", maxPageSize))
+			return
+		}
+		top = parsed
+	}
+
+	compare := false
+	if value := query.Get("compare"); value != "" {
+		compare, err = strconv.ParseBool(value)
+		if err != nil {
+			ResponseWithError(w, invalidf("compare must be true or false, got '%s'", value))
+			return
+		}
+	}
+
+	currency, err := reportCurrency(r)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	// The previous period ends the day before startDate, so a single listing
+	// covers both.
+	listFrom := startDate
+	report := model.NewCategoryReport(startDate, endDate, currency)
+	var previous *model.CategoryReport
+	if compare {
+		start, _ := time.Parse(dateLayout, startDate)
+		end, _ := time.Parse(dateLayout, endDate)
+		previousStart, previousEnd := model.PreviousPeriod(start, end)
+		listFrom = previousStart.Format(dateLayout)
+		previous = model.NewCategoryReport(listFrom, previousEnd.Format(dateLayout), currency)
+	}
+
+	transactions, err := repository.ListAllTransactions(e.ctx, e.transactions, owner, listFrom, endDate)
+	if err != nil {
+		logger.Error("Failed to fetch transactions", err, zap.String("start_date", listFrom), zap.String("end_date", endDate))
+		ResponseWithError(w, err)
+		return
+	}
+
+	converter, err := newAmountConverter(e.ctx, e.rates, owner, baseCurrency(r), currency)
+	if err != nil {
+		logger.Error("Failed to fetch exchange rates", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Type != model.TransactionTypePurchase {
+			continue
+		}
+		amount, err := converter.amount(transaction)
+		if err != nil {
+			ResponseWithError(w, err)
+			return
+		}
+		if transaction.OccurredAt.Format(dateLayout) < startDate {
+			previous.Add(transaction, amount)
+		} else {
+			report.Add(transaction, amount)
+		}
+	}
+
+	categories, err := e.categories.ListCategories(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch categories", err)
+		ResponseWithError(w, err)
+		return
+	}
+	names := make(map[string]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	if previous != nil {
+		report.Compare(previous)
+	}
+	report.Finish(names, top)
+
+	logger.Info("Category report computed successfully", zap.String("start_date", startDate), zap.String("end_date", endDate), zap.Int("purchases", report.Count))
+	ResponseWithData(w, http.StatusOK, report)
+}
+
+// inCurrency reports whether every aggregate is in currency, taking those
+// stored without one as base.
+func inCurrency(aggregates []model.MonthlyAggregate, base string, currency string) bool {
+	for _, aggregate := range aggregates {
+		stored := aggregate.Currency
+		if stored == "" {
+			stored = base
+		}
+		if stored != currency {
+			return false
+		}
+	}
+	return true
+}
+
+// yearParam reads the year query parameter, defaulting to the current year
+// in the time zone of the caller.
+func yearParam(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("year")
+	if value == "" {
+		return requestNow(r).Year(), nil
+	}
+
+	year, err := strconv.Atoi(value)
+	if err != nil || year < 1 || year > 9999 {
+		return 0, invalidf("year must be a year like 2026, got '%s'", value)
+	}
+	return year, nil
+}
@@ -0,0 +1,148 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/handler"
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+// record creates a transaction for alice and fails the test unless it is
+// stored.
+func (s *server) record(kind model.TransactionType, amount int, currency string, occurredAt string, categoryID string) model.Transaction {
+	s.t.Helper()
+
+	body := map[string]any{"type": kind, "title": string(kind), "amount": amount, "occurred_at": occurredAt}
+	if currency != "" {
+		body["currency"] = currency
+	}
+	if categoryID != "" {
+		body["category_id"] = categoryID
+	}
+	r := s.do(http.MethodPost, "/api/transaction/", body)
+	expect(s.t, r, http.StatusCreated, "")
+	return decode[model.Transaction](s.t, r)
+}
+
+func TestMonthlyReport(t *testing.T) {
+	s := newServer(t)
+	for _, day := range []string{"2025-03-01", "2025-03-15", "2025-03-31"} {
+		s.record(model.TransactionTypePurchase, 2000, "", day, "")
+	}
+	s.record(model.TransactionTypeIncome, 10000, "", "2025-03-05", "")
+	s.record(model.TransactionTypeInvestment, 1000, "", "2025-03-05", "")
+	s.record(model.TransactionTypeIncome, 1000, "", "2026-01-01", "")
+
+	r := s.do(http.MethodGet, "/api/report/monthly?year=2025", nil)
+	expect(t, r, http.StatusOK, "")
+	report := decode[model.MonthlyReport](t, r)
+	if report.Year != 2025 || report.Currency != "BRL" || len(report.Months) != 12 {
+		t.Fatalf("report of %d in %s has %d months", report.Year, report.Currency, len(report.Months))
+	}
+
+	march := report.Months[2]
+	if march.Month != "2025-03" || march.Income != 10000 || march.Purchases != 6000 || march.Investments != 1000 || march.Net != 3000 {
+		t.Fatalf("March summed to %+v", march)
+	}
+	if march.SavingsRate == nil || *march.SavingsRate != 40 {
+		t.Fatalf("March savings rate = %v, want 40", march.SavingsRate)
+	}
+	if april := report.Months[3]; april.Income != 0 || april.Purchases != 0 || april.SavingsRate != nil {
+		t.Fatalf("April summed to %+v, want nothing", april)
+	}
+	if report.Total.Income != 10000 || report.Total.Purchases != 6000 || report.Total.Net != 3000 {
+		t.Fatalf("year summed to %+v", report.Total)
+	}
+}
+
+func TestMonthlyReportConvertsCurrencies(t *testing.T) {
+	s := newServer(t)
+	s.record(model.TransactionTypeIncome, 10000, "BRL", "2025-12-01", "")
+	s.record(model.TransactionTypePurchase, 1000, "USD", "2025-12-31", "")
+
+	expect(t, s.do(http.MethodGet, "/api/report/monthly?year=2025", nil), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+
+	r := s.do(http.MethodPost, "/api/exchange-rate", map[string]any{"base": "USD", "quote": "BRL", "date": "2025-01-01", "rate": 5})
+	expect(t, r, http.StatusCreated, "")
+
+	r = s.do(http.MethodGet, "/api/report/monthly?year=2025", nil)
+	expect(t, r, http.StatusOK, "")
+	december := decode[model.MonthlyReport](t, r).Months[11]
+	if december.Income != 10000 || december.Purchases != 5000 || december.Net != 5000 {
+		t.Fatalf("December summed to %+v in BRL", december)
+	}
+
+	r = s.do(http.MethodGet, "/api/report/monthly?year=2025&currency=usd", nil)
+	expect(t, r, http.StatusOK, "")
+	report := decode[model.MonthlyReport](t, r)
+	if report.Currency != "USD" || report.Total.Income != 2000 || report.Total.Purchases != 1000 {
+		t.Fatalf("year summed to %+v in %s", report.Total, report.Currency)
+	}
+
+	expect(t, s.do(http.MethodGet, "/api/report/monthly?year=abc", nil), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+	expect(t, s.do(http.MethodGet, "/api/report/monthly?currency=dollars", nil), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+}
+
+func TestCategoryReport(t *testing.T) {
+	s := newServer(t)
+	category := func(name string) string {
+		r := s.do(http.MethodPost, "/api/category/", map[string]any{"name": name})
+		expect(t, r, http.StatusCreated, "")
+		return decode[model.Category](t, r).ID
+	}
+	food, fun := category("Food"), category("Fun")
+
+	s.record(model.TransactionTypePurchase, 3000, "", "2026-03-02", food)
+	s.record(model.TransactionTypePurchase, 1000, "", "2026-03-31", food)
+	s.record(model.TransactionTypePurchase, 4000, "", "2026-03-10", "")
+	s.record(model.TransactionTypePurchase, 2000, "", "2026-03-11", fun)
+	s.record(model.TransactionTypeIncome, 9999, "", "2026-03-11", "")
+	s.record(model.TransactionTypePurchase, 2000, "", "2026-02-10", food)
+	s.record(model.TransactionTypePurchase, 500, "", "2026-02-28", "")
+	s.record(model.TransactionTypePurchase, 700, "", "2026-01-31", "")
+
+	r := s.do(http.MethodGet, "/api/report/categories?startDate=2026-03-01&endDate=2026-03-31&top=2&compare=true", nil)
+	expect(t, r, http.StatusOK, "")
+	report := decode[model.CategoryReport](t, r)
+	if report.Total != 10000 || report.Count != 4 {
+		t.Fatalf("March purchases summed to %d over %d, want 10000 over 4", report.Total, report.Count)
+	}
+
+	want := []struct {
+		name     string
+		total    int
+		share    float64
+		previous int
+	}{
+		{"Food", 4000, 40, 2000},
+		{"PURCHASE", 4000, 40, 500},
+		{"Fun", 2000, 20, 0},
+	}
+	if len(report.Categories) != len(want) {
+		t.Fatalf("report lists %d categories, want %d", len(report.Categories), len(want))
+	}
+	for i, w := range want {
+		got := report.Categories[i]
+		if got.Name != w.name || got.Total != w.total || got.Percentage != w.share || got.PreviousTotal == nil || *got.PreviousTotal != w.previous {
+			t.Fatalf("category %d is %+v, want %s totalling %d (%v%%), %d before", i, got, w.name, w.total, w.share, w.previous)
+		}
+	}
+	if change := report.Categories[0].Change; change == nil || *change != 100 {
+		t.Fatalf("Food changed by %v, want 100", change)
+	}
+	if change := report.Categories[2].Change; change != nil {
+		t.Fatalf("Fun changed by %v, want nothing to compare with", *change)
+	}
+
+	if len(report.Top) != 2 || report.Top[0].Amount != 4000 || report.Top[1].Amount != 3000 {
+		t.Fatalf("top purchases are %+v", report.Top)
+	}
+	previous := report.Previous
+	if previous == nil || previous.StartDate != "2026-02-01" || previous.EndDate != "2026-02-28" || previous.Total != 2500 {
+		t.Fatalf("previous period is %+v, want February totalling 2500", previous)
+	}
+	if previous.Change == nil || *previous.Change != 300 {
+		t.Fatalf("spending changed by %v, want 300", previous.Change)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/dto"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+type TagHandler struct {
+	ctx          context.Context
+	transactions repository.TransactionRepository
+}
+
+func NewTagHandler(ctx context.Context, transactions repository.TransactionRepository) *TagHandler {
+	return &TagHandler{
+		ctx:          ctx,
+		transactions: transactions,
+	}
+}
+
+type renamedTag struct {
+	From                string `json:"from"`
+	To                  string `json:"to"`
+	RenamedTransactions int    `json:"renamed_transactions"`
+}
+
+func (e *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Received request to list all tags")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := e.transactions.ListTags(e.ctx, owner)
+	if err != nil {
+		logger.Error("Failed to fetch tags", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Tags retrieved successfully", zap.Int("count", len(tags)))
+	ResponseWithData(w, http.StatusOK, tags)
+}
+
+// RenameTag renames a tag on every transaction. Renaming onto an existing
+// tag merges the two.
+func (e *TagHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	raw, err := url.PathUnescape(chi.URLParam(r, "tag"))
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	from := model.NormalizeTag(raw)
+	logger.Info("Received request to rename tag", zap.String("tag", from))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := Deserialize[dto.RenameTagInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	to := model.NormalizeTag(input.Name)
+	if from == "" || to == "" {
+		ResponseWithError(w, invalidf("tag names cannot be empty"))
+		return
+	}
+
+	renamed, err := e.transactions.RenameTag(e.ctx, owner, from, to)
+	if err != nil {
+		logger.Error("Failed to rename tag", err, zap.String("tag", from))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Tag renamed successfully", zap.String("from", from), zap.String("to", to), zap.Int("count", renamed))
+	ResponseWithData(w, http.StatusOK, renamedTag{
+		From:                from,
+		To:                  to,
+		RenamedTransactions: renamed,
+	})
+}
@@ -2,174 +2,725 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
-	"go.uber.org/zap"
-	"github.com/google/uuid"
+
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/cursor"
 	"github.com/joaoleau/muquirango/internal/dto"
 	"github.com/joaoleau/muquirango/internal/model"
 	"github.com/joaoleau/muquirango/internal/repository"
-	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
 )
 
 type TransactionHandler struct {
-	ctx 		context.Context
-	repository repository.TransactionRepo
+	ctx          context.Context
+	repository   repository.TransactionRepository
+	categories   repository.CategoryRepository
+	installments repository.InstallmentRepository
+	accounts     repository.AccountRepository
+	cursors      *cursor.Codec
 }
 
-func NewTransactionHandler(ctx context.Context, repo repository.TransactionRepo) *TransactionHandler {
+func NewTransactionHandler(ctx context.Context, repo repository.TransactionRepository, categories repository.CategoryRepository, installments repository.InstallmentRepository, accounts repository.AccountRepository, cursors *cursor.Codec) *TransactionHandler {
 	return &TransactionHandler{
-		repository: repo,
-		ctx: ctx,
+		repository:   repo,
+		categories:   categories,
+		installments: installments,
+		accounts:     accounts,
+		ctx:          ctx,
+		cursors:      cursors,
 	}
 }
 
+// NewTransaction creates a single transaction, an installment plan with its
+// installments when installments is above one, or both legs of a transfer
+// from account_id to to_account_id when the type is TRANSFER.
 func (e *TransactionHandler) NewTransaction(w http.ResponseWriter, r *http.Request) {
 	logger.Info("Received request to create a new transaction")
 
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
 	input, err := Deserialize[dto.CreateTransactionInput](r)
 	if err != nil {
 		logger.Error("Failed to deserialize request body", err)
-		ResponseWithError(w, http.StatusBadRequest, err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	record, err := newIdempotencyRecord(owner, r.Header.Get(IdempotencyKeyHeader), input, time.Now().UTC())
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	if record != nil && e.replay(w, record) {
+		return
+	}
+
+	if input.Installments > 1 {
+		e.newInstallmentPlan(w, owner, input, record, requestLocation(r), baseCurrency(r))
+		return
+	}
+	if input.Type == model.TransactionTypeTransfer {
+		e.newTransfer(w, owner, input, record, requestLocation(r))
 		return
 	}
 
+	now := time.Now().UTC()
 	transaction := &model.Transaction{
+		OwnerID:     owner,
 		ID:          uuid.NewString(),
-		Title: input.Title,
+		Title:       input.Title,
 		Type:        input.Type,
 		Description: input.Description,
-		Amount: input.Amount,
-		CreatedAt:   time.Now().UTC(),
+		Money:       model.Money{Amount: input.Amount},
+		CategoryID:  input.CategoryID,
+		AccountID:   input.AccountID,
+		Tags:        model.NormalizeTags(input.Tags),
+		OccurredAt:  parseOccurredAt(input.OccurredAt, now.In(requestLocation(r)), requestLocation(r)),
+		CreatedAt:   now,
 	}
 
 	transaction.SetKeys()
 
-	savedTransaction, err := e.repository.NewTransaction(e.ctx, transaction)
+	if err := e.validateCategory(owner, transaction.CategoryID); err != nil {
+		logger.Error("Invalid category", err, zap.String("transaction_id", transaction.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	transaction.Currency, err = accountCurrency(e.ctx, e.accounts, owner, transaction.AccountID, input.Currency, baseCurrency(r))
+	if err != nil {
+		logger.Error("Invalid account", err, zap.String("transaction_id", transaction.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	savedTransaction, err := e.repository.NewTransaction(e.ctx, transaction, record)
+	if errors.Is(err, repository.ErrIdempotencyKeyUsed) && e.replay(w, record) {
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to save new transaction", err, zap.String("transaction_id", transaction.ID))
-		ResponseWithError(w, http.StatusInternalServerError, err)
+		ResponseWithError(w, err)
 		return
 	}
 
 	logger.Info("Transaction created successfully", zap.String("transaction_id", savedTransaction.ID))
+	setETag(w, savedTransaction.Version)
 	ResponseWithData(w, http.StatusCreated, savedTransaction)
 }
 
 func (e *TransactionHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
 	logger.Info("Received request to list all transactions")
-    query := r.URL.Query()
-    
-    startDate := query.Get("startDate"); if startDate == "" {
-		startDate = time.Now().AddDate(0, 0, -3).Format("2006-01-02")
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
 	}
-	endDate := query.Get("endDate"); if endDate == "" {
-		endDate = time.Now().Format("2006-01-02")
+
+	query := r.URL.Query()
+
+	loc := requestLocation(r)
+	now := requestNow(r)
+	startDate, err := dateParam(query, "startDate", now.AddDate(0, 0, -3).Format(dateLayout), loc)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	endDate, err := dateParam(query, "endDate", now.Format(dateLayout), loc)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	limit := defaultPageSize
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			ResponseWithError(w, invalidf("limit must be between 1 and %d", maxPageSize))
+			return
+		}
+		limit = parsed
+	}
+
+	startKey, err := e.cursors.Decode(owner, query.Get("cursor"))
+	if err != nil {
+		logger.Error("Failed to decode cursor", err)
+		if errors.Is(err, cursor.ErrInvalid) {
+			err = invalidf("%s", err)
+		}
+		ResponseWithError(w, err)
+		return
 	}
 
-	transactions, err := e.repository.ListTransactions(e.ctx, startDate, endDate)
+	page, err := e.repository.ListTransactions(e.ctx, owner, startDate, endDate, repository.ListOptions{
+		Limit:     limit,
+		StartKey:  startKey,
+		Tag:       model.NormalizeTag(query.Get("tag")),
+		AccountID: query.Get("accountId"),
+	})
 	if err != nil {
 		logger.Error("Failed to fetch transactions", err)
-		ResponseWithError(w, http.StatusInternalServerError, err)
-		return 
+		ResponseWithError(w, err)
+		return
+	}
+
+	nextCursor, err := e.cursors.Encode(owner, page.NextKey)
+	if err != nil {
+		logger.Error("Failed to encode cursor", err)
+		ResponseWithError(w, err)
+		return
 	}
 
-	logger.Info("Transactions retrieved successfully", zap.Int("count", len(*transactions)))
-	ResponseWithData(w, http.StatusAccepted, transactions) 
+	logger.Info("Transactions retrieved successfully", zap.Int("count", len(page.Items)))
+	ResponseWithPage(w, http.StatusOK, page.Items, nextCursor)
 }
 
 func (e *TransactionHandler) UpdateTransactionByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	logger.Info("Received request to update transaction", zap.String("transaction_id", id))
 
-    query := r.URL.Query()
-    
-	createdAt := query.Get("createdAt"); if createdAt == "" {
-		createdAt = time.Now().Format("2006-01-02")
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
 	}
 
-	updateTransaction, err := e.repository.GetTransactionByID(e.ctx, id, createdAt)
+	query := r.URL.Query()
+
+	// occurredAt is an optional hint; without it the lookup goes by ID alone.
+	occurredAt := dateHint(query)
+
+	updateTransaction, err := e.repository.GetTransactionByID(e.ctx, owner, id, occurredAt)
 	if err != nil {
 		logger.Error("Transaction not found", err, zap.String("transaction_id", id))
-		ResponseWithError(w, http.StatusBadRequest, err)
+		ResponseWithError(w, err)
 		return
 	}
 
-	input, err := Deserialize[dto.CreateTransactionInput](r)
+	input, err := Deserialize[dto.UpdateTransactionInput](r)
 	if err != nil {
 		logger.Error("Failed to deserialize request body", err)
-		ResponseWithError(w, http.StatusBadRequest, err)
+		ResponseWithError(w, err)
+		return
+	}
+	if input.Installments > 1 {
+		ResponseWithError(w, invalidf("installments can only be set when creating a transaction"))
+		return
+	}
+
+	version, err := requestVersion(r, input.Version)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	updateTransaction.Version = version
+
+	if updateTransaction.IsTransfer() || input.Type == model.TransactionTypeTransfer {
+		e.updateTransfer(w, owner, updateTransaction, &input.CreateTransactionInput, requestLocation(r))
 		return
 	}
 
+	// The stored keys name the item to replace; the repository moves it
+	// when the new date files it under another key.
 	newTransaction := &model.Transaction{
+		PK:          updateTransaction.PK,
+		SK:          updateTransaction.SK,
+		GSI1PK:      updateTransaction.GSI1PK,
+		GSI1SK:      updateTransaction.GSI1SK,
+		OwnerID:     owner,
 		ID:          updateTransaction.ID,
-		Title: 		 input.Title,
+		Title:       input.Title,
 		Type:        input.Type,
 		Description: input.Description,
-		Amount:		 input.Amount,
+		Money:       model.Money{Amount: input.Amount},
+		CategoryID:  input.CategoryID,
+		AccountID:   input.AccountID,
+		Tags:        model.NormalizeTags(input.Tags),
+		OccurredAt:  parseOccurredAt(input.OccurredAt, updateTransaction.OccurredAt, requestLocation(r)),
 		CreatedAt:   updateTransaction.CreatedAt,
-		UpdatedAt: 	 time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+		Version:     version,
+	}
+
+	if err := e.validateCategory(owner, newTransaction.CategoryID); err != nil {
+		logger.Error("Invalid category", err, zap.String("transaction_id", id))
+		ResponseWithError(w, err)
+		return
 	}
-	newTransaction.SetKeys()
 
-	_, err = e.repository.UpdateTransaction(e.ctx, newTransaction)
+	newTransaction.Currency, err = accountCurrency(e.ctx, e.accounts, owner, newTransaction.AccountID, input.Currency, baseCurrency(r))
+	if err != nil {
+		logger.Error("Invalid account", err, zap.String("transaction_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	updatedTransaction, err := e.repository.UpdateTransaction(e.ctx, newTransaction)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		e.respondStale(w, owner, id, err)
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to update transaction", err, zap.String("transaction_id", id))
-		ResponseWithError(w, http.StatusBadRequest, err)
+		ResponseWithError(w, err)
 		return
 	}
 
 	logger.Info("Transaction updated successfully", zap.String("transaction_id", id))
-	ResponseWithData(w, http.StatusAccepted, newTransaction)
+	setETag(w, updatedTransaction.Version)
+	ResponseWithData(w, http.StatusOK, updatedTransaction)
+}
+
+// PatchTransactionByID applies a JSON Merge Patch (RFC 7396) to a
+// transaction: fields left out keep their stored value and optional fields
+// sent as null are removed. On a transfer leg the patch changes both legs
+// and may only touch the fields they share.
+func (e *TransactionHandler) PatchTransactionByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	logger.Info("Received request to patch transaction", zap.String("transaction_id", id))
+
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	// occurredAt is an optional hint; without it the lookup goes by ID alone.
+	occurredAt := dateHint(query)
+
+	patchTransaction, err := e.repository.GetTransactionByID(e.ctx, owner, id, occurredAt)
+	if err != nil {
+		logger.Error("Transaction not found", err, zap.String("transaction_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	input, err := Deserialize[dto.PatchTransactionInput](r)
+	if err != nil {
+		logger.Error("Failed to deserialize request body", err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	version, err := requestVersion(r, input.Version)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	patchTransaction.Version = version
+
+	patch := &input.TransactionPatch
+	if patch.Tags.Value != nil {
+		tags := model.NormalizeTags(*patch.Tags.Value)
+		patch.Tags.Value = &tags
+	}
+	if input.OccurredAt.Value != nil {
+		date := parseOccurredAt(input.OccurredAt.Value, patchTransaction.OccurredAt, requestLocation(r))
+		patch.OccurredAt = &date
+	}
+	patch.UpdatedAt = time.Now().UTC()
+
+	if patchTransaction.IsTransfer() {
+		e.patchTransfer(w, owner, patchTransaction, patch)
+		return
+	}
+
+	if err := e.validateCategory(owner, patch.CategoryID.Value); err != nil {
+		logger.Error("Invalid category", err, zap.String("transaction_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	// The currency follows the account: one sent must match it, and one
+	// left out is kept unless the account changes.
+	requested := patch.Currency.Or("")
+	if !patch.Currency.Set && !patch.AccountID.Set {
+		requested = patchTransaction.Currency
+	}
+	accountID := patchTransaction.AccountID
+	if patch.AccountID.Set {
+		accountID = patch.AccountID.Value
+	}
+	currency, err := accountCurrency(e.ctx, e.accounts, owner, accountID, requested, baseCurrency(r))
+	if err != nil {
+		logger.Error("Invalid account", err, zap.String("transaction_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+	if currency != patchTransaction.Currency {
+		patch.Currency = model.Optional[string]{Set: true, Value: &currency}
+	}
+
+	patchedTransaction, err := e.repository.PatchTransaction(e.ctx, patchTransaction, patch)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		e.respondStale(w, owner, id, err)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to patch transaction", err, zap.String("transaction_id", id))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Transaction patched successfully", zap.String("transaction_id", id))
+	setETag(w, patchedTransaction.Version)
+	ResponseWithData(w, http.StatusOK, patchedTransaction)
 }
 
 func (e *TransactionHandler) DeleteTransactionByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	logger.Info("Received request to delete transaction", zap.String("transaction_id", id))
 
-    query := r.URL.Query()
-    
-	createdAt := query.Get("createdAt"); if createdAt == "" {
-		createdAt = time.Now().Format("2006-01-02")
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
 	}
 
-	deleteTransaction, err := e.repository.GetTransactionByID(e.ctx, id, createdAt)
+	query := r.URL.Query()
+
+	// occurredAt is an optional hint; without it the lookup goes by ID alone.
+	occurredAt := dateHint(query)
+
+	deleteTransaction, err := e.repository.GetTransactionByID(e.ctx, owner, id, occurredAt)
 	if err != nil {
 		logger.Error("Transaction not found", err, zap.String("transaction_id", id))
-		ResponseWithError(w, http.StatusBadRequest, err)
+		ResponseWithError(w, err)
+		return
+	}
+
+	version, err := requestVersion(r, nil)
+	if err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+	deleteTransaction.Version = version
+
+	if deleteTransaction.IsTransfer() {
+		e.deleteTransfer(w, owner, deleteTransaction)
 		return
 	}
 
 	_, err = e.repository.DeleteTransaction(e.ctx, deleteTransaction)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		e.respondStale(w, owner, id, err)
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to delete transaction", err, zap.String("transaction_id", id))
-		ResponseWithError(w, http.StatusBadRequest, err)
+		ResponseWithError(w, err)
 		return
 	}
 
 	logger.Info("Transaction deleted successfully", zap.String("transaction_id", id))
-	ResponseWithData(w, http.StatusAccepted, deleteTransaction)
+	ResponseNoContent(w)
 }
 
 func (e *TransactionHandler) GetTransactionByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	logger.Info("Received request to fetch transaction by ID", zap.String("transaction_id", id))
 
-    query := r.URL.Query()
-    
-	createdAt := query.Get("createdAt"); if createdAt == "" {
-		createdAt = time.Now().Format("2006-01-02")
+	owner, ok := requestOwner(w, r)
+	if !ok {
+		return
 	}
 
-	transaction, err := e.repository.GetTransactionByID(e.ctx, id, createdAt)
+	query := r.URL.Query()
+
+	// occurredAt is an optional hint; without it the lookup goes by ID alone.
+	occurredAt := dateHint(query)
+
+	transaction, err := e.repository.GetTransactionByID(e.ctx, owner, id, occurredAt)
 	if err != nil {
 		logger.Error("Transaction not found", err, zap.String("transaction_id", id))
-		ResponseWithError(w, http.StatusBadRequest, err)
+		ResponseWithError(w, err)
 		return
 	}
 
 	logger.Info("Transaction retrieved successfully", zap.String("transaction_id", id))
-	ResponseWithData(w, http.StatusAccepted, transaction)
+	setETag(w, transaction.Version)
+	ResponseWithData(w, http.StatusOK, transaction)
+}
+
+func (e *TransactionHandler) newInstallmentPlan(w http.ResponseWriter, owner string, input *dto.CreateTransactionInput, record *model.IdempotencyRecord, loc *time.Location, base string) {
+	if limit := e.installments.MaxInstallments(); input.Installments > limit {
+		ResponseWithError(w, invalidf("a purchase can be split into at most %d installments", limit))
+		return
+	}
+
+	now := time.Now().UTC()
+	plan := &model.InstallmentPlan{
+		OwnerID:      owner,
+		ID:           uuid.NewString(),
+		Title:        input.Title,
+		Description:  input.Description,
+		Money:        model.Money{Amount: input.Amount},
+		Installments: input.Installments,
+		CategoryID:   input.CategoryID,
+		AccountID:    input.AccountID,
+		Tags:         model.NormalizeTags(input.Tags),
+		OccurredAt:   parseOccurredAt(input.OccurredAt, now.In(loc), loc),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	plan.SetKeys()
+
+	if err := e.validateCategory(owner, plan.CategoryID); err != nil {
+		logger.Error("Invalid category", err, zap.String("plan_id", plan.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	currency, err := accountCurrency(e.ctx, e.accounts, owner, plan.AccountID, input.Currency, base)
+	if err != nil {
+		logger.Error("Invalid account", err, zap.String("plan_id", plan.ID))
+		ResponseWithError(w, err)
+		return
+	}
+	plan.Currency = currency
+
+	plan.Transactions = plan.Split()
+	savedPlan, err := e.installments.NewInstallmentPlan(e.ctx, plan, plan.Transactions, record)
+	if errors.Is(err, repository.ErrIdempotencyKeyUsed) && e.replay(w, record) {
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to save new installment plan", err, zap.String("plan_id", plan.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Installment plan created successfully", zap.String("plan_id", savedPlan.ID), zap.Int("installments", len(savedPlan.Transactions)))
+	ResponseWithData(w, http.StatusCreated, savedPlan)
+}
+
+func (e *TransactionHandler) newTransfer(w http.ResponseWriter, owner string, input *dto.CreateTransactionInput, record *model.IdempotencyRecord, loc *time.Location) {
+	now := time.Now().UTC()
+	transfer := &model.Transfer{
+		From: model.Transaction{OwnerID: owner, ID: uuid.NewString(), OccurredAt: now.In(loc), CreatedAt: now},
+		To:   model.Transaction{OwnerID: owner, ID: uuid.NewString()},
+	}
+	applyTransferInput(transfer, input, loc)
+	transfer.Link()
+
+	if err := e.validateTransfer(owner, transfer); err != nil {
+		logger.Error("Invalid transfer", err, zap.String("transaction_id", transfer.From.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	savedTransfer, err := e.repository.NewTransfer(e.ctx, transfer, record)
+	if errors.Is(err, repository.ErrIdempotencyKeyUsed) && e.replay(w, record) {
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to save new transfer", err, zap.String("transaction_id", transfer.From.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Transfer created successfully", zap.String("transaction_id", savedTransfer.From.ID))
+	ResponseWithData(w, http.StatusCreated, savedTransfer)
+}
+
+// updateTransfer rewrites both legs of the transfer leg belongs to. A
+// transfer stays a transfer: switching type either way is rejected.
+func (e *TransactionHandler) updateTransfer(w http.ResponseWriter, owner string, leg *model.Transaction, input *dto.CreateTransactionInput, loc *time.Location) {
+	if !leg.IsTransfer() || input.Type != model.TransactionTypeTransfer {
+		ResponseWithError(w, invalidf("transfers cannot change type, delete and record the transaction again"))
+		return
+	}
+
+	transfer, err := e.loadTransfer(owner, leg)
+	if err != nil {
+		logger.Error("Transfer not found", err, zap.String("transaction_id", leg.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	// The legs keep their stored keys so the repository knows which items
+	// to replace.
+	now := time.Now().UTC()
+	transfer.From.UpdatedAt = now
+	transfer.To.UpdatedAt = now
+	applyTransferInput(transfer, input, loc)
+
+	if err := e.validateTransfer(owner, transfer); err != nil {
+		logger.Error("Invalid transfer", err, zap.String("transaction_id", leg.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	e.saveTransfer(w, owner, leg, transfer)
+}
+
+// patchTransfer applies patch to both legs of the transfer leg belongs to.
+// Accounts are changed with a full update, which checks their currencies.
+func (e *TransactionHandler) patchTransfer(w http.ResponseWriter, owner string, leg *model.Transaction, patch *model.TransactionPatch) {
+	check := validation.Checker{}
+	check.Check(!patch.Type.Set, "type", "transfers cannot change type, delete and record the transaction again")
+	check.Check(!patch.CategoryID.Set, "category_id", "is not accepted on transfers")
+	check.Check(!patch.AccountID.Set, "account_id", "is changed on transfers with a full update")
+	check.Check(!patch.Currency.Set, "currency", "is the currency of the accounts on transfers")
+	if err := check.Err(); err != nil {
+		ResponseWithError(w, err)
+		return
+	}
+
+	transfer, err := e.loadTransfer(owner, leg)
+	if err != nil {
+		logger.Error("Transfer not found", err, zap.String("transaction_id", leg.ID))
+		ResponseWithError(w, err)
+		return
+	}
+	patch.ApplyTo(&transfer.From)
+	patch.ApplyTo(&transfer.To)
+
+	e.saveTransfer(w, owner, leg, transfer)
+}
+
+// saveTransfer writes both legs of transfer and answers with them, tagged
+// with the version of leg, the one the client addressed.
+func (e *TransactionHandler) saveTransfer(w http.ResponseWriter, owner string, leg *model.Transaction, transfer *model.Transfer) {
+	updatedTransfer, err := e.repository.UpdateTransfer(e.ctx, transfer)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		e.respondStale(w, owner, leg.ID, err)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to update transfer", err, zap.String("transaction_id", leg.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Transfer updated successfully", zap.String("transaction_id", leg.ID))
+	if updatedTransfer.From.ID == leg.ID {
+		setETag(w, updatedTransfer.From.Version)
+	} else {
+		setETag(w, updatedTransfer.To.Version)
+	}
+	ResponseWithData(w, http.StatusOK, updatedTransfer)
+}
+
+func (e *TransactionHandler) deleteTransfer(w http.ResponseWriter, owner string, leg *model.Transaction) {
+	transfer, err := e.loadTransfer(owner, leg)
+	if err != nil {
+		logger.Error("Transfer not found", err, zap.String("transaction_id", leg.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	_, err = e.repository.DeleteTransfer(e.ctx, transfer)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		e.respondStale(w, owner, leg.ID, err)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to delete transfer", err, zap.String("transaction_id", leg.ID))
+		ResponseWithError(w, err)
+		return
+	}
+
+	logger.Info("Transfer deleted successfully", zap.String("transaction_id", leg.ID))
+	ResponseNoContent(w)
+}
+
+// respondStale answers a write that lost against another edit with 409 and
+// the transaction as it is stored now, so the client can reapply its change
+// on top of it.
+func (e *TransactionHandler) respondStale(w http.ResponseWriter, owner string, id string, err error) {
+	logger.Error("Transaction changed by another request", err, zap.String("transaction_id", id))
+
+	current, getErr := e.repository.GetTransactionByID(e.ctx, owner, id, "")
+	if getErr != nil {
+		logger.Error("Transaction not found", getErr, zap.String("transaction_id", id))
+		ResponseWithError(w, getErr)
+		return
+	}
+
+	setETag(w, current.Version)
+	ResponseWithErrorData(w, err, current)
+}
+
+// loadTransfer fetches the counterpart of leg and pairs both legs up.
+func (e *TransactionHandler) loadTransfer(owner string, leg *model.Transaction) (*model.Transfer, error) {
+	if leg.CounterpartID == nil {
+		return nil, fmt.Errorf("transfer leg '%s' has no counterpart", leg.ID)
+	}
+
+	counterpart, err := e.repository.GetTransactionByID(e.ctx, owner, *leg.CounterpartID, leg.OccurredAt.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	if leg.Direction == model.TransferOut {
+		return &model.Transfer{From: *leg, To: *counterpart}, nil
+	}
+	return &model.Transfer{From: *counterpart, To: *leg}, nil
+}
+
+// validateTransfer ensures both accounts belong to owner and share a
+// currency, so the amount means the same on both sides, and records that
+// currency on both legs. A currency the client sent must be the same.
+func (e *TransactionHandler) validateTransfer(owner string, transfer *model.Transfer) error {
+	from, err := e.accounts.GetAccountByID(e.ctx, owner, *transfer.From.AccountID)
+	if err != nil {
+		return reference("account_id", err)
+	}
+	to, err := e.accounts.GetAccountByID(e.ctx, owner, *transfer.To.AccountID)
+	if err != nil {
+		return reference("to_account_id", err)
+	}
+	if from.Currency != to.Currency {
+		return invalidf("cannot transfer between %s and %s accounts", from.Currency, to.Currency)
+	}
+	if transfer.From.Currency != "" && transfer.From.Currency != from.Currency {
+		return validation.Errors{{Field: "currency", Message: fmt.Sprintf("must be %s, the currency of the accounts", from.Currency)}}
+	}
+	transfer.From.Currency, transfer.To.Currency = from.Currency, to.Currency
+	return nil
+}
+
+// applyTransferInput copies the fields a client controls onto both legs,
+// reading a date sent as a moment in loc.
+func applyTransferInput(transfer *model.Transfer, input *dto.CreateTransactionInput, loc *time.Location) {
+	tags := model.NormalizeTags(input.Tags)
+	for _, leg := range []*model.Transaction{&transfer.From, &transfer.To} {
+		leg.Title = input.Title
+		leg.Description = input.Description
+		leg.Money = model.Money{Amount: input.Amount, Currency: model.NormalizeCurrency(input.Currency)}
+		leg.CategoryID = input.CategoryID
+		leg.Tags = tags
+	}
+	if input.OccurredAt != nil {
+		transfer.From.OccurredAt = parseOccurredAt(input.OccurredAt, transfer.From.OccurredAt, loc)
+		transfer.To.OccurredAt = transfer.From.OccurredAt
+	}
+	transfer.From.AccountID = input.AccountID
+	transfer.To.AccountID = input.ToAccountID
+}
+
+// validateCategory ensures a referenced category belongs to owner.
+func (e *TransactionHandler) validateCategory(owner string, categoryID *string) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	_, err := e.categories.GetCategoryByID(e.ctx, owner, *categoryID)
+	return reference("category_id", err)
 }
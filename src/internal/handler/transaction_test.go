@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/handler"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+func TestNewTransactionValidation(t *testing.T) {
+	s := newServer(t)
+
+	r := s.do(http.MethodPost, "/api/transaction/", map[string]any{
+		"type":   "GIFT",
+		"title":  "",
+		"amount": -100,
+	})
+	expect(t, r, http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+
+	fields := map[string]bool{}
+	for _, err := range r.Errors {
+		fields[err.Field] = true
+	}
+	for _, field := range []string{"type", "title", "amount"} {
+		if !fields[field] {
+			t.Fatalf("errors %+v do not name %s", r.Errors, field)
+		}
+	}
+
+	r = s.do(http.MethodGet, "/api/transaction/?startDate=2000-01-01&endDate=2100-12-31", nil)
+	expect(t, r, http.StatusOK, "")
+	if listed := decode[[]model.Transaction](t, r); len(listed) != 0 {
+		t.Fatalf("invalid transaction stored: %+v", listed)
+	}
+}
+
+func TestUpdateTransactionStaleVersion(t *testing.T) {
+	s := newServer(t)
+	r := s.do(http.MethodPost, "/api/transaction/", map[string]any{"type": "PURCHASE", "title": "Groceries", "amount": 100})
+	expect(t, r, http.StatusCreated, "")
+	created := decode[model.Transaction](t, r)
+	path := "/api/transaction/" + created.ID
+
+	body := map[string]any{"type": "PURCHASE", "title": "Market", "amount": 200}
+	expect(t, s.do(http.MethodPut, path, body), http.StatusPreconditionRequired, handler.CodeVersionRequired)
+
+	r = s.do(http.MethodPut, path, body, "If-Match", `"1"`)
+	expect(t, r, http.StatusOK, "")
+	if updated := decode[model.Transaction](t, r); updated.Version != 2 || updated.Title != "Market" {
+		t.Fatalf("PUT returned %+v", updated)
+	}
+	if etag := r.Header.Get("ETag"); etag != `"2"` {
+		t.Fatalf("PUT answered ETag %s, want \"2\"", etag)
+	}
+
+	// Another client still holding version 1 loses and is shown what won.
+	body["title"] = "Stale"
+	r = s.do(http.MethodPut, path, body, "If-Match", `"1"`)
+	expect(t, r, http.StatusConflict, handler.CodeConflict)
+	if stored := decode[model.Transaction](t, r); stored.Version != 2 || stored.Title != "Market" {
+		t.Fatalf("conflict carried %+v, want the stored version 2", stored)
+	}
+	expect(t, s.do(http.MethodDelete, path, nil, "If-Match", `W/"1"`), http.StatusConflict, handler.CodeConflict)
+
+	expect(t, s.do(http.MethodDelete, path, nil, "If-Match", `"2"`), http.StatusNoContent, "")
+}
+
+func TestNewTransactionIdempotencyKey(t *testing.T) {
+	s := newServer(t)
+	body := map[string]any{"type": "PURCHASE", "title": "Coffee", "amount": 500}
+
+	r := s.do(http.MethodPost, "/api/transaction/", body, "Idempotency-Key", "coffee")
+	expect(t, r, http.StatusCreated, "")
+	created := decode[model.Transaction](t, r)
+
+	r = s.do(http.MethodPost, "/api/transaction/", body, "Idempotency-Key", "coffee")
+	expect(t, r, http.StatusCreated, "")
+	if replayed := decode[model.Transaction](t, r); replayed.ID != created.ID || replayed.Version != created.Version {
+		t.Fatalf("replay returned %+v, want %+v", replayed, created)
+	}
+
+	body["amount"] = 600
+	expect(t, s.do(http.MethodPost, "/api/transaction/", body, "Idempotency-Key", "coffee"), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+
+	r = s.do(http.MethodGet, "/api/transaction/?startDate=2000-01-01&endDate=2100-12-31", nil)
+	expect(t, r, http.StatusOK, "")
+	if listed := decode[[]model.Transaction](t, r); len(listed) != 1 {
+		t.Fatalf("listed %d transactions, want the one created once", len(listed))
+	}
+
+	// Keys belong to their owner.
+	r = s.send(token(t, "bob"), http.MethodPost, "/api/transaction/", body, "Idempotency-Key", "coffee")
+	expect(t, r, http.StatusCreated, "")
+	if theirs := decode[model.Transaction](t, r); theirs.ID == created.ID {
+		t.Fatalf("bob replayed alice's transaction %s", created.ID)
+	}
+}
+
+func TestNewTransactionTooManyInstallments(t *testing.T) {
+	s := newServer(t)
+	body := map[string]any{"type": "PURCHASE", "title": "Car", "amount": 9900000, "installments": repository.MaxInstallments + 1}
+
+	expect(t, s.do(http.MethodPost, "/api/transaction/", body), http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+
+	body["installments"] = repository.MaxInstallments
+	expect(t, s.do(http.MethodPost, "/api/transaction/", body), http.StatusCreated, "")
+}
+
+func TestPatchTransactionNullAndAbsent(t *testing.T) {
+	s := newServer(t)
+	food := s.category("Food", "")
+	r := s.do(http.MethodPost, "/api/transaction/", map[string]any{"type": "PURCHASE", "title": "Groceries", "amount": 100, "description": "weekly", "category_id": food.ID, "tags": []string{"home"}})
+	expect(t, r, http.StatusCreated, "")
+	path := "/api/transaction/" + decode[model.Transaction](t, r).ID
+
+	// Fields left out keep their stored value.
+	r = s.do(http.MethodPatch, path, map[string]any{"title": "Market"}, "If-Match", `"1"`)
+	expect(t, r, http.StatusOK, "")
+	kept := decode[model.Transaction](t, r)
+	if kept.Title != "Market" || kept.Description == nil || *kept.Description != "weekly" || kept.CategoryID == nil || *kept.CategoryID != food.ID || len(kept.Tags) != 1 || kept.Amount != 100 {
+		t.Fatalf("patching the title left %+v", kept)
+	}
+
+	// Fields sent as null are removed.
+	r = s.do(http.MethodPatch, path, map[string]any{"description": nil, "category_id": nil, "tags": nil}, "If-Match", `"2"`)
+	expect(t, r, http.StatusOK, "")
+	cleared := decode[model.Transaction](t, r)
+	if cleared.Title != "Market" || cleared.Description != nil || cleared.CategoryID != nil || len(cleared.Tags) != 0 {
+		t.Fatalf("patching with nulls left %+v", cleared)
+	}
+
+	// Required fields cannot be removed.
+	for _, field := range []string{"type", "title", "amount", "currency", "occurred_at"} {
+		r = s.do(http.MethodPatch, path, map[string]any{field: nil}, "If-Match", `"3"`)
+		expect(t, r, http.StatusUnprocessableEntity, handler.CodeValidationFailed)
+		if len(r.Errors) != 1 || r.Errors[0].Field != field {
+			t.Fatalf("null %s answered errors %+v", field, r.Errors)
+		}
+	}
+}
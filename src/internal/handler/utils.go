@@ -2,13 +2,47 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/joaoleau/muquirango/internal/auth"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/validation"
+)
+
+// maxBodyBytes caps request bodies. Nothing the API accepts comes close.
+const maxBodyBytes = 1 << 20
+
+var (
+	errMalformedBody   = errors.New("malformed request body")
+	errUnauthenticated = errors.New("missing caller identity")
+	errVersionRequired = errors.New("the version being changed is required, send it in If-Match or as version")
+)
+
+// Error codes let clients branch on a failure without parsing the message.
+// They are part of the API and must not change.
+const (
+	CodeMalformedBody    = "malformed_body"
+	CodeUnauthenticated  = "unauthenticated"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeVersionRequired  = "version_required"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeValidationFailed = "validation_failed"
+	CodeUnavailable      = "unavailable"
+	CodeInternal         = "internal"
 )
 
 type ResponseBody struct {
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	Data       interface{}       `json:"data,omitempty"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Code       string            `json:"code,omitempty"`
+	Errors     validation.Errors `json:"errors,omitempty"`
 }
 
 func response(w http.ResponseWriter, status int, body ResponseBody) {
@@ -17,12 +51,61 @@ func response(w http.ResponseWriter, status int, body ResponseBody) {
 	json.NewEncoder(w).Encode(body)
 }
 
-func ResponseWithError(w http.ResponseWriter, status int, err error) {
+// ResponseWithError answers with the status and code err maps to. It is the
+// only place that decides how a failure looks on the wire.
+func ResponseWithError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+
+	body := ResponseBody{
+		Error: err.Error(),
+		Code:  code,
+	}
+	var invalid validation.Errors
+	if errors.As(err, &invalid) {
+		body.Error = "request body failed validation"
+		body.Errors = invalid
+	}
+	response(w, status, body)
+}
+
+// ResponseWithErrorData is ResponseWithError carrying data as well, such as
+// the stored state a conflicting write lost against.
+func ResponseWithErrorData(w http.ResponseWriter, err error, data interface{}) {
+	status, code := errorStatus(err)
 	response(w, status, ResponseBody{
-		Error:   err.Error(),
+		Error: err.Error(),
+		Code:  code,
+		Data:  data,
 	})
 }
 
+func errorStatus(err error) (int, string) {
+	var (
+		invalid  validation.Errors
+		tooLarge *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &invalid), errors.Is(err, repository.ErrValidation):
+		return http.StatusUnprocessableEntity, CodeValidationFailed
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge, CodePayloadTooLarge
+	case errors.Is(err, errMalformedBody):
+		return http.StatusBadRequest, CodeMalformedBody
+	case errors.Is(err, errUnauthenticated):
+		return http.StatusUnauthorized, CodeUnauthenticated
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound, CodeNotFound
+	case errors.Is(err, repository.ErrConflict):
+		return http.StatusConflict, CodeConflict
+	case errors.Is(err, errVersionRequired):
+		return http.StatusPreconditionRequired, CodeVersionRequired
+	case errors.Is(err, repository.ErrUnavailable):
+		return http.StatusServiceUnavailable, CodeUnavailable
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
+
 func ResponseWithMessage(w http.ResponseWriter, status int, message string) {
 	response(w, status, ResponseBody{
 		Message: message,
@@ -31,7 +114,51 @@ func ResponseWithMessage(w http.ResponseWriter, status int, message string) {
 
 func ResponseWithData(w http.ResponseWriter, status int, data interface{}) {
 	response(w, status, ResponseBody{
-		Data:    data,
+		Data: data,
+	})
+}
+
+// requestError is a problem the handler found on its own. It reads as
+// message and matches kind with errors.Is, so ResponseWithError maps it like
+// the repository errors.
+type requestError struct {
+	kind    error
+	message string
+}
+
+func (e *requestError) Error() string { return e.message }
+
+func (e *requestError) Unwrap() error { return e.kind }
+
+// invalidf reports a request the client has to change before retrying.
+func invalidf(format string, args ...any) error {
+	return &requestError{kind: repository.ErrValidation, message: fmt.Sprintf(format, args...)}
+}
+
+// conflictf reports a request the stored state does not allow right now.
+func conflictf(format string, args ...any) error {
+	return &requestError{kind: repository.ErrConflict, message: fmt.Sprintf(format, args...)}
+}
+
+// reference turns the lookup of an item named by field in the request body
+// into a field error when it is missing, so a dangling ID answers 422 rather
+// than 404.
+func reference(field string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return validation.Errors{{Field: field, Message: "does not exist"}}
+	}
+	return err
+}
+
+// ResponseNoContent answers 204 for a request with nothing to return.
+func ResponseNoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func ResponseWithPage(w http.ResponseWriter, status int, data interface{}, nextCursor string) {
+	response(w, status, ResponseBody{
+		Data:       data,
+		NextCursor: nextCursor,
 	})
 }
 
@@ -45,11 +172,70 @@ func Serialize[T any](obj T) ([]byte, error) {
 	return body, nil
 }
 
+// Deserialize decodes a single JSON value of at most maxBodyBytes into T,
+// rejecting fields T does not declare, then runs T's Validate method when it
+// has one.
 func Deserialize[T any](r *http.Request) (*T, error) {
 	var t T
 	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
-		return nil, err
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&t); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", errMalformedBody, err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: the body must hold a single JSON value", errMalformedBody)
+	}
+
+	if validator, ok := any(&t).(validation.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
 	}
 	return &t, nil
-}
\ No newline at end of file
+}
+
+// requestVersion returns the version a write is based on: the If-Match
+// header, else the version field of the body, else the version query
+// parameter. Writes without one are refused, so a client holding a stale
+// copy cannot silently overwrite a newer edit.
+func requestVersion(r *http.Request, bodyVersion *int) (int, error) {
+	value := strings.Trim(strings.TrimPrefix(r.Header.Get("If-Match"), "W/"), `"`)
+	switch {
+	case value != "":
+	case bodyVersion != nil:
+		return *bodyVersion, nil
+	default:
+		value = r.URL.Query().Get("version")
+	}
+	if value == "" {
+		return 0, errVersionRequired
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 0 {
+		return 0, invalidf("version must be a non-negative integer, got '%s'", value)
+	}
+	return version, nil
+}
+
+// setETag exposes version as the entity tag clients send back in If-Match.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// requestOwner returns the ledger owner resolved by the auth middleware,
+// answering 401 when the request carries no identity.
+func requestOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	owner, ok := auth.OwnerFromContext(r.Context())
+	if !ok {
+		ResponseWithError(w, errUnauthenticated)
+		return "", false
+	}
+	return owner, true
+}
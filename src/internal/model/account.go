@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type AccountKind string
+
+const (
+	AccountKindChecking   AccountKind = "CHECKING"
+	AccountKindSavings    AccountKind = "SAVINGS"
+	AccountKindCash       AccountKind = "CASH"
+	AccountKindCreditCard AccountKind = "CREDIT_CARD"
+	AccountKindInvestment AccountKind = "INVESTMENT"
+)
+
+// Account is where money sits: a bank account, a wallet, a card. Its
+// balance on any date is OpeningBalance plus the effect of every
+// transaction filed under it up to that date. Credit cards also carry the
+// day their statement closes and the day it is due.
+type Account struct {
+	PK             string      `json:"-" dynamodbav:"PK"`
+	SK             string      `json:"-" dynamodbav:"SK"`
+	OwnerID        string      `json:"-" dynamodbav:"owner_id"`
+	ID             string      `json:"id" dynamodbav:"id"`
+	Name           string      `json:"name" dynamodbav:"name"`
+	Kind           AccountKind `json:"kind" dynamodbav:"kind"`
+	Currency       string      `json:"currency" dynamodbav:"currency"`
+	OpeningBalance int         `json:"opening_balance" dynamodbav:"opening_balance"`
+	ClosingDay     int         `json:"closing_day,omitempty" dynamodbav:"closing_day,omitempty"`
+	DueDay         int         `json:"due_day,omitempty" dynamodbav:"due_day,omitempty"`
+	CreatedAt      time.Time   `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// AccountBalance is the balance of an account at the end of Date.
+type AccountBalance struct {
+	AccountID string `json:"account_id"`
+	Date      string `json:"date"`
+	Currency  string `json:"currency"`
+	Balance   int    `json:"balance"`
+}
+
+// StatementEntry is a transaction with the account balance right after it.
+type StatementEntry struct {
+	Transaction Transaction `json:"transaction"`
+	Balance     int         `json:"balance"`
+}
+
+// AccountStatement lists the transactions of an account between two dates
+// with a running balance.
+type AccountStatement struct {
+	AccountID      string           `json:"account_id"`
+	StartDate      string           `json:"start_date"`
+	EndDate        string           `json:"end_date"`
+	Currency       string           `json:"currency"`
+	OpeningBalance int              `json:"opening_balance"`
+	ClosingBalance int              `json:"closing_balance"`
+	Entries        []StatementEntry `json:"entries"`
+}
+
+func (a *Account) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: a.PK},
+		"SK": &types.AttributeValueMemberS{Value: a.SK},
+	}
+}
+
+func (a *Account) SetKeys() {
+	a.PK = OwnerKey(a.OwnerID)
+	a.SK = AccountKey(a.ID)
+}
+
+// AccountKey is the sort key of an account inside its owner's partition.
+func AccountKey(id string) string {
+	return fmt.Sprintf("ACCOUNT#%s", id)
+}
+
+// BalanceEffect is how much a transaction changes the balance of its
+// account: income and incoming transfers add, everything else takes money
+// out.
+func (t *Transaction) BalanceEffect() int {
+	if t.Type == TransactionTypeIncome || t.Type == TransactionTypeTransfer && t.Direction == TransferIn {
+		return t.Amount
+	}
+	return -t.Amount
+}
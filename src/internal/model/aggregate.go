@@ -0,0 +1,122 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// AggregatePrefix starts the sort key of every monthly aggregate.
+const AggregatePrefix = "AGGREGATE#"
+
+// MonthlyAggregate sums the income, purchases and investments of an owner
+// in one month and currency, and counts the transactions behind each sum.
+// It is kept up to date as transactions are written so reports need not
+// read them all. Transfers are left out. Currency is blank for amounts
+// stored without one.
+type MonthlyAggregate struct {
+	PK              string `json:"-" dynamodbav:"PK"`
+	SK              string `json:"-" dynamodbav:"SK"`
+	OwnerID         string `json:"-" dynamodbav:"owner_id"`
+	Month           string `json:"month" dynamodbav:"month"`
+	Currency        string `json:"currency" dynamodbav:"currency"`
+	Income          int    `json:"income" dynamodbav:"income"`
+	IncomeCount     int    `json:"income_count" dynamodbav:"income_count"`
+	Purchases       int    `json:"purchases" dynamodbav:"purchases"`
+	PurchaseCount   int    `json:"purchase_count" dynamodbav:"purchase_count"`
+	Investments     int    `json:"investments" dynamodbav:"investments"`
+	InvestmentCount int    `json:"investment_count" dynamodbav:"investment_count"`
+}
+
+func (a *MonthlyAggregate) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: a.PK},
+		"SK": &types.AttributeValueMemberS{Value: a.SK},
+	}
+}
+
+func (a *MonthlyAggregate) SetKeys() {
+	a.PK = OwnerKey(a.OwnerID)
+	a.SK = AggregateKey(a.Month, a.Currency)
+}
+
+// AggregateKey is the sort key of the aggregate of a "2006-01" month and a
+// currency inside its owner's partition.
+func AggregateKey(month string, currency string) string {
+	return fmt.Sprintf("%s%s#%s", AggregatePrefix, month, currency)
+}
+
+// Empty reports whether the aggregate sums and counts nothing.
+func (a *MonthlyAggregate) Empty() bool {
+	return a.Income == 0 && a.IncomeCount == 0 &&
+		a.Purchases == 0 && a.PurchaseCount == 0 &&
+		a.Investments == 0 && a.InvestmentCount == 0
+}
+
+// add counts transaction, or takes it out when sign is -1.
+func (a *MonthlyAggregate) add(transaction Transaction, sign int) {
+	switch transaction.Type {
+	case TransactionTypeIncome:
+		a.Income += sign * transaction.Amount
+		a.IncomeCount += sign
+	case TransactionTypePurchase:
+		a.Purchases += sign * transaction.Amount
+		a.PurchaseCount += sign
+	case TransactionTypeInvestment:
+		a.Investments += sign * transaction.Amount
+		a.InvestmentCount += sign
+	}
+}
+
+// Aggregates accumulates the monthly aggregates of one owner.
+type Aggregates map[string]*MonthlyAggregate
+
+// Add counts transaction in the aggregate of its month and currency, or
+// takes it out when sign is -1. Transfers are left out.
+func (a Aggregates) Add(transaction Transaction, sign int) {
+	if transaction.Type == TransactionTypeTransfer {
+		return
+	}
+
+	month := transaction.OccurredAt.Format(MonthLayout)
+	key := AggregateKey(month, transaction.Currency)
+	aggregate, ok := a[key]
+	if !ok {
+		aggregate = &MonthlyAggregate{
+			OwnerID:  transaction.OwnerID,
+			Month:    month,
+			Currency: transaction.Currency,
+		}
+		aggregate.SetKeys()
+		a[key] = aggregate
+	}
+	aggregate.add(transaction, sign)
+}
+
+// List returns the aggregates that are not empty, ordered by month and
+// currency.
+func (a Aggregates) List() []MonthlyAggregate {
+	list := make([]MonthlyAggregate, 0, len(a))
+	for _, aggregate := range a {
+		if !aggregate.Empty() {
+			list = append(list, *aggregate)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SK < list[j].SK })
+	return list
+}
+
+// AggregateChanges returns what replacing before with after adds to each
+// aggregate, leaving out aggregates that do not change. Either may be nil,
+// for a transaction created or deleted.
+func AggregateChanges(before *Transaction, after *Transaction) []MonthlyAggregate {
+	changes := Aggregates{}
+	if before != nil {
+		changes.Add(*before, -1)
+	}
+	if after != nil {
+		changes.Add(*after, 1)
+	}
+	return changes.List()
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MonthLayout is the format of month references such as "2026-10".
+const MonthLayout = "2006-01"
+
+// Budget caps the spending of a category or a transaction type per month,
+// from StartMonth through EndMonth (open-ended when nil). With Rollover,
+// the unspent part of each month is added to the next month's limit.
+type Budget struct {
+	PK         string           `json:"-" dynamodbav:"PK"`
+	SK         string           `json:"-" dynamodbav:"SK"`
+	OwnerID    string           `json:"-" dynamodbav:"owner_id"`
+	ID         string           `json:"id" dynamodbav:"id"`
+	Name       string           `json:"name" dynamodbav:"name"`
+	CategoryID *string          `json:"category_id,omitempty" dynamodbav:"category_id,omitempty"`
+	Type       *TransactionType `json:"type,omitempty" dynamodbav:"type,omitempty"`
+	Limit      int              `json:"limit" dynamodbav:"limit"`
+	StartMonth string           `json:"start_month" dynamodbav:"start_month"`
+	EndMonth   *string          `json:"end_month,omitempty" dynamodbav:"end_month,omitempty"`
+	Rollover   bool             `json:"rollover" dynamodbav:"rollover"`
+	CreatedAt  time.Time        `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// BudgetStatus is the state of a budget in a single month. Available is the
+// limit plus whatever rolled over from previous months. Amounts are in
+// Currency, the base currency.
+type BudgetStatus struct {
+	BudgetID   string  `json:"budget_id"`
+	Month      string  `json:"month"`
+	Currency   string  `json:"currency"`
+	Limit      int     `json:"limit"`
+	RolledOver int     `json:"rolled_over"`
+	Available  int     `json:"available"`
+	Spent      int     `json:"spent"`
+	Remaining  int     `json:"remaining"`
+	Percentage float64 `json:"percentage"`
+}
+
+func (b *Budget) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: b.PK},
+		"SK": &types.AttributeValueMemberS{Value: b.SK},
+	}
+}
+
+func (b *Budget) SetKeys() {
+	b.PK = OwnerKey(b.OwnerID)
+	b.SK = BudgetKey(b.ID)
+}
+
+// BudgetKey is the sort key of a budget inside its owner's partition.
+func BudgetKey(id string) string {
+	return fmt.Sprintf("BUDGET#%s", id)
+}
+
+// ParseMonth parses a "2006-01" month reference.
+func ParseMonth(value string) (time.Time, error) {
+	month, err := time.Parse(MonthLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month '%s', expected YYYY-MM", value)
+	}
+	return month, nil
+}
+
+// Covers reports whether month falls inside the budget period.
+func (b *Budget) Covers(month string) bool {
+	if month < b.StartMonth {
+		return false
+	}
+	return b.EndMonth == nil || month <= *b.EndMonth
+}
+
+// Counts reports whether a transaction is charged against the budget.
+// Category budgets count purchases filed under any of categoryIDs, which
+// should hold the budget category and its descendants.
+func (b *Budget) Counts(transaction Transaction, categoryIDs map[string]bool) bool {
+	if b.Type != nil {
+		return transaction.Type == *b.Type
+	}
+	return transaction.Type == TransactionTypePurchase &&
+		transaction.CategoryID != nil &&
+		categoryIDs[*transaction.CategoryID]
+}
+
+// Status computes the budget state for month from the amounts spent in each
+// month since StartMonth, keyed by "2006-01".
+func (b *Budget) Status(month string, spentByMonth map[string]int) BudgetStatus {
+	rolledOver := 0
+	if b.Rollover {
+		start, _ := ParseMonth(b.StartMonth)
+		for m := start; m.Format(MonthLayout) < month; m = m.AddDate(0, 1, 0) {
+			rolledOver = max(0, b.Limit+rolledOver-spentByMonth[m.Format(MonthLayout)])
+		}
+	}
+
+	status := BudgetStatus{
+		BudgetID:   b.ID,
+		Month:      month,
+		Limit:      b.Limit,
+		RolledOver: rolledOver,
+		Available:  b.Limit + rolledOver,
+		Spent:      spentByMonth[month],
+	}
+	status.Remaining = status.Available - status.Spent
+	if status.Available > 0 {
+		status.Percentage = math.Round(float64(status.Spent)/float64(status.Available)*10000) / 100
+	}
+	return status
+}
@@ -0,0 +1,49 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+func TestBudgetStatusRollover(t *testing.T) {
+	budget := model.Budget{ID: "groceries", Limit: 1000, StartMonth: "2026-10", Rollover: true}
+	spent := map[string]int{"2026-10": 400, "2026-11": 1500, "2026-12": 200}
+
+	cases := []struct {
+		month      string
+		rolledOver int
+		remaining  int
+	}{
+		{"2026-10", 0, 600},
+		{"2026-11", 600, 100},
+		{"2026-12", 100, 900},
+		{"2027-01", 900, 1900},
+	}
+	for _, c := range cases {
+		status := budget.Status(c.month, spent)
+		if status.RolledOver != c.rolledOver || status.Available != budget.Limit+c.rolledOver || status.Remaining != c.remaining {
+			t.Fatalf("%s: status %+v, want %d rolled over and %d remaining", c.month, status, c.rolledOver, c.remaining)
+		}
+	}
+}
+
+func TestBudgetStatusRolloverNeverCarriesOverspending(t *testing.T) {
+	budget := model.Budget{Limit: 1000, StartMonth: "2026-10", Rollover: true}
+	spent := map[string]int{"2026-10": 2500}
+
+	status := budget.Status("2026-11", spent)
+	if status.RolledOver != 0 || status.Available != 1000 {
+		t.Fatalf("overspent October left November with %+v", status)
+	}
+}
+
+func TestBudgetStatusWithoutRollover(t *testing.T) {
+	budget := model.Budget{Limit: 1000, StartMonth: "2026-10"}
+	spent := map[string]int{"2026-10": 100, "2026-11": 250}
+
+	status := budget.Status("2026-11", spent)
+	if status.RolledOver != 0 || status.Available != 1000 || status.Spent != 250 || status.Remaining != 750 || status.Percentage != 25 {
+		t.Fatalf("November status %+v", status)
+	}
+}
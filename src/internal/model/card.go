@@ -0,0 +1,101 @@
+package model
+
+import "time"
+
+type StatementStatus string
+
+const (
+	StatementStatusOpen   StatementStatus = "OPEN"
+	StatementStatusClosed StatementStatus = "CLOSED"
+	StatementStatusPaid   StatementStatus = "PAID"
+)
+
+// CardStatement is the bill of a credit card for one month: every
+// transaction dated after the previous closing day up to and including
+// this month's closing day. Total is what was charged in the cycle and
+// Paid how much of it payments and refunds have covered so far.
+type CardStatement struct {
+	AccountID    string          `json:"account_id"`
+	Month        string          `json:"month"`
+	StartDate    string          `json:"start_date"`
+	ClosingDate  string          `json:"closing_date"`
+	DueDate      string          `json:"due_date"`
+	Currency     string          `json:"currency"`
+	Status       StatementStatus `json:"status"`
+	Total        int             `json:"total"`
+	Paid         int             `json:"paid"`
+	Remaining    int             `json:"remaining"`
+	Transactions []Transaction   `json:"transactions"`
+}
+
+// Cycle returns the first day, closing day and due day of the statement
+// that closes in month. Days past the end of a month fall on its last day,
+// and a due day on or before the closing day falls in the following month.
+func (a *Account) Cycle(month time.Time) (time.Time, time.Time, time.Time) {
+	closing := dayOfMonth(month, a.ClosingDay)
+	start := dayOfMonth(AddMonths(month, -1), a.ClosingDay).AddDate(0, 0, 1)
+
+	due := dayOfMonth(month, a.DueDay)
+	if a.DueDay <= a.ClosingDay {
+		due = dayOfMonth(AddMonths(month, 1), a.DueDay)
+	}
+	return start, closing, due
+}
+
+// NewCardStatement builds the statement of card closing in month from the
+// card's transactions up to at least the closing date, as seen on today.
+//
+// Credits settle the oldest charges first: payments and refunds dated up to
+// today pay off the opening debt and earlier cycles before this one.
+func NewCardStatement(card *Account, month time.Time, transactions []Transaction, today string) *CardStatement {
+	start, closing, due := card.Cycle(month)
+	statement := &CardStatement{
+		AccountID:    card.ID,
+		Month:        month.Format(MonthLayout),
+		StartDate:    start.Format("2006-01-02"),
+		ClosingDate:  closing.Format("2006-01-02"),
+		DueDate:      due.Format("2006-01-02"),
+		Currency:     card.Currency,
+		Transactions: []Transaction{},
+	}
+
+	chargedBefore := max(-card.OpeningBalance, 0)
+	credited := max(card.OpeningBalance, 0)
+	for _, transaction := range transactions {
+		date := transaction.OccurredAt.Format("2006-01-02")
+		if date >= statement.StartDate && date <= statement.ClosingDate {
+			statement.Transactions = append(statement.Transactions, transaction)
+		}
+
+		effect := transaction.BalanceEffect()
+		switch {
+		case effect > 0 && date <= today:
+			credited += effect
+		case effect < 0 && date < statement.StartDate:
+			chargedBefore -= effect
+		case effect < 0 && date <= statement.ClosingDate:
+			statement.Total -= effect
+		}
+	}
+
+	statement.Paid = min(max(credited-chargedBefore, 0), statement.Total)
+	statement.Remaining = statement.Total - statement.Paid
+
+	switch {
+	case today <= statement.ClosingDate:
+		statement.Status = StatementStatusOpen
+	case statement.Remaining == 0:
+		statement.Status = StatementStatusPaid
+	default:
+		statement.Status = StatementStatusClosed
+	}
+	return statement
+}
+
+// dayOfMonth returns day of the month of t, or the last day of that month
+// when it is shorter.
+func dayOfMonth(t time.Time, day int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
@@ -0,0 +1,58 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+func purchase(id string, amount int, date string) model.Transaction {
+	occurredAt, _ := time.Parse("2006-01-02", date)
+	return model.Transaction{ID: id, Type: model.TransactionTypePurchase, Money: model.Money{Amount: amount}, OccurredAt: occurredAt}
+}
+
+func month(value string) time.Time {
+	parsed, _ := time.Parse(model.MonthLayout, value)
+	return parsed
+}
+
+func TestPurchaseAfterClosingDayGoesOnNextStatement(t *testing.T) {
+	card := &model.Account{ID: "card", Kind: model.AccountKindCreditCard, ClosingDay: 25, DueDay: 5}
+	transactions := []model.Transaction{
+		purchase("closing-day", 1000, "2026-03-25"),
+		purchase("after-closing", 2000, "2026-03-28"),
+	}
+
+	march := model.NewCardStatement(card, month("2026-03"), transactions, "2026-03-28")
+	if march.ClosingDate != "2026-03-25" || march.DueDate != "2026-04-05" || march.Total != 1000 {
+		t.Fatalf("March statement %+v", march)
+	}
+	if len(march.Transactions) != 1 || march.Transactions[0].ID != "closing-day" {
+		t.Fatalf("March statement holds %v, want only the purchase on the closing day", march.Transactions)
+	}
+
+	april := model.NewCardStatement(card, month("2026-04"), transactions, "2026-03-28")
+	if april.StartDate != "2026-03-26" || april.ClosingDate != "2026-04-25" || april.DueDate != "2026-05-05" || april.Total != 2000 {
+		t.Fatalf("April statement %+v", april)
+	}
+	if len(april.Transactions) != 1 || april.Transactions[0].ID != "after-closing" {
+		t.Fatalf("April statement holds %v, want the purchase on the 28th", april.Transactions)
+	}
+	if april.Status != model.StatementStatusOpen {
+		t.Fatalf("April statement is %s, want OPEN", april.Status)
+	}
+}
+
+func TestCycleClampsClosingDayToShortMonths(t *testing.T) {
+	card := &model.Account{ClosingDay: 30, DueDay: 10}
+
+	start, closing, due := card.Cycle(month("2026-02"))
+	if start.Format("2006-01-02") != "2026-01-31" || closing.Format("2006-01-02") != "2026-02-28" || due.Format("2006-01-02") != "2026-03-10" {
+		t.Fatalf("February cycle runs %s to %s, due %s", start, closing, due)
+	}
+	start, _, _ = card.Cycle(month("2026-03"))
+	if start.Format("2006-01-02") != "2026-03-01" {
+		t.Fatalf("March cycle starts on %s, want the day after February closed", start)
+	}
+}
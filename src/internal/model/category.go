@@ -0,0 +1,38 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type Category struct {
+	PK        string    `json:"-" dynamodbav:"PK"`
+	SK        string    `json:"-" dynamodbav:"SK"`
+	OwnerID   string    `json:"-" dynamodbav:"owner_id"`
+	ID        string    `json:"id" dynamodbav:"id"`
+	Name      string    `json:"name" dynamodbav:"name"`
+	Color     *string   `json:"color,omitempty" dynamodbav:"color,omitempty"`
+	Icon      *string   `json:"icon,omitempty" dynamodbav:"icon,omitempty"`
+	ParentID  *string   `json:"parent_id,omitempty" dynamodbav:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+func (c *Category) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: c.PK},
+		"SK": &types.AttributeValueMemberS{Value: c.SK},
+	}
+}
+
+func (c *Category) SetKeys() {
+	c.PK = OwnerKey(c.OwnerID)
+	c.SK = CategoryKey(c.ID)
+}
+
+// CategoryKey is the sort key of a category inside its owner's partition.
+func CategoryKey(id string) string {
+	return fmt.Sprintf("CATEGORY#%s", id)
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ExchangeRatePrefix starts the sort key of every exchange rate.
+const ExchangeRatePrefix = "RATE#"
+
+// ExchangeRate is what one unit of Base was worth in Quote on Date, a day
+// like 2026-01-31. Rate is kept as the decimal it was entered with, like
+// 5.4321, so no precision is lost on the way through storage. Rates are
+// entered by hand or imported; nothing is fetched from outside.
+type ExchangeRate struct {
+	PK        string    `json:"-" dynamodbav:"PK"`
+	SK        string    `json:"-" dynamodbav:"SK"`
+	OwnerID   string    `json:"-" dynamodbav:"owner_id"`
+	Base      string    `json:"base" dynamodbav:"base"`
+	Quote     string    `json:"quote" dynamodbav:"quote"`
+	Date      string    `json:"date" dynamodbav:"date"`
+	Rate      string    `json:"rate" dynamodbav:"rate"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+func (e *ExchangeRate) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: e.PK},
+		"SK": &types.AttributeValueMemberS{Value: e.SK},
+	}
+}
+
+func (e *ExchangeRate) SetKeys() {
+	e.PK = OwnerKey(e.OwnerID)
+	e.SK = ExchangeRateKey(e.Base, e.Quote, e.Date)
+}
+
+// ExchangeRateKey is the sort key of the rate of a currency pair on date.
+// Rates of a pair sort by date.
+func ExchangeRateKey(base string, quote string, date string) string {
+	return fmt.Sprintf("%s%s#%s#%s", ExchangeRatePrefix, base, quote, date)
+}
+
+// Converter converts money between currencies with a set of exchange
+// rates. On each date it uses the latest rate of the pair dated on or
+// before it.
+type Converter struct {
+	rates map[[2]string][]datedRate
+}
+
+type datedRate struct {
+	date string
+	rate *big.Rat
+}
+
+// NewConverter indexes rates by currency pair. Rates that do not parse are
+// left out.
+func NewConverter(rates []ExchangeRate) *Converter {
+	converter := &Converter{rates: map[[2]string][]datedRate{}}
+	for _, rate := range rates {
+		value, ok := ParseRate(rate.Rate)
+		if !ok {
+			continue
+		}
+		pair := [2]string{rate.Base, rate.Quote}
+		converter.rates[pair] = append(converter.rates[pair], datedRate{date: rate.Date, rate: value})
+	}
+	for _, dated := range converter.rates {
+		sort.Slice(dated, func(i, j int) bool { return dated[i].date < dated[j].date })
+	}
+	return converter
+}
+
+// Convert returns money in the currency to as of date, rounded half away
+// from zero to the minor unit of to. A pair without a rate of its own is
+// converted with the inverse of the opposite pair. ok is false when
+// neither pair has a rate on or before date.
+func (c *Converter) Convert(money Money, to string, date string) (amount int, ok bool) {
+	if money.Currency == to {
+		return money.Amount, true
+	}
+
+	rate, ok := c.rate(money.Currency, to, date)
+	if !ok {
+		inverse, found := c.rate(to, money.Currency, date)
+		if !found {
+			return 0, false
+		}
+		rate = new(big.Rat).Inv(inverse)
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(money.Amount)), rate)
+	shift := MinorUnits(to) - MinorUnits(money.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(shift, -shift))), nil))
+	if shift >= 0 {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+	return roundRat(value), true
+}
+
+func (c *Converter) rate(base string, quote string, date string) (*big.Rat, bool) {
+	dated := c.rates[[2]string{base, quote}]
+	i := sort.Search(len(dated), func(i int) bool { return dated[i].date > date })
+	if i == 0 {
+		return nil, false
+	}
+	return dated[i-1].rate, true
+}
+
+// roundRat rounds value to the nearest integer, halves away from zero.
+func roundRat(value *big.Rat) int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	return int(quotient.Int64())
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// IdempotencyRecord remembers the response to a create sent with an
+// Idempotency-Key header, so a retry is answered the same way instead of
+// recording the transaction twice. It is stored in the same write as what it
+// created and expires at ExpiresAt, a Unix time DynamoDB's TTL deletes the
+// item after.
+type IdempotencyRecord struct {
+	PK          string    `dynamodbav:"PK"`
+	SK          string    `dynamodbav:"SK"`
+	OwnerID     string    `dynamodbav:"owner_id"`
+	Key         string    `dynamodbav:"idempotency_key"`
+	Fingerprint string    `dynamodbav:"fingerprint"`
+	Status      int       `dynamodbav:"status"`
+	Response    string    `dynamodbav:"response"`
+	CreatedAt   time.Time `dynamodbav:"created_at"`
+	ExpiresAt   int64     `dynamodbav:"expires_at"`
+}
+
+func (r *IdempotencyRecord) SetKeys() {
+	r.PK = OwnerKey(r.OwnerID)
+	r.SK = IdempotencyKey(r.Key)
+}
+
+func (r *IdempotencyRecord) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: r.PK},
+		"SK": &types.AttributeValueMemberS{Value: r.SK},
+	}
+}
+
+// IdempotencyKey is the sort key of the record kept for key.
+func IdempotencyKey(key string) string {
+	return fmt.Sprintf("IDEMPOTENCY#%s", key)
+}
+
+// Expired reports whether the record no longer applies at now, even if it
+// has not been deleted yet.
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return now.Unix() >= r.ExpiresAt
+}
+
+// SetResponse stores data, the item the request created, as the response
+// body to replay.
+func (r *IdempotencyRecord) SetResponse(data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	r.Response = string(body)
+	return nil
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// InstallmentPlan is the parent of a purchase split into monthly
+// installments ("parcelamento"). It carries the total amount and is not a
+// transaction itself, so listings and totals only ever see the
+// installments, each one a PURCHASE dated a month after the previous one.
+type InstallmentPlan struct {
+	PK          string  `json:"-" dynamodbav:"PK"`
+	SK          string  `json:"-" dynamodbav:"SK"`
+	OwnerID     string  `json:"-" dynamodbav:"owner_id"`
+	ID          string  `json:"id" dynamodbav:"id"`
+	Title       string  `json:"title" dynamodbav:"title"`
+	Description *string `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Money
+	Installments int        `json:"installments" dynamodbav:"installments"`
+	CategoryID   *string    `json:"category_id,omitempty" dynamodbav:"category_id,omitempty"`
+	AccountID    *string    `json:"account_id,omitempty" dynamodbav:"account_id,omitempty"`
+	Tags         []string   `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" dynamodbav:"cancelled_at,omitempty"`
+	OccurredAt   time.Time  `json:"occurred_at" dynamodbav:"occurred_at"`
+	CreatedAt    time.Time  `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" dynamodbav:"updated_at"`
+
+	// Transactions holds the installments still stored, when loaded.
+	Transactions []Transaction `json:"transactions,omitempty" dynamodbav:"-"`
+}
+
+func (p *InstallmentPlan) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: p.PK},
+		"SK": &types.AttributeValueMemberS{Value: p.SK},
+	}
+}
+
+// UnmarshalDynamoDBAttributeValue reads plans stored before OccurredAt
+// existed as purchased when they were created.
+func (p *InstallmentPlan) UnmarshalDynamoDBAttributeValue(value types.AttributeValue) error {
+	type stored InstallmentPlan
+	if err := attributevalue.Unmarshal(value, (*stored)(p)); err != nil {
+		return err
+	}
+	if p.OccurredAt.IsZero() {
+		p.OccurredAt = p.CreatedAt
+	}
+	return nil
+}
+
+func (p *InstallmentPlan) SetKeys() {
+	p.PK = OwnerKey(p.OwnerID)
+	p.SK = InstallmentPlanKey(p.ID)
+}
+
+// InstallmentPlanKey is the sort key of an installment plan inside its
+// owner's partition.
+func InstallmentPlanKey(id string) string {
+	return fmt.Sprintf("INSTALLMENT#%s", id)
+}
+
+// Split builds the installments of the plan. The first one is dated at the
+// purchase and each following one a month later, on the same day or the
+// last day of shorter months. Cents that do not divide evenly go to the
+// first installments, so the amounts always add up to the total.
+func (p *InstallmentPlan) Split() []Transaction {
+	amounts := SplitAmount(p.Amount, p.Installments)
+	parentID := p.ID
+
+	installments := make([]Transaction, p.Installments)
+	for i := range installments {
+		installment := Transaction{
+			OwnerID:      p.OwnerID,
+			ID:           InstallmentID(p.ID, i+1),
+			Type:         TransactionTypePurchase,
+			Title:        p.Title,
+			Description:  p.Description,
+			Money:        Money{Amount: amounts[i], Currency: p.Currency},
+			CategoryID:   p.CategoryID,
+			AccountID:    p.AccountID,
+			Tags:         append([]string(nil), p.Tags...),
+			ParentID:     &parentID,
+			Installment:  i + 1,
+			Installments: p.Installments,
+			OccurredAt:   AddMonths(p.OccurredAt, i),
+			CreatedAt:    p.CreatedAt,
+			UpdatedAt:    p.UpdatedAt,
+		}
+		installment.SetKeys()
+		installments[i] = installment
+	}
+	return installments
+}
+
+// ApplyTo copies the descriptive fields of the plan onto one of its
+// installments. Amounts and dates are fixed at creation.
+func (p *InstallmentPlan) ApplyTo(transaction *Transaction) {
+	transaction.Title = p.Title
+	transaction.Description = p.Description
+	transaction.CategoryID = p.CategoryID
+	transaction.Tags = append([]string(nil), p.Tags...)
+}
+
+// InstallmentID is the deterministic ID of the n-th installment of a plan,
+// counting from 1.
+func InstallmentID(planID string, n int) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s#%d", planID, n))).String()
+}
+
+// SplitAmount divides total into n parts that differ by at most one cent,
+// the larger ones first.
+func SplitAmount(total int, n int) []int {
+	parts := make([]int, n)
+	for i := range parts {
+		parts[i] = total / n
+		if i < total%n {
+			parts[i]++
+		}
+	}
+	return parts
+}
+
+// AddMonths moves t by months, keeping the day of month where it exists and
+// using the last day of the target month otherwise, so Jan 31 plus one
+// month is Feb 28 rather than Mar 3.
+func AddMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Money is an amount in the minor unit of its currency, cents for BRL, and
+// the ISO 4217 code of that currency. Currency is blank on items stored
+// before amounts carried one; reports take those as the base currency.
+type Money struct {
+	Amount   int    `json:"amount" dynamodbav:"amount"`
+	Currency string `json:"currency,omitempty" dynamodbav:"currency,omitempty"`
+}
+
+// minorUnits lists the currencies whose minor unit is not the hundredth.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits returns how many decimal places currency has, 2 for most.
+func MinorUnits(currency string) int {
+	if units, ok := minorUnits[currency]; ok {
+		return units
+	}
+	return 2
+}
+
+// NormalizeCurrency trims and upper-cases an ISO 4217 code.
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// ParseRate reads an exchange rate written as a positive decimal like
+// 5.4321.
+func ParseRate(value string) (*big.Rat, bool) {
+	if value == "" || strings.ContainsAny(value, "/eE+-") {
+		return nil, false
+	}
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, false
+	}
+	return rate, true
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Optional is one field of a partial update. Set reports whether the field
+// was sent at all; a set field with a nil Value was sent as null and clears
+// what is stored.
+type Optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		o.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+	return nil
+}
+
+// Null reports whether the field was sent as null.
+func (o Optional[T]) Null() bool {
+	return o.Set && o.Value == nil
+}
+
+// Or returns the value sent, or fallback when there is none.
+func (o Optional[T]) Or(fallback T) T {
+	if o.Value == nil {
+		return fallback
+	}
+	return *o.Value
+}
+
+// TransactionPatch is a JSON Merge Patch (RFC 7396) of a transaction: fields
+// left out keep their stored value, fields sent as null are removed and the
+// rest replace what is stored. Tags are replaced as a whole.
+type TransactionPatch struct {
+	Type        Optional[TransactionType] `json:"type"`
+	Title       Optional[string]          `json:"title"`
+	Description Optional[string]          `json:"description"`
+	Amount      Optional[int]             `json:"amount"`
+	Currency    Optional[string]          `json:"currency"`
+	CategoryID  Optional[string]          `json:"category_id"`
+	AccountID   Optional[string]          `json:"account_id"`
+	Tags        Optional[[]string]        `json:"tags"`
+	// OccurredAt is parsed from the request apart from the rest; nil keeps
+	// the stored date.
+	OccurredAt *time.Time `json:"-"`
+	UpdatedAt  time.Time  `json:"-"`
+}
+
+// ApplyTo writes the fields set in p onto transaction.
+func (p *TransactionPatch) ApplyTo(transaction *Transaction) {
+	if p.Type.Set {
+		transaction.Type = p.Type.Or("")
+	}
+	if p.Title.Set {
+		transaction.Title = p.Title.Or("")
+	}
+	if p.Description.Set {
+		transaction.Description = p.Description.Value
+	}
+	if p.Amount.Set {
+		transaction.Amount = p.Amount.Or(0)
+	}
+	if p.Currency.Set {
+		transaction.Currency = p.Currency.Or("")
+	}
+	if p.CategoryID.Set {
+		transaction.CategoryID = p.CategoryID.Value
+	}
+	if p.AccountID.Set {
+		transaction.AccountID = p.AccountID.Value
+	}
+	if p.Tags.Set {
+		transaction.Tags = append([]string(nil), p.Tags.Or(nil)...)
+	}
+	if p.OccurredAt != nil {
+		transaction.OccurredAt = *p.OccurredAt
+	}
+	transaction.UpdatedAt = p.UpdatedAt
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// DueRecurrencesKey is the GSI1 partition holding every active recurrence
+// of every owner, sorted by the date of its next occurrence, so the
+// scheduler can find due rules without scanning the table.
+const DueRecurrencesKey = "RECURRENCE#DUE"
+
+// Recurrence is a template that the scheduler materializes into a real
+// transaction on every occurrence of RRule, counting from StartDate.
+// NextOccurrence is the first date not yet materialized and is nil once the
+// rule has ended. Materialized transactions carry the rule's ID in
+// RecurrenceID. Version grows with every update, as on transactions.
+// TimeZone is the zone of the owner who saved the rule; an occurrence is due
+// once its date has begun there.
+type Recurrence struct {
+	PK             string          `json:"-" dynamodbav:"PK"`
+	SK             string          `json:"-" dynamodbav:"SK"`
+	GSI1PK         string          `json:"-" dynamodbav:"GSI1PK,omitempty"`
+	GSI1SK         string          `json:"-" dynamodbav:"GSI1SK,omitempty"`
+	OwnerID        string          `json:"-" dynamodbav:"owner_id"`
+	ID             string          `json:"id" dynamodbav:"id"`
+	RRule          string          `json:"rrule" dynamodbav:"rrule"`
+	StartDate      string          `json:"start_date" dynamodbav:"start_date"`
+	NextOccurrence *string         `json:"next_occurrence,omitempty" dynamodbav:"next_occurrence,omitempty"`
+	Type           TransactionType `json:"type" dynamodbav:"type"`
+	Title          string          `json:"title" dynamodbav:"title"`
+	Description    *string         `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Money
+	CategoryID *string   `json:"category_id,omitempty" dynamodbav:"category_id,omitempty"`
+	AccountID  *string   `json:"account_id,omitempty" dynamodbav:"account_id,omitempty"`
+	Tags       []string  `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
+	CreatedAt  time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" dynamodbav:"updated_at"`
+	Version    int       `json:"version" dynamodbav:"version"`
+	TimeZone   string    `json:"time_zone,omitempty" dynamodbav:"time_zone,omitempty"`
+}
+
+// Location returns the zone the rule is due in, or fallback for rules saved
+// before zones were recorded.
+func (r *Recurrence) Location(fallback *time.Location) *time.Location {
+	if r.TimeZone == "" {
+		return fallback
+	}
+	location, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return fallback
+	}
+	return location
+}
+
+func (r *Recurrence) GetKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: r.PK},
+		"SK": &types.AttributeValueMemberS{Value: r.SK},
+	}
+}
+
+// SetKeys fills the primary key and, while the rule is still active, the
+// GSI1 entry the scheduler reads. Ended rules drop out of the index.
+func (r *Recurrence) SetKeys() {
+	r.PK = OwnerKey(r.OwnerID)
+	r.SK = RecurrenceKey(r.ID)
+	r.GSI1PK, r.GSI1SK = "", ""
+	if r.NextOccurrence != nil {
+		r.GSI1PK = DueRecurrencesKey
+		r.GSI1SK = fmt.Sprintf("%s#%s#%s", *r.NextOccurrence, r.OwnerID, r.ID)
+	}
+}
+
+// RecurrenceKey is the sort key of a recurrence inside its owner's
+// partition.
+func RecurrenceKey(id string) string {
+	return fmt.Sprintf("RECURRENCE#%s", id)
+}
+
+// Occurrence builds the transaction materialized for the occurrence on
+// date. Its ID is derived from the rule and the date, so materializing the
+// same occurrence twice yields the same transaction.
+func (r *Recurrence) Occurrence(date time.Time, now time.Time) *Transaction {
+	recurrenceID := r.ID
+	transaction := &Transaction{
+		OwnerID:      r.OwnerID,
+		ID:           OccurrenceID(r.ID, date),
+		Type:         r.Type,
+		Title:        r.Title,
+		Description:  r.Description,
+		Money:        r.Money,
+		CategoryID:   r.CategoryID,
+		AccountID:    r.AccountID,
+		Tags:         append([]string(nil), r.Tags...),
+		RecurrenceID: &recurrenceID,
+		OccurredAt:   date,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	transaction.SetKeys()
+	return transaction
+}
+
+// OccurrenceID is the deterministic transaction ID of the occurrence of a
+// recurrence on date.
+func OccurrenceID(recurrenceID string, date time.Time) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(recurrenceID+"#"+date.Format("2006-01-02"))).String()
+}
+
+// ApplyTo copies the template fields of the rule onto a transaction it
+// materialized earlier.
+func (r *Recurrence) ApplyTo(transaction *Transaction) {
+	transaction.Type = r.Type
+	transaction.Title = r.Title
+	transaction.Description = r.Description
+	transaction.Money = r.Money
+	transaction.CategoryID = r.CategoryID
+	transaction.AccountID = r.AccountID
+	transaction.Tags = append([]string(nil), r.Tags...)
+}
@@ -0,0 +1,279 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PeriodSummary totals the transactions of a period. Net is what income
+// left after purchases and investments, and SavingsRate the percentage of
+// income not spent on purchases, investments counting as savings. It is
+// nil when the period had no income.
+type PeriodSummary struct {
+	Income      int      `json:"income"`
+	Purchases   int      `json:"purchases"`
+	Investments int      `json:"investments"`
+	Net         int      `json:"net"`
+	SavingsRate *float64 `json:"savings_rate"`
+}
+
+// MonthSummary is the PeriodSummary of one "2006-01" month.
+type MonthSummary struct {
+	Month string `json:"month"`
+	PeriodSummary
+}
+
+// MonthlyReport summarizes every month of a year, amounts in Currency.
+type MonthlyReport struct {
+	Year     int            `json:"year"`
+	Currency string         `json:"currency"`
+	Months   []MonthSummary `json:"months"`
+	Total    PeriodSummary  `json:"total"`
+}
+
+// NewMonthlyReport returns an empty report for each month of year.
+func NewMonthlyReport(year int, currency string) *MonthlyReport {
+	report := &MonthlyReport{
+		Year:     year,
+		Currency: currency,
+		Months:   make([]MonthSummary, 12),
+	}
+	for i := range report.Months {
+		report.Months[i].Month = time.Date(year, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC).Format(MonthLayout)
+	}
+	return report
+}
+
+// Add counts amount, already in the report currency, in the month
+// transaction occurred and in the year total. Transfers and transactions of
+// other years are left out.
+func (r *MonthlyReport) Add(transaction Transaction, amount int) {
+	if transaction.OccurredAt.Year() != r.Year {
+		return
+	}
+	r.Months[transaction.OccurredAt.Month()-1].Add(transaction.Type, amount)
+	r.Total.Add(transaction.Type, amount)
+}
+
+// AddAggregate counts the sums of aggregate, already in the report
+// currency, in its month and in the year total.
+func (r *MonthlyReport) AddAggregate(aggregate MonthlyAggregate) {
+	month, err := ParseMonth(aggregate.Month)
+	if err != nil || month.Year() != r.Year {
+		return
+	}
+	for _, summary := range []*PeriodSummary{&r.Months[month.Month()-1].PeriodSummary, &r.Total} {
+		summary.Add(TransactionTypeIncome, aggregate.Income)
+		summary.Add(TransactionTypePurchase, aggregate.Purchases)
+		summary.Add(TransactionTypeInvestment, aggregate.Investments)
+	}
+}
+
+// Add counts amount under kind. Transfers move money between accounts and
+// are left out.
+func (s *PeriodSummary) Add(kind TransactionType, amount int) {
+	switch kind {
+	case TransactionTypeIncome:
+		s.Income += amount
+	case TransactionTypePurchase:
+		s.Purchases += amount
+	case TransactionTypeInvestment:
+		s.Investments += amount
+	default:
+		return
+	}
+
+	s.Net = s.Income - s.Purchases - s.Investments
+	s.SavingsRate = nil
+	if s.Income > 0 {
+		rate := percentage(s.Income-s.Purchases, s.Income)
+		s.SavingsRate = &rate
+	}
+}
+
+// CategoryReport breaks the purchases of StartDate through EndDate down by
+// category, amounts in Currency. Top holds the largest purchases, and
+// Previous, when compared, the totals of the period before.
+type CategoryReport struct {
+	StartDate  string              `json:"start_date"`
+	EndDate    string              `json:"end_date"`
+	Currency   string              `json:"currency"`
+	Total      int                 `json:"total"`
+	Count      int                 `json:"count"`
+	Categories []CategorySpending  `json:"categories"`
+	Top        []RankedTransaction `json:"top"`
+	Previous   *PeriodSpending     `json:"previous,omitempty"`
+
+	groups map[string]*CategorySpending
+}
+
+// CategorySpending is what was spent in one category. Purchases without a
+// category are grouped by Type instead. Percentage is the share of the
+// report total; PreviousTotal and Change, the percentage it grew by, are
+// set when the report is compared, Change only if the category had
+// spending before.
+type CategorySpending struct {
+	CategoryID    *string          `json:"category_id,omitempty"`
+	Type          *TransactionType `json:"type,omitempty"`
+	Name          string           `json:"name"`
+	Total         int              `json:"total"`
+	Count         int              `json:"count"`
+	Percentage    float64          `json:"percentage"`
+	PreviousTotal *int             `json:"previous_total,omitempty"`
+	Change        *float64         `json:"change,omitempty"`
+}
+
+// PeriodSpending totals the purchases of the period a report is compared
+// against.
+type PeriodSpending struct {
+	StartDate string   `json:"start_date"`
+	EndDate   string   `json:"end_date"`
+	Total     int      `json:"total"`
+	Count     int      `json:"count"`
+	Change    *float64 `json:"change,omitempty"`
+}
+
+// RankedTransaction is a transaction with its amount in the report
+// currency.
+type RankedTransaction struct {
+	Amount      int         `json:"amount"`
+	Transaction Transaction `json:"transaction"`
+}
+
+// NewCategoryReport returns an empty report of the purchases from start
+// through end, both "2006-01-02" days.
+func NewCategoryReport(start string, end string, currency string) *CategoryReport {
+	return &CategoryReport{
+		StartDate:  start,
+		EndDate:    end,
+		Currency:   currency,
+		Categories: []CategorySpending{},
+		Top:        []RankedTransaction{},
+		groups:     map[string]*CategorySpending{},
+	}
+}
+
+// Add counts a purchase whose amount, already in the report currency, is
+// amount. Other transaction types are left out.
+func (r *CategoryReport) Add(transaction Transaction, amount int) {
+	if transaction.Type != TransactionTypePurchase {
+		return
+	}
+
+	group := r.group(transaction.CategoryID, transaction.Type)
+	group.Total += amount
+	group.Count++
+	r.Total += amount
+	r.Count++
+	r.Top = append(r.Top, RankedTransaction{Amount: amount, Transaction: transaction})
+}
+
+// group returns the spending of a category, or of kind when categoryID is
+// nil, creating it when missing.
+func (r *CategoryReport) group(categoryID *string, kind TransactionType) *CategorySpending {
+	key := string(kind)
+	if categoryID != nil {
+		key = CategoryKey(*categoryID)
+	}
+
+	group, ok := r.groups[key]
+	if !ok {
+		group = &CategorySpending{CategoryID: categoryID}
+		if categoryID == nil {
+			group.Type = &kind
+		}
+		r.groups[key] = group
+	}
+	return group
+}
+
+// Compare sets the totals of previous, the report of the period before,
+// next to those of r. Categories only spent on before are listed with a
+// zero total.
+func (r *CategoryReport) Compare(previous *CategoryReport) {
+	r.Previous = &PeriodSpending{
+		StartDate: previous.StartDate,
+		EndDate:   previous.EndDate,
+		Total:     previous.Total,
+		Count:     previous.Count,
+		Change:    change(r.Total, previous.Total),
+	}
+
+	for _, before := range previous.groups {
+		kind := TransactionTypePurchase
+		if before.Type != nil {
+			kind = *before.Type
+		}
+		r.group(before.CategoryID, kind)
+	}
+	for key, group := range r.groups {
+		total := 0
+		if before, ok := previous.groups[key]; ok {
+			total = before.Total
+		}
+		group.PreviousTotal = &total
+		group.Change = change(group.Total, total)
+	}
+}
+
+// Finish names the categories from names, keyed by category ID, ranks them
+// by total and keeps the top largest purchases.
+func (r *CategoryReport) Finish(names map[string]string, top int) {
+	r.Categories = make([]CategorySpending, 0, len(r.groups))
+	for _, group := range r.groups {
+		if group.CategoryID != nil {
+			group.Name = names[*group.CategoryID]
+		} else {
+			group.Name = string(*group.Type)
+		}
+		if r.Total > 0 {
+			group.Percentage = percentage(group.Total, r.Total)
+		}
+		r.Categories = append(r.Categories, *group)
+	}
+	sort.SliceStable(r.Categories, func(i, j int) bool {
+		if r.Categories[i].Total != r.Categories[j].Total {
+			return r.Categories[i].Total > r.Categories[j].Total
+		}
+		return r.Categories[i].Name < r.Categories[j].Name
+	})
+
+	sort.SliceStable(r.Top, func(i, j int) bool {
+		if r.Top[i].Amount != r.Top[j].Amount {
+			return r.Top[i].Amount > r.Top[j].Amount
+		}
+		return r.Top[i].Transaction.OccurredAt.After(r.Top[j].Transaction.OccurredAt)
+	})
+	r.Top = r.Top[:min(top, len(r.Top))]
+}
+
+// PreviousPeriod returns the period of the same length that ends the day
+// before start. A period of whole months is followed back by as many whole
+// months, so March is compared with February rather than with the last 31
+// days of it.
+func PreviousPeriod(start time.Time, end time.Time) (time.Time, time.Time) {
+	previousEnd := start.AddDate(0, 0, -1)
+	if start.Day() == 1 && end.AddDate(0, 0, 1).Day() == 1 {
+		months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+		return start.AddDate(0, -months, 0), previousEnd
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	return start.AddDate(0, 0, -days), previousEnd
+}
+
+// change is the percentage by which now grew over before, or nil when
+// there was nothing before to grow from.
+func change(now int, before int) *float64 {
+	if before == 0 {
+		return nil
+	}
+	value := percentage(now-before, before)
+	return &value
+}
+
+// percentage is part as a percentage of whole, rounded to two decimals.
+func percentage(part int, whole int) float64 {
+	return math.Round(float64(part)/float64(whole)*10000) / 100
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// TagUsage reports how many transactions carry a tag.
+type TagUsage struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTag trims and lower-cases a tag so "Trip-2026 " and "trip-2026"
+// are the same tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes, de-duplicates and sorts tags, dropping empty
+// ones. It returns nil when nothing is left.
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// HasTag reports whether tags contains tag.
+func HasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameTag replaces from with to in tags, merging both when to is already
+// present.
+func RenameTag(tags []string, from string, to string) []string {
+	renamed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag == from {
+			tag = to
+		}
+		renamed = append(renamed, tag)
+	}
+	return NormalizeTags(renamed)
+}
@@ -3,37 +3,90 @@ package model
 import (
 	"fmt"
 	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 type TransactionType string
 
 const (
-	TransactionTypePurchase TransactionType = "PURCHASE"
-	TransactionTypeIncome TransactionType = "INCOME"
+	TransactionTypePurchase   TransactionType = "PURCHASE"
+	TransactionTypeIncome     TransactionType = "INCOME"
 	TransactionTypeInvestment TransactionType = "INVESTMENT"
+	TransactionTypeTransfer   TransactionType = "TRANSFER"
 )
 
 type Transaction struct {
 	PK          string          `dynamodbav:"PK"`
 	SK          string          `dynamodbav:"SK"`
+	GSI1PK      string          `json:"-" dynamodbav:"GSI1PK"`
+	GSI1SK      string          `json:"-" dynamodbav:"GSI1SK"`
+	OwnerID     string          `json:"-" dynamodbav:"owner_id"`
 	ID          string          `json:"id" dynamodbav:"id"`
 	Type        TransactionType `json:"type" dynamodbav:"type"`
 	Title       string          `json:"title" dynamodbav:"title"`
 	Description *string         `json:"description,omitempty" dynamodbav:"description,omitempty"`
-	Amount      int             `json:"amount" dynamodbav:"amount"`
-	CreatedAt   time.Time       `json:"created_at" dynamodbav:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at" dynamodbav:"updated_at"`
+	Money
+	CategoryID    *string           `json:"category_id,omitempty" dynamodbav:"category_id,omitempty"`
+	AccountID     *string           `json:"account_id,omitempty" dynamodbav:"account_id,omitempty"`
+	Tags          []string          `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
+	RecurrenceID  *string           `json:"recurrence_id,omitempty" dynamodbav:"recurrence_id,omitempty"`
+	ParentID      *string           `json:"parent_id,omitempty" dynamodbav:"parent_id,omitempty"`
+	Installment   int               `json:"installment,omitempty" dynamodbav:"installment,omitempty"`
+	Installments  int               `json:"installments,omitempty" dynamodbav:"installments,omitempty"`
+	CounterpartID *string           `json:"counterpart_id,omitempty" dynamodbav:"counterpart_id,omitempty"`
+	Direction     TransferDirection `json:"direction,omitempty" dynamodbav:"direction,omitempty"`
+	Version       int               `json:"version" dynamodbav:"version"`
+	OccurredAt    time.Time         `json:"occurred_at" dynamodbav:"occurred_at"`
+	CreatedAt     time.Time         `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// OwnerKey returns the partition key holding every item of a single owner.
+func OwnerKey(owner string) string {
+	return fmt.Sprintf("USER#%s", owner)
 }
 
 func (t *Transaction) GetKey() map[string]types.AttributeValue {
-    return map[string]types.AttributeValue{
-        "PK": &types.AttributeValueMemberS{Value: t.PK},
-        "SK": &types.AttributeValueMemberS{Value: t.SK},
-    }
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: t.PK},
+		"SK": &types.AttributeValueMemberS{Value: t.SK},
+	}
 }
 
+// SetKeys sorts the transaction by the day it occurred, in the time zone
+// OccurredAt was recorded in, which is the owner's. The CREATEDAT prefix
+// predates OccurredAt and is kept so stored items keep their keys: until
+// then every transaction occurred on the day it was created.
 func (e *Transaction) SetKeys() {
-	e.PK = "TRANSACTION"
-	e.SK = fmt.Sprintf("CREATEDAT#%s#%s", e.CreatedAt.Format("2006-01-02"), e.ID)
+	e.PK = OwnerKey(e.OwnerID)
+	e.SK = TransactionDateKey(e.OccurredAt.Format("2006-01-02"), e.ID)
+	e.GSI1PK = e.PK
+	e.GSI1SK = TransactionIDKey(e.ID)
+}
+
+// TransactionDateKey is the sort key of the transaction id that occurred on
+// date, a day like 2026-01-31.
+func TransactionDateKey(date string, id string) string {
+	return fmt.Sprintf("CREATEDAT#%s#%s", date, id)
+}
+
+// UnmarshalDynamoDBAttributeValue reads items stored before OccurredAt
+// existed as having occurred when they were created.
+func (e *Transaction) UnmarshalDynamoDBAttributeValue(value types.AttributeValue) error {
+	type stored Transaction
+	if err := attributevalue.Unmarshal(value, (*stored)(e)); err != nil {
+		return err
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = e.CreatedAt
+	}
+	return nil
+}
+
+// TransactionIDKey is the GSI1 sort key that locates a transaction by ID
+// alone, regardless of the date encoded in its SK.
+func TransactionIDKey(id string) string {
+	return fmt.Sprintf("TRANSACTION#%s", id)
 }
@@ -0,0 +1,41 @@
+package model
+
+// TransferDirection tells which side of a transfer a leg is on.
+type TransferDirection string
+
+const (
+	TransferOut TransferDirection = "OUT"
+	TransferIn  TransferDirection = "IN"
+)
+
+// Transfer moves money between two accounts of the same owner. It is
+// stored as two TRANSFER transactions, each pointing at the other through
+// CounterpartID: From takes the amount out of its account and To puts it
+// into its own. Transfers never count as income or spending.
+type Transfer struct {
+	From Transaction `json:"from"`
+	To   Transaction `json:"to"`
+}
+
+// Link marks both legs as a transfer pointing at each other and sets their
+// keys. The legs share the date of From.
+func (t *Transfer) Link() {
+	fromID, toID := t.From.ID, t.To.ID
+
+	t.From.Type = TransactionTypeTransfer
+	t.From.Direction = TransferOut
+	t.From.CounterpartID = &toID
+	t.From.SetKeys()
+
+	t.To.Type = TransactionTypeTransfer
+	t.To.Direction = TransferIn
+	t.To.CounterpartID = &fromID
+	t.To.OccurredAt = t.From.OccurredAt
+	t.To.CreatedAt = t.From.CreatedAt
+	t.To.SetKeys()
+}
+
+// IsTransfer reports whether t is a leg of a transfer.
+func (t *Transaction) IsTransfer() bool {
+	return t.Type == TransactionTypeTransfer
+}
@@ -0,0 +1,117 @@
+package recurrence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Materializer turns due recurrences into real transactions. Runs are
+// idempotent: occurrence IDs are derived from the rule and the date, and a
+// transaction that already exists is left untouched, so a run interrupted
+// halfway can simply be repeated.
+type Materializer struct {
+	recurrences  repository.RecurrenceRepository
+	transactions repository.TransactionRepository
+}
+
+func NewMaterializer(recurrences repository.RecurrenceRepository, transactions repository.TransactionRepository) *Materializer {
+	return &Materializer{
+		recurrences:  recurrences,
+		transactions: transactions,
+	}
+}
+
+// maxZoneOffset is how far ahead of UTC any time zone runs.
+const maxZoneOffset = 14 * time.Hour
+
+// Run materializes, for every rule, each occurrence dated on or before the
+// day now falls on in the rule's time zone, and moves the rule's
+// NextOccurrence past it. Rules without a zone use the zone of now. A
+// failing rule is reported but does not stop the others; it is retried on
+// the next run. It returns how many transactions were created.
+func (m *Materializer) Run(ctx context.Context, now time.Time) (int, error) {
+	// No zone has reached a later date than UTC+14, so every due rule is
+	// among these; the ones whose zone is still a day behind are skipped.
+	date := now.UTC().Add(maxZoneOffset).Format(DateLayout)
+	logger.Info("Attempting to materialize due recurrences", zap.String("date", date))
+
+	candidates, err := m.recurrences.ListDueRecurrences(ctx, date)
+	if err != nil {
+		return 0, err
+	}
+
+	created, due := 0, 0
+	var errs []error
+	for i := range candidates {
+		recurrence := &candidates[i]
+		today := now.In(recurrence.Location(now.Location()))
+		if *recurrence.NextOccurrence > today.Format(DateLayout) {
+			continue
+		}
+
+		due++
+		count, err := m.materialize(ctx, recurrence, today)
+		created += count
+		if err != nil {
+			logger.Error("Failed to materialize recurrence", err, zap.String("recurrence_id", recurrence.ID))
+			errs = append(errs, fmt.Errorf("recurrence '%s': %w", recurrence.ID, err))
+		}
+	}
+
+	logger.Info("Recurrences successfully materialized", zap.Int("recurrences", due), zap.Int("created", created))
+	return created, errors.Join(errs...)
+}
+
+func (m *Materializer) materialize(ctx context.Context, recurrence *model.Recurrence, today time.Time) (int, error) {
+	rule, err := Parse(recurrence.RRule)
+	if err != nil {
+		return 0, err
+	}
+	start, err := time.Parse(DateLayout, recurrence.StartDate)
+	if err != nil {
+		return 0, err
+	}
+	next, err := time.Parse(DateLayout, *recurrence.NextOccurrence)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	created := 0
+	for _, date := range rule.Between(start, next, today) {
+		_, err := m.transactions.NewTransaction(ctx, recurrence.Occurrence(date, now), nil)
+		if errors.Is(err, repository.ErrTransactionExists) {
+			continue
+		}
+		if err != nil {
+			return created, err
+		}
+		created++
+	}
+
+	recurrence.NextOccurrence = NextOccurrence(rule, start, today)
+	recurrence.UpdatedAt = now
+	recurrence.SetKeys()
+	if _, err := m.recurrences.UpdateRecurrence(ctx, recurrence); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+// NextOccurrence returns the date of the first occurrence strictly after
+// after, or nil when the rule has ended by then.
+func NextOccurrence(rule *Rule, start time.Time, after time.Time) *string {
+	next, ok := rule.After(start, after)
+	if !ok {
+		return nil
+	}
+	date := next.Format(DateLayout)
+	return &date
+}
@@ -0,0 +1,156 @@
+package recurrence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/recurrence"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/repository/repositorytest"
+)
+
+type fixture struct {
+	recurrences  *repository.MemoryRecurrenceRepo
+	transactions *repository.MemoryTransactionRepo
+	materializer *recurrence.Materializer
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		recurrences:  repository.NewMemoryRecurrenceRepository(),
+		transactions: repository.NewMemoryTransactionRepository(),
+	}
+	f.materializer = recurrence.NewMaterializer(f.recurrences, f.transactions)
+	return f
+}
+
+// recurrence stores a rule on the 5th of every month since January 2026,
+// due in zone.
+func (f *fixture) recurrence(t *testing.T, owner string, next string, zone string) *model.Recurrence {
+	t.Helper()
+	item := repositorytest.NewRecurrence(owner, next)
+	item.TimeZone = zone
+	if _, err := f.recurrences.NewRecurrence(context.Background(), item); err != nil {
+		t.Fatalf("NewRecurrence: %v", err)
+	}
+	return item
+}
+
+func (f *fixture) run(t *testing.T, now string) int {
+	t.Helper()
+	at, err := time.Parse(time.RFC3339, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := f.materializer.Run(context.Background(), at)
+	if err != nil {
+		t.Fatalf("Run at %s: %v", now, err)
+	}
+	return created
+}
+
+func (f *fixture) next(t *testing.T, item *model.Recurrence) string {
+	t.Helper()
+	stored, err := f.recurrences.GetRecurrenceByID(context.Background(), item.OwnerID, item.ID)
+	if err != nil {
+		t.Fatalf("GetRecurrenceByID: %v", err)
+	}
+	if stored.NextOccurrence == nil {
+		return ""
+	}
+	return *stored.NextOccurrence
+}
+
+func TestRunTwiceCreatesNoDuplicates(t *testing.T) {
+	f := newFixture()
+	rent := f.recurrence(t, "alice", "2026-01-05", "UTC")
+
+	if created := f.run(t, "2026-03-10T12:00:00Z"); created != 3 {
+		t.Fatalf("first run created %d transactions, want 3", created)
+	}
+	if created := f.run(t, "2026-03-10T12:00:00Z"); created != 0 {
+		t.Fatalf("second run created %d transactions, want 0", created)
+	}
+
+	transactions, err := repository.ListAllTransactions(context.Background(), f.transactions, "alice", "2026-01-01", "2026-12-31")
+	if err != nil {
+		t.Fatalf("ListAllTransactions: %v", err)
+	}
+	if len(transactions) != 3 {
+		t.Fatalf("alice has %d transactions, want 3", len(transactions))
+	}
+	for _, transaction := range transactions {
+		if transaction.RecurrenceID == nil || *transaction.RecurrenceID != rent.ID {
+			t.Fatalf("transaction %+v does not point at the rule", transaction)
+		}
+	}
+	if next := f.next(t, rent); next != "2026-04-05" {
+		t.Fatalf("next occurrence %s, want 2026-04-05", next)
+	}
+}
+
+// A replay that finds the occurrences of a run interrupted before the rule
+// moved on keeps them and only advances the rule.
+func TestRunAfterInterruptedRun(t *testing.T) {
+	f := newFixture()
+	rent := f.recurrence(t, "alice", "2026-01-05", "UTC")
+	for _, day := range []string{"2026-01-05", "2026-02-05"} {
+		occurrence := rent.Occurrence(date(t, day), time.Now().UTC())
+		if _, err := f.transactions.NewTransaction(context.Background(), occurrence, nil); err != nil {
+			t.Fatalf("NewTransaction: %v", err)
+		}
+	}
+
+	if created := f.run(t, "2026-02-10T12:00:00Z"); created != 0 {
+		t.Fatalf("replay created %d transactions, want 0", created)
+	}
+	if next := f.next(t, rent); next != "2026-03-05" {
+		t.Fatalf("next occurrence %s, want 2026-03-05", next)
+	}
+}
+
+func TestRunUsesTheZoneOfEachRecurrence(t *testing.T) {
+	f := newFixture()
+	saoPaulo := f.recurrence(t, "alice", "2026-04-05", "America/Sao_Paulo")
+	tokyo := f.recurrence(t, "bob", "2026-04-05", "Asia/Tokyo")
+
+	// 02:00 UTC is 11:00 on the 5th in Tokyo, but still 23:00 on the 4th in
+	// São Paulo.
+	if created := f.run(t, "2026-04-05T02:00:00Z"); created != 1 {
+		t.Fatalf("run created %d transactions, want only Tokyo's", created)
+	}
+	if next := f.next(t, tokyo); next != "2026-05-05" {
+		t.Fatalf("Tokyo's next occurrence %s, want 2026-05-05", next)
+	}
+	if next := f.next(t, saoPaulo); next != "2026-04-05" {
+		t.Fatalf("São Paulo's next occurrence %s, want 2026-04-05", next)
+	}
+
+	if created := f.run(t, "2026-04-05T03:00:00Z"); created != 1 {
+		t.Fatalf("run at midnight in São Paulo created %d transactions, want 1", created)
+	}
+	if next := f.next(t, saoPaulo); next != "2026-05-05" {
+		t.Fatalf("São Paulo's next occurrence %s, want 2026-05-05", next)
+	}
+}
+
+// Rules saved before zones were recorded are due in the zone of the time
+// the scheduler passes, which is the deployment's TIME_ZONE.
+func TestRunFallsBackToTheZoneOfNow(t *testing.T) {
+	f := newFixture()
+	legacy := f.recurrence(t, "alice", "2026-04-05", "")
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := f.materializer.Run(context.Background(), time.Date(2026, 4, 4, 23, 0, 0, 0, saoPaulo))
+	if err != nil || created != 0 {
+		t.Fatalf("run on the 4th in São Paulo created %d transactions, %v", created, err)
+	}
+	if next := f.next(t, legacy); next != "2026-04-05" {
+		t.Fatalf("next occurrence %s, want 2026-04-05", next)
+	}
+}
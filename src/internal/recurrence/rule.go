@@ -0,0 +1,265 @@
+// Package recurrence parses the subset of RFC 5545 RRULEs supported for
+// recurring transactions and expands them into occurrence dates.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Rule is a parsed RRULE. Supported parts are FREQ, INTERVAL, BYDAY (weekly
+// rules only), BYMONTHDAY (a single day, negative counts from the end of the
+// month), BYMONTH (yearly rules only), COUNT and UNTIL.
+//
+// Unlike RFC 5545, a BYMONTHDAY past the end of a month is clamped to the
+// last day instead of skipping the month, so "rent on the 31st" still
+// happens in February.
+type Rule struct {
+	Frequency  Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+	ByMonth    time.Month
+	Count      int
+	Until      *time.Time
+}
+
+// Parse reads an RRULE such as "FREQ=MONTHLY;BYMONTHDAY=5". A leading
+// "RRULE:" prefix is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		name, raw, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("malformed rule part '%s'", part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Frequency = Frequency(strings.ToUpper(raw))
+			switch rule.Frequency {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("unsupported FREQ '%s'", raw)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positive(name, raw)
+		case "COUNT":
+			rule.Count, err = positive(name, raw)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = strconv.Atoi(raw)
+			if err != nil || rule.ByMonthDay == 0 || rule.ByMonthDay < -31 || rule.ByMonthDay > 31 {
+				err = fmt.Errorf("BYMONTHDAY must be a single day between -31 and 31, got '%s'", raw)
+			}
+		case "BYMONTH":
+			var month int
+			month, err = strconv.Atoi(raw)
+			if err != nil || month < 1 || month > 12 {
+				err = fmt.Errorf("BYMONTH must be a single month between 1 and 12, got '%s'", raw)
+			}
+			rule.ByMonth = time.Month(month)
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(raw), ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value '%s'", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "UNTIL":
+			var until time.Time
+			until, err = time.Parse("20060102", raw[:min(len(raw), 8)])
+			if err != nil {
+				err = fmt.Errorf("UNTIL must be a date like 20260131, got '%s'", raw)
+			}
+			rule.Until = &until
+		default:
+			err = fmt.Errorf("unsupported rule part '%s'", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Frequency == "" {
+		return nil, errors.New("recurrence rule needs FREQ")
+	}
+	if len(rule.ByDay) > 0 && rule.Frequency != Weekly {
+		return nil, errors.New("BYDAY is only supported on weekly rules")
+	}
+	if rule.ByMonth != 0 && rule.Frequency != Yearly {
+		return nil, errors.New("BYMONTH is only supported on yearly rules")
+	}
+	if rule.ByMonthDay != 0 && rule.Frequency != Monthly && rule.Frequency != Yearly {
+		return nil, errors.New("BYMONTHDAY is only supported on monthly and yearly rules")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("COUNT and UNTIL cannot be combined")
+	}
+	return rule, nil
+}
+
+func positive(name string, raw string) (int, error) {
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer, got '%s'", name, raw)
+	}
+	return value, nil
+}
+
+// Between returns the occurrences of a series starting at start that fall
+// on or after from and on or before to. Dates are compared by the day they
+// fall on in their own location.
+func (r *Rule) Between(start time.Time, from time.Time, to time.Time) []time.Time {
+	start, from, to = day(start), day(from), day(to)
+
+	var occurrences []time.Time
+	r.each(start, func(occurrence time.Time) bool {
+		if occurrence.After(to) {
+			return false
+		}
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+		return true
+	})
+	return occurrences
+}
+
+// After returns the first occurrence strictly after after, or false when
+// the series has ended.
+func (r *Rule) After(start time.Time, after time.Time) (time.Time, bool) {
+	start, after = day(start), day(after)
+
+	var (
+		next  time.Time
+		found bool
+	)
+	r.each(start, func(occurrence time.Time) bool {
+		if occurrence.After(after) {
+			next, found = occurrence, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// each walks the series in order until yield returns false, COUNT is
+// reached or UNTIL is passed.
+func (r *Rule) each(start time.Time, yield func(time.Time) bool) {
+	emitted := 0
+	emit := func(occurrence time.Time) bool {
+		if occurrence.Before(start) {
+			return true
+		}
+		if r.Until != nil && occurrence.After(*r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return yield(occurrence)
+	}
+
+	for period := 0; ; period++ {
+		for _, occurrence := range r.period(start, period) {
+			if !emit(occurrence) {
+				return
+			}
+		}
+	}
+}
+
+// period returns the occurrences of the n-th interval of the series, in
+// order.
+func (r *Rule) period(start time.Time, n int) []time.Time {
+	step := n * r.Interval
+
+	switch r.Frequency {
+	case Daily:
+		return []time.Time{start.AddDate(0, 0, step)}
+
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{start.AddDate(0, 0, 7*step)}
+		}
+		weekStart := start.AddDate(0, 0, -int(start.Weekday())+7*step)
+		var days []time.Time
+		for offset := 0; offset < 7; offset++ {
+			candidate := weekStart.AddDate(0, 0, offset)
+			for _, weekday := range r.ByDay {
+				if candidate.Weekday() == weekday {
+					days = append(days, candidate)
+				}
+			}
+		}
+		return days
+
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		return []time.Time{monthDay(first, r.dayOfMonth(start))}
+
+	case Yearly:
+		month := start.Month()
+		if r.ByMonth != 0 {
+			month = r.ByMonth
+		}
+		first := time.Date(start.Year()+step, month, 1, 0, 0, 0, 0, time.UTC)
+		return []time.Time{monthDay(first, r.dayOfMonth(start))}
+	}
+	return nil
+}
+
+func (r *Rule) dayOfMonth(start time.Time) int {
+	if r.ByMonthDay != 0 {
+		return r.ByMonthDay
+	}
+	return start.Day()
+}
+
+// monthDay resolves day within the month starting at first, counting from
+// the end when negative and clamping to the last day.
+func monthDay(first time.Time, day int) time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	if day < 0 {
+		day = last + day + 1
+	}
+	day = max(1, min(day, last))
+	return first.AddDate(0, 0, day-1)
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,103 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/recurrence"
+)
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(recurrence.DateLayout, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func parse(t *testing.T, value string) *recurrence.Rule {
+	t.Helper()
+	rule, err := recurrence.Parse(value)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", value, err)
+	}
+	return rule
+}
+
+func formatAll(dates []time.Time) []string {
+	formatted := make([]string, len(dates))
+	for i, date := range dates {
+		formatted[i] = date.Format(recurrence.DateLayout)
+	}
+	return formatted
+}
+
+func expectDates(t *testing.T, got []time.Time, want ...string) {
+	t.Helper()
+	formatted := formatAll(got)
+	if len(formatted) != len(want) {
+		t.Fatalf("got %v, want %v", formatted, want)
+	}
+	for i := range want {
+		if formatted[i] != want[i] {
+			t.Fatalf("got %v, want %v", formatted, want)
+		}
+	}
+}
+
+func TestMonthDayClampedToEndOfMonth(t *testing.T) {
+	rule := parse(t, "FREQ=MONTHLY;BYMONTHDAY=31")
+	start := date(t, "2026-01-31")
+
+	expectDates(t, rule.Between(start, start, date(t, "2026-04-30")),
+		"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30")
+
+	next, ok := rule.After(start, date(t, "2026-01-31"))
+	if !ok || next.Format(recurrence.DateLayout) != "2026-02-28" {
+		t.Fatalf("After 2026-01-31 = %v, %t, want 2026-02-28", next, ok)
+	}
+	next, ok = rule.After(start, date(t, "2028-01-31"))
+	if !ok || next.Format(recurrence.DateLayout) != "2028-02-29" {
+		t.Fatalf("After 2028-01-31 = %v, %t, want the leap day", next, ok)
+	}
+	// The clamp is per month: March is back on the 31st.
+	next, _ = rule.After(start, date(t, "2026-02-28"))
+	if next.Format(recurrence.DateLayout) != "2026-03-31" {
+		t.Fatalf("After 2026-02-28 = %v, want 2026-03-31", next)
+	}
+}
+
+func TestCountEndsTheSeries(t *testing.T) {
+	rule := parse(t, "FREQ=MONTHLY;BYMONTHDAY=5;COUNT=3")
+	start := date(t, "2026-01-05")
+
+	expectDates(t, rule.Between(start, start, date(t, "2027-12-31")),
+		"2026-01-05", "2026-02-05", "2026-03-05")
+	// Occurrences before from still count towards COUNT.
+	expectDates(t, rule.Between(start, date(t, "2026-02-01"), date(t, "2027-12-31")),
+		"2026-02-05", "2026-03-05")
+
+	if next, ok := rule.After(start, date(t, "2026-03-05")); ok {
+		t.Fatalf("After the third occurrence = %v, want the series ended", next)
+	}
+}
+
+func TestUntilEndsTheSeries(t *testing.T) {
+	rule := parse(t, "FREQ=WEEKLY;BYDAY=FR;UNTIL=20260320T235959Z")
+	start := date(t, "2026-03-01")
+
+	// UNTIL itself is included.
+	expectDates(t, rule.Between(start, start, date(t, "2026-12-31")),
+		"2026-03-06", "2026-03-13", "2026-03-20")
+
+	if next, ok := rule.After(start, date(t, "2026-03-20")); ok {
+		t.Fatalf("After UNTIL = %v, want the series ended", next)
+	}
+}
+
+func TestCountAndUntilCannotBeCombined(t *testing.T) {
+	if _, err := recurrence.Parse("FREQ=DAILY;COUNT=2;UNTIL=20260320"); err == nil {
+		t.Fatal("Parse accepted COUNT together with UNTIL")
+	}
+}
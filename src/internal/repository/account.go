@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+type AccountRepo struct {
+	db        *dynamodb.Client
+	tableName string
+}
+
+func NewAccountRepository(db *dynamodb.Client, tableName string) *AccountRepo {
+	return &AccountRepo{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+func (r *AccountRepo) NewAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to create new account", zap.String("account_id", account.ID))
+
+	item, err := attributevalue.MarshalMap(account)
+	if err != nil {
+		logger.Error("Failed to marshal account", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to marshal account: %w", storageError(err))
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		logger.Error("Failed to add account to DynamoDB", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to add account: %w", storageError(err))
+	}
+
+	logger.Info("Account successfully created", zap.String("account_id", account.ID))
+	return account, nil
+}
+
+func (r *AccountRepo) UpdateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to update account", zap.String("account_id", account.ID))
+
+	update := expression.Set(expression.Name("name"), expression.Value(account.Name))
+	update = update.Set(expression.Name("kind"), expression.Value(account.Kind))
+	update = update.Set(expression.Name("currency"), expression.Value(account.Currency))
+	update = update.Set(expression.Name("opening_balance"), expression.Value(account.OpeningBalance))
+	update = update.Set(expression.Name("updated_at"), expression.Value(account.UpdatedAt))
+
+	condition := expression.AttributeExists(expression.Name("PK"))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		logger.Error("Failed to build update expression", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to build update expression: %w", storageError(err))
+	}
+
+	response, err := r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       account.GetKey(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		logger.Error("No account found to update", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("account with ID '%s': %w", account.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to update account in DynamoDB", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to update account with ID '%s': %w", account.ID, storageError(err))
+	}
+
+	var updated model.Account
+	if err := attributevalue.UnmarshalMap(response.Attributes, &updated); err != nil {
+		logger.Error("Failed to unmarshal updated account", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to unmarshal updated account: %w", storageError(err))
+	}
+
+	logger.Info("Account successfully updated", zap.String("account_id", account.ID))
+	return &updated, nil
+}
+
+func (r *AccountRepo) ListAccounts(ctx context.Context, owner string) ([]model.Account, error) {
+	logger.Info("Attempting to list accounts", zap.String("owner", owner))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: model.AccountKey("")},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query accounts from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query accounts from table: %w", storageError(err))
+	}
+
+	accounts := []model.Account{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &accounts); err != nil {
+		logger.Error("Failed to unmarshal accounts list", err)
+		return nil, fmt.Errorf("failed to unmarshal accounts list: %w", storageError(err))
+	}
+
+	logger.Info("Accounts successfully retrieved", zap.Int("count", len(accounts)))
+	return accounts, nil
+}
+
+func (r *AccountRepo) GetAccountByID(ctx context.Context, owner string, id string) (*model.Account, error) {
+	logger.Info("Attempting to fetch account", zap.String("owner", owner), zap.String("account_id", id))
+
+	key := model.Account{OwnerID: owner, ID: id}
+	key.SetKeys()
+
+	response, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       key.GetKey(),
+	})
+	if err != nil {
+		logger.Error("Failed to fetch account from DynamoDB", err, zap.String("account_id", id))
+		return nil, fmt.Errorf("failed to get account with ID '%s': %w", id, storageError(err))
+	}
+
+	if len(response.Item) == 0 {
+		logger.Error("Account not found", ErrNotFound, zap.String("account_id", id))
+		return nil, fmt.Errorf("account with ID '%s': %w", id, ErrNotFound)
+	}
+
+	var account model.Account
+	if err := attributevalue.UnmarshalMap(response.Item, &account); err != nil {
+		logger.Error("Failed to unmarshal account", err, zap.String("account_id", id))
+		return nil, fmt.Errorf("failed to unmarshal account with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Account successfully retrieved", zap.String("account_id", id))
+	return &account, nil
+}
+
+func (r *AccountRepo) DeleteAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to delete account", zap.String("account_id", account.ID))
+
+	response, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.tableName),
+		Key:          account.GetKey(),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logger.Error("Failed to delete account from DynamoDB", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to delete account with ID '%s': %w", account.ID, storageError(err))
+	}
+
+	if len(response.Attributes) == 0 {
+		logger.Error("No account found to delete", ErrNotFound, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("no account found to delete with ID '%s': %w", account.ID, ErrNotFound)
+	}
+
+	var deleted model.Account
+	if err := attributevalue.UnmarshalMap(response.Attributes, &deleted); err != nil {
+		logger.Error("Failed to unmarshal deleted account", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to unmarshal deleted account: %w", storageError(err))
+	}
+
+	logger.Info("Account successfully deleted", zap.String("account_id", account.ID))
+	return &deleted, nil
+}
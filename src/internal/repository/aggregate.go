@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+func (r *TransactionRepo) ListMonthlyAggregates(ctx context.Context, owner string, startMonth string, endMonth string) ([]model.MonthlyAggregate, error) {
+	logger.Info("Attempting to list monthly aggregates", zap.String("owner", owner), zap.String("start_month", startMonth), zap.String("end_month", endMonth))
+
+	// "~" sorts after every currency code, so each currency of endMonth is
+	// included.
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND SK BETWEEN :start AND :end"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":start": &types.AttributeValueMemberS{Value: model.AggregateKey(startMonth, "")},
+			":end":   &types.AttributeValueMemberS{Value: model.AggregateKey(endMonth, "~")},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query monthly aggregates from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query monthly aggregates from table: %w", storageError(err))
+	}
+
+	stored := []model.MonthlyAggregate{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &stored); err != nil {
+		logger.Error("Failed to unmarshal monthly aggregates list", err)
+		return nil, fmt.Errorf("failed to unmarshal monthly aggregates list: %w", storageError(err))
+	}
+
+	// Deleting the last transaction of a month leaves its aggregate at zero.
+	aggregates := []model.MonthlyAggregate{}
+	for _, aggregate := range stored {
+		if !aggregate.Empty() {
+			aggregates = append(aggregates, aggregate)
+		}
+	}
+
+	logger.Info("Monthly aggregates successfully retrieved", zap.Int("count", len(aggregates)))
+	return aggregates, nil
+}
+
+// RebuildMonthlyAggregates reads every transaction of owner and overwrites
+// the stored aggregates with their sums, deleting those no transaction
+// backs. Transactions written while it runs may be counted twice or not at
+// all, so it is meant to run while owner is not writing.
+func (r *TransactionRepo) RebuildMonthlyAggregates(ctx context.Context, owner string) ([]model.MonthlyAggregate, error) {
+	logger.Info("Attempting to rebuild monthly aggregates", zap.String("owner", owner))
+
+	transactions, err := ListAllTransactions(ctx, r, owner, "0001-01-01", "9999-12-31")
+	if err != nil {
+		logger.Error("Failed to list transactions", err, zap.String("owner", owner))
+		return nil, err
+	}
+	computed := model.Aggregates{}
+	for _, transaction := range transactions {
+		computed.Add(transaction, 1)
+	}
+	aggregates := computed.List()
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ProjectionExpression:   aws.String("PK, SK"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: model.AggregatePrefix},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query monthly aggregates from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query monthly aggregates from table: %w", storageError(err))
+	}
+
+	requests := []types.WriteRequest{}
+	kept := map[string]bool{}
+	for i := range aggregates {
+		item, err := attributevalue.MarshalMap(&aggregates[i])
+		if err != nil {
+			logger.Error("Failed to marshal monthly aggregate", err, zap.String("sk", aggregates[i].SK))
+			return nil, fmt.Errorf("failed to marshal monthly aggregate: %w", storageError(err))
+		}
+		kept[aggregates[i].SK] = true
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	for _, item := range items {
+		sk, _ := item["SK"].(*types.AttributeValueMemberS)
+		if sk != nil && !kept[sk.Value] {
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: item}})
+		}
+	}
+
+	if err := batchWrite(ctx, r.db, r.tableName, requests); err != nil {
+		logger.Error("Failed to write monthly aggregates to DynamoDB", err, zap.String("owner", owner))
+		return nil, fmt.Errorf("failed to write monthly aggregates: %w", storageError(err))
+	}
+
+	logger.Info("Monthly aggregates successfully rebuilt", zap.String("owner", owner), zap.Int("count", len(aggregates)))
+	return aggregates, nil
+}
+
+// aggregateWrites builds the writes that add changes to the stored
+// aggregates, creating the ones missing. They carry no condition, so they
+// go in the same TransactWriteItems call as the transaction writes they
+// account for and only fail with them.
+func aggregateWrites(tableName string, changes []model.MonthlyAggregate) ([]types.TransactWriteItem, error) {
+	writes := make([]types.TransactWriteItem, 0, len(changes))
+	for i := range changes {
+		change := &changes[i]
+
+		update := expression.Set(expression.Name("owner_id"), expression.Value(change.OwnerID))
+		update = update.Set(expression.Name("month"), expression.Value(change.Month))
+		update = update.Set(expression.Name("currency"), expression.Value(change.Currency))
+		update = update.Add(expression.Name("income"), expression.Value(change.Income))
+		update = update.Add(expression.Name("income_count"), expression.Value(change.IncomeCount))
+		update = update.Add(expression.Name("purchases"), expression.Value(change.Purchases))
+		update = update.Add(expression.Name("purchase_count"), expression.Value(change.PurchaseCount))
+		update = update.Add(expression.Name("investments"), expression.Value(change.Investments))
+		update = update.Add(expression.Name("investment_count"), expression.Value(change.InvestmentCount))
+
+		expr, err := expression.NewBuilder().WithUpdate(update).Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build aggregate update expression: %w", storageError(err))
+		}
+		writes = append(writes, types.TransactWriteItem{Update: &types.Update{
+			TableName:                 aws.String(tableName),
+			Key:                       change.GetKey(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			UpdateExpression:          expr.Update(),
+		}})
+	}
+	return writes, nil
+}
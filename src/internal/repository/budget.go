@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+type BudgetRepo struct {
+	db        *dynamodb.Client
+	tableName string
+}
+
+func NewBudgetRepository(db *dynamodb.Client, tableName string) *BudgetRepo {
+	return &BudgetRepo{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+func (r *BudgetRepo) NewBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to create new budget", zap.String("budget_id", budget.ID))
+
+	item, err := attributevalue.MarshalMap(budget)
+	if err != nil {
+		logger.Error("Failed to marshal budget", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to marshal budget: %w", storageError(err))
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		logger.Error("Failed to add budget to DynamoDB", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to add budget: %w", storageError(err))
+	}
+
+	logger.Info("Budget successfully created", zap.String("budget_id", budget.ID))
+	return budget, nil
+}
+
+func (r *BudgetRepo) UpdateBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to update budget", zap.String("budget_id", budget.ID))
+
+	update := expression.Set(expression.Name("name"), expression.Value(budget.Name))
+	update = update.Set(expression.Name("category_id"), expression.Value(budget.CategoryID))
+	update = update.Set(expression.Name("type"), expression.Value(budget.Type))
+	update = update.Set(expression.Name("limit"), expression.Value(budget.Limit))
+	update = update.Set(expression.Name("start_month"), expression.Value(budget.StartMonth))
+	update = update.Set(expression.Name("end_month"), expression.Value(budget.EndMonth))
+	update = update.Set(expression.Name("rollover"), expression.Value(budget.Rollover))
+	update = update.Set(expression.Name("updated_at"), expression.Value(budget.UpdatedAt))
+
+	condition := expression.AttributeExists(expression.Name("PK"))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		logger.Error("Failed to build update expression", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to build update expression: %w", storageError(err))
+	}
+
+	response, err := r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       budget.GetKey(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		logger.Error("No budget found to update", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("budget with ID '%s': %w", budget.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to update budget in DynamoDB", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to update budget with ID '%s': %w", budget.ID, storageError(err))
+	}
+
+	var updated model.Budget
+	if err := attributevalue.UnmarshalMap(response.Attributes, &updated); err != nil {
+		logger.Error("Failed to unmarshal updated budget", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to unmarshal updated budget: %w", storageError(err))
+	}
+
+	logger.Info("Budget successfully updated", zap.String("budget_id", budget.ID))
+	return &updated, nil
+}
+
+func (r *BudgetRepo) ListBudgets(ctx context.Context, owner string) ([]model.Budget, error) {
+	logger.Info("Attempting to list budgets", zap.String("owner", owner))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: model.BudgetKey("")},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query budgets from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query budgets from table: %w", storageError(err))
+	}
+
+	budgets := []model.Budget{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &budgets); err != nil {
+		logger.Error("Failed to unmarshal budgets list", err)
+		return nil, fmt.Errorf("failed to unmarshal budgets list: %w", storageError(err))
+	}
+
+	logger.Info("Budgets successfully retrieved", zap.Int("count", len(budgets)))
+	return budgets, nil
+}
+
+func (r *BudgetRepo) GetBudgetByID(ctx context.Context, owner string, id string) (*model.Budget, error) {
+	logger.Info("Attempting to fetch budget", zap.String("owner", owner), zap.String("budget_id", id))
+
+	key := model.Budget{OwnerID: owner, ID: id}
+	key.SetKeys()
+
+	response, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       key.GetKey(),
+	})
+	if err != nil {
+		logger.Error("Failed to fetch budget from DynamoDB", err, zap.String("budget_id", id))
+		return nil, fmt.Errorf("failed to get budget with ID '%s': %w", id, storageError(err))
+	}
+
+	if len(response.Item) == 0 {
+		logger.Error("Budget not found", ErrNotFound, zap.String("budget_id", id))
+		return nil, fmt.Errorf("budget with ID '%s': %w", id, ErrNotFound)
+	}
+
+	var budget model.Budget
+	if err := attributevalue.UnmarshalMap(response.Item, &budget); err != nil {
+		logger.Error("Failed to unmarshal budget", err, zap.String("budget_id", id))
+		return nil, fmt.Errorf("failed to unmarshal budget with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Budget successfully retrieved", zap.String("budget_id", id))
+	return &budget, nil
+}
+
+func (r *BudgetRepo) DeleteBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to delete budget", zap.String("budget_id", budget.ID))
+
+	response, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.tableName),
+		Key:          budget.GetKey(),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logger.Error("Failed to delete budget from DynamoDB", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to delete budget with ID '%s': %w", budget.ID, storageError(err))
+	}
+
+	if len(response.Attributes) == 0 {
+		logger.Error("No budget found to delete", ErrNotFound, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("no budget found to delete with ID '%s': %w", budget.ID, ErrNotFound)
+	}
+
+	var deleted model.Budget
+	if err := attributevalue.UnmarshalMap(response.Attributes, &deleted); err != nil {
+		logger.Error("Failed to unmarshal deleted budget", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to unmarshal deleted budget: %w", storageError(err))
+	}
+
+	logger.Info("Budget successfully deleted", zap.String("budget_id", budget.ID))
+	return &deleted, nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+type CategoryRepo struct {
+	db        *dynamodb.Client
+	tableName string
+}
+
+func NewCategoryRepository(db *dynamodb.Client, tableName string) *CategoryRepo {
+	return &CategoryRepo{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+func (r *CategoryRepo) NewCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to create new category", zap.String("category_id", category.ID))
+
+	item, err := attributevalue.MarshalMap(category)
+	if err != nil {
+		logger.Error("Failed to marshal category", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to marshal category: %w", storageError(err))
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		logger.Error("Failed to add category to DynamoDB", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to add category: %w", storageError(err))
+	}
+
+	logger.Info("Category successfully created", zap.String("category_id", category.ID))
+	return category, nil
+}
+
+func (r *CategoryRepo) UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to update category", zap.String("category_id", category.ID))
+
+	update := expression.Set(expression.Name("name"), expression.Value(category.Name))
+	update = update.Set(expression.Name("color"), expression.Value(category.Color))
+	update = update.Set(expression.Name("icon"), expression.Value(category.Icon))
+	update = update.Set(expression.Name("parent_id"), expression.Value(category.ParentID))
+	update = update.Set(expression.Name("updated_at"), expression.Value(category.UpdatedAt))
+
+	condition := expression.AttributeExists(expression.Name("PK"))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		logger.Error("Failed to build update expression", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to build update expression: %w", storageError(err))
+	}
+
+	response, err := r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       category.GetKey(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		logger.Error("No category found to update", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("category with ID '%s': %w", category.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to update category in DynamoDB", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to update category with ID '%s': %w", category.ID, storageError(err))
+	}
+
+	var updated model.Category
+	if err := attributevalue.UnmarshalMap(response.Attributes, &updated); err != nil {
+		logger.Error("Failed to unmarshal updated category", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to unmarshal updated category: %w", storageError(err))
+	}
+
+	logger.Info("Category successfully updated", zap.String("category_id", category.ID))
+	return &updated, nil
+}
+
+func (r *CategoryRepo) ListCategories(ctx context.Context, owner string) ([]model.Category, error) {
+	logger.Info("Attempting to list categories", zap.String("owner", owner))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: model.CategoryKey("")},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query categories from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query categories from table: %w", storageError(err))
+	}
+
+	categories := []model.Category{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &categories); err != nil {
+		logger.Error("Failed to unmarshal categories list", err)
+		return nil, fmt.Errorf("failed to unmarshal categories list: %w", storageError(err))
+	}
+
+	logger.Info("Categories successfully retrieved", zap.Int("count", len(categories)))
+	return categories, nil
+}
+
+func (r *CategoryRepo) GetCategoryByID(ctx context.Context, owner string, id string) (*model.Category, error) {
+	logger.Info("Attempting to fetch category", zap.String("owner", owner), zap.String("category_id", id))
+
+	key := model.Category{OwnerID: owner, ID: id}
+	key.SetKeys()
+
+	response, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       key.GetKey(),
+	})
+	if err != nil {
+		logger.Error("Failed to fetch category from DynamoDB", err, zap.String("category_id", id))
+		return nil, fmt.Errorf("failed to get category with ID '%s': %w", id, storageError(err))
+	}
+
+	if len(response.Item) == 0 {
+		logger.Error("Category not found", ErrNotFound, zap.String("category_id", id))
+		return nil, fmt.Errorf("category with ID '%s': %w", id, ErrNotFound)
+	}
+
+	var category model.Category
+	if err := attributevalue.UnmarshalMap(response.Item, &category); err != nil {
+		logger.Error("Failed to unmarshal category", err, zap.String("category_id", id))
+		return nil, fmt.Errorf("failed to unmarshal category with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Category successfully retrieved", zap.String("category_id", id))
+	return &category, nil
+}
+
+func (r *CategoryRepo) DeleteCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to delete category", zap.String("category_id", category.ID))
+
+	response, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.tableName),
+		Key:          category.GetKey(),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logger.Error("Failed to delete category from DynamoDB", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to delete category with ID '%s': %w", category.ID, storageError(err))
+	}
+
+	if len(response.Attributes) == 0 {
+		logger.Error("No category found to delete", ErrNotFound, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("no category found to delete with ID '%s': %w", category.ID, ErrNotFound)
+	}
+
+	var deleted model.Category
+	if err := attributevalue.UnmarshalMap(response.Attributes, &deleted); err != nil {
+		logger.Error("Failed to unmarshal deleted category", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to unmarshal deleted category: %w", storageError(err))
+	}
+
+	logger.Info("Category successfully deleted", zap.String("category_id", category.ID))
+	return &deleted, nil
+}
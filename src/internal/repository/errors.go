@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Every error a repository returns either wraps one of these or is an
+// unexpected failure. Handlers map them to HTTP status codes, so callers
+// should test them with errors.Is rather than by message.
+var (
+	// ErrNotFound means the item does not exist for that owner.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the write clashes with what is stored, such as a
+	// create whose key is taken.
+	ErrConflict = errors.New("conflict")
+	// ErrValidation means the input can never be stored as given.
+	ErrValidation = errors.New("invalid input")
+	// ErrUnavailable means the storage backend failed in a way a later
+	// retry may not: throttling, timeouts, a locked database.
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// ErrTransactionExists is returned by NewTransaction when a transaction
+// with the same key is already stored. Recurrence materialization relies on
+// it to stay idempotent.
+var ErrTransactionExists = fmt.Errorf("transaction already exists: %w", ErrConflict)
+
+// ErrVersionMismatch is returned by a versioned write whose expected
+// version is no longer the stored one: someone else changed the item first.
+var ErrVersionMismatch = fmt.Errorf("item was changed by another request: %w", ErrConflict)
+
+// ErrIdempotencyKeyUsed is returned by a create whose idempotency record is
+// already stored: another request with the same key got there first.
+var ErrIdempotencyKeyUsed = fmt.Errorf("idempotency key already used: %w", ErrConflict)
+
+// firstVersion is the version every transaction and recurrence is created
+// with. Items
+// stored before versioning existed read as version 0.
+const firstVersion = 1
+
+// storageError marks err as ErrUnavailable when the backend failed for a
+// transient reason and returns it unchanged otherwise.
+func storageError(err error) error {
+	if isTransient(err) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
+func isTransient(err error) bool {
+	var (
+		throughput *types.ProvisionedThroughputExceededException
+		limit      *types.RequestLimitExceeded
+		internal   *types.InternalServerError
+		conflict   *types.TransactionConflictException
+		network    net.Error
+		database   *sqlite.Error
+	)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	case errors.As(err, &throughput), errors.As(err, &limit), errors.As(err, &internal), errors.As(err, &conflict):
+		return true
+	case errors.As(err, &network):
+		return true
+	case errors.As(err, &database):
+		code := database.Code() & 0xff
+		return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+type ExchangeRateRepo struct {
+	db        *dynamodb.Client
+	tableName string
+}
+
+func NewExchangeRateRepository(db *dynamodb.Client, tableName string) *ExchangeRateRepo {
+	return &ExchangeRateRepo{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// PutExchangeRates writes rates in batches. BatchWriteItem rejects a batch
+// naming the same key twice, so only the last rate given for a pair and day
+// is sent.
+func (r *ExchangeRateRepo) PutExchangeRates(ctx context.Context, rates []model.ExchangeRate) ([]model.ExchangeRate, error) {
+	logger.Info("Attempting to store exchange rates", zap.Int("count", len(rates)))
+
+	positions := map[string]int{}
+	requests := []types.WriteRequest{}
+	for i := range rates {
+		item, err := attributevalue.MarshalMap(&rates[i])
+		if err != nil {
+			logger.Error("Failed to marshal exchange rate", err, zap.String("sk", rates[i].SK))
+			return nil, fmt.Errorf("failed to marshal exchange rate: %w", storageError(err))
+		}
+
+		key := rates[i].PK + "#" + rates[i].SK
+		request := types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}
+		if position, ok := positions[key]; ok {
+			requests[position] = request
+			continue
+		}
+		positions[key] = len(requests)
+		requests = append(requests, request)
+	}
+
+	if err := batchWrite(ctx, r.db, r.tableName, requests); err != nil {
+		logger.Error("Failed to add exchange rates to DynamoDB", err)
+		return nil, fmt.Errorf("failed to add exchange rates: %w", storageError(err))
+	}
+
+	logger.Info("Exchange rates successfully stored", zap.Int("count", len(requests)))
+	return rates, nil
+}
+
+func (r *ExchangeRateRepo) ListExchangeRates(ctx context.Context, owner string) ([]model.ExchangeRate, error) {
+	logger.Info("Attempting to list exchange rates", zap.String("owner", owner))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: model.ExchangeRatePrefix},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query exchange rates from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query exchange rates from table: %w", storageError(err))
+	}
+
+	rates := []model.ExchangeRate{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &rates); err != nil {
+		logger.Error("Failed to unmarshal exchange rates list", err)
+		return nil, fmt.Errorf("failed to unmarshal exchange rates list: %w", storageError(err))
+	}
+
+	logger.Info("Exchange rates successfully retrieved", zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (r *ExchangeRateRepo) DeleteExchangeRate(ctx context.Context, rate *model.ExchangeRate) (*model.ExchangeRate, error) {
+	logger.Info("Attempting to delete exchange rate", zap.String("sk", rate.SK))
+
+	response, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.tableName),
+		Key:          rate.GetKey(),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logger.Error("Failed to delete exchange rate from DynamoDB", err, zap.String("sk", rate.SK))
+		return nil, fmt.Errorf("failed to delete exchange rate for %s/%s on %s: %w", rate.Base, rate.Quote, rate.Date, storageError(err))
+	}
+
+	if len(response.Attributes) == 0 {
+		logger.Error("No exchange rate found to delete", ErrNotFound, zap.String("sk", rate.SK))
+		return nil, fmt.Errorf("no exchange rate found to delete for %s/%s on %s: %w", rate.Base, rate.Quote, rate.Date, ErrNotFound)
+	}
+
+	var deleted model.ExchangeRate
+	if err := attributevalue.UnmarshalMap(response.Attributes, &deleted); err != nil {
+		logger.Error("Failed to unmarshal deleted exchange rate", err, zap.String("sk", rate.SK))
+		return nil, fmt.Errorf("failed to unmarshal deleted exchange rate: %w", storageError(err))
+	}
+
+	logger.Info("Exchange rate successfully deleted", zap.String("sk", rate.SK))
+	return &deleted, nil
+}
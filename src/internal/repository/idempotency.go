@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+func (r *TransactionRepo) GetIdempotencyRecord(ctx context.Context, owner string, key string) (*model.IdempotencyRecord, error) {
+	logger.Info("Attempting to fetch idempotency record", zap.String("owner", owner), zap.String("idempotency_key", key))
+
+	lookup := model.IdempotencyRecord{OwnerID: owner, Key: key}
+	lookup.SetKeys()
+
+	// A retry usually follows the first attempt closely, so an eventually
+	// consistent read could miss the record and create a duplicate.
+	response, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            lookup.GetKey(),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		logger.Error("Failed to fetch idempotency record from DynamoDB", err, zap.String("idempotency_key", key))
+		return nil, fmt.Errorf("failed to get idempotency record '%s': %w", key, storageError(err))
+	}
+
+	var record model.IdempotencyRecord
+	if err := attributevalue.UnmarshalMap(response.Item, &record); err != nil {
+		logger.Error("Failed to unmarshal idempotency record", err, zap.String("idempotency_key", key))
+		return nil, fmt.Errorf("failed to unmarshal idempotency record '%s': %w", key, storageError(err))
+	}
+
+	// The TTL deletes expired items lazily, so they may still be read.
+	if len(response.Item) == 0 || record.Expired(time.Now()) {
+		logger.Info("Idempotency record not found", zap.String("idempotency_key", key))
+		return nil, fmt.Errorf("idempotency record '%s': %w", key, ErrNotFound)
+	}
+
+	logger.Info("Idempotency record successfully retrieved", zap.String("idempotency_key", key))
+	return &record, nil
+}
+
+// transactWrite runs writes in a single TransactWriteItems call. When record
+// is not nil it is written too, holding created as the response, and a
+// record already stored for its key fails the call with
+// ErrIdempotencyKeyUsed.
+func transactWrite(ctx context.Context, db *dynamodb.Client, tableName string, writes []types.TransactWriteItem, record *model.IdempotencyRecord, created any) error {
+	if record != nil {
+		put, err := putIdempotencyRecord(tableName, record, created)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, put)
+	}
+
+	_, err := db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	if record != nil && conditionFailedAt(err, len(writes)-1) {
+		return fmt.Errorf("idempotency key '%s': %w", record.Key, ErrIdempotencyKeyUsed)
+	}
+	return err
+}
+
+// putIdempotencyRecord writes record unless a live one is stored for the
+// same key. Expired records may linger until the TTL deletes them and are
+// overwritten.
+func putIdempotencyRecord(tableName string, record *model.IdempotencyRecord, created any) (types.TransactWriteItem, error) {
+	if err := record.SetResponse(created); err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("failed to marshal idempotent response: %w", err)
+	}
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("failed to marshal idempotency record: %w", storageError(err))
+	}
+
+	condition := expression.AttributeNotExists(expression.Name("PK")).
+		Or(expression.Name(ttlAttribute).LessThanEqual(expression.Value(time.Now().Unix())))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("failed to build condition expression: %w", storageError(err))
+	}
+
+	return types.TransactWriteItem{Put: &types.Put{
+		TableName:                 aws.String(tableName),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}, nil
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MaxInstallments is the most installments a plan can have on any store.
+const MaxInstallments = 98
+
+// maxTransactItems is the most items a TransactWriteItems call accepts.
+const maxTransactItems = 100
+
+type InstallmentRepo struct {
+	db        *dynamodb.Client
+	tableName string
+}
+
+func NewInstallmentRepository(db *dynamodb.Client, tableName string) *InstallmentRepo {
+	return &InstallmentRepo{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// MaxInstallments is the most installments a plan fits on DynamoDB: the
+// plan, each installment, the aggregate of the month it falls in and the
+// idempotency record of the request are written in a single
+// TransactWriteItems call.
+func (r *InstallmentRepo) MaxInstallments() int {
+	return (maxTransactItems - 2) / 2
+}
+
+func (r *InstallmentRepo) NewInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction, record *model.IdempotencyRecord) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to create new installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	item, err := attributevalue.MarshalMap(plan)
+	if err != nil {
+		logger.Error("Failed to marshal installment plan", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to marshal installment plan: %w", storageError(err))
+	}
+
+	writes := []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String(r.tableName), Item: item}},
+	}
+	for i := range installments {
+		installments[i].Version = firstVersion
+		item, err := attributevalue.MarshalMap(&installments[i])
+		if err != nil {
+			logger.Error("Failed to marshal installment", err, zap.String("transaction_id", installments[i].ID))
+			return nil, fmt.Errorf("failed to marshal installment: %w", storageError(err))
+		}
+		writes = append(writes, types.TransactWriteItem{Put: &types.Put{
+			TableName:           aws.String(r.tableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(PK)"),
+		}})
+	}
+
+	counts := model.Aggregates{}
+	for i := range installments {
+		counts.Add(installments[i], 1)
+	}
+	aggregates, err := aggregateWrites(r.tableName, counts.List())
+	if err != nil {
+		logger.Error("Failed to build aggregate writes", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+	writes = append(writes, aggregates...)
+
+	// Callers check MaxInstallments first; this only guards the call.
+	items := len(writes)
+	if record != nil {
+		items++
+	}
+	if items > maxTransactItems {
+		logger.Error("Installment plan exceeds the transaction item limit", ErrValidation, zap.String("plan_id", plan.ID), zap.Int("items", items))
+		return nil, fmt.Errorf("installment plan with ID '%s' needs %d writes, at most %d fit in one transaction: %w", plan.ID, items, maxTransactItems, ErrValidation)
+	}
+
+	err = transactWrite(ctx, r.db, r.tableName, writes, record, plan)
+	if errors.Is(err, ErrIdempotencyKeyUsed) {
+		logger.Error("Idempotency key already used", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+	if isConditionCancellation(err) {
+		logger.Error("Installment already exists", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to add installment plan with ID '%s': %w", plan.ID, ErrTransactionExists)
+	}
+	if err != nil {
+		logger.Error("Failed to add installment plan to DynamoDB", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to add installment plan: %w", storageError(err))
+	}
+
+	logger.Info("Installment plan successfully created", zap.String("plan_id", plan.ID))
+	return plan, nil
+}
+
+func (r *InstallmentRepo) UpdateInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to update installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	transactions := r.transactions()
+	updated := make([]model.Transaction, len(installments))
+	counts := model.Aggregates{}
+	writes := []types.TransactWriteItem{}
+	for i := range installments {
+		updated[i] = installments[i]
+		updated[i].Version++
+		updated[i].SetKeys()
+		replace, err := transactions.replaceTransaction(&installments[i], &updated[i])
+		if err != nil {
+			return nil, err
+		}
+		writes = append(writes, replace...)
+		counts.Add(installments[i], -1)
+		counts.Add(updated[i], 1)
+	}
+
+	if err := r.writePlan(ctx, plan, writes, counts.List()); err != nil {
+		logger.Error("Installment plan not updated", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+	copy(installments, updated)
+
+	logger.Info("Installment plan successfully updated", zap.String("plan_id", plan.ID))
+	return plan, nil
+}
+
+func (r *InstallmentRepo) CancelInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to cancel installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	counts := model.Aggregates{}
+	writes := []types.TransactWriteItem{}
+	for i := range installments {
+		expr, err := expression.NewBuilder().WithCondition(versionCondition(installments[i].Version)).Build()
+		if err != nil {
+			logger.Error("Failed to build condition expression", err, zap.String("transaction_id", installments[i].ID))
+			return nil, fmt.Errorf("failed to build condition expression: %w", storageError(err))
+		}
+		writes = append(writes, types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                           aws.String(r.tableName),
+			Key:                                 installments[i].GetKey(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ConditionExpression:                 expr.Condition(),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}})
+		counts.Add(installments[i], -1)
+	}
+
+	if err := r.writePlan(ctx, plan, writes, counts.List()); err != nil {
+		logger.Error("Installment plan not cancelled", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+
+	logger.Info("Installment plan successfully cancelled", zap.String("plan_id", plan.ID))
+	return plan, nil
+}
+
+// ListInstallments looks each installment of plan up by the ID it was
+// created with, so those moved to another date are found too. Installments
+// deleted on their own are left out.
+func (r *InstallmentRepo) ListInstallments(ctx context.Context, plan *model.InstallmentPlan) ([]model.Transaction, error) {
+	logger.Info("Attempting to list installments", zap.String("plan_id", plan.ID), zap.Int("installments", plan.Installments))
+
+	installments := []model.Transaction{}
+	for n := 1; n <= plan.Installments; n++ {
+		id := model.InstallmentID(plan.ID, n)
+		resp, err := r.db.Query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			IndexName:              aws.String(GSI1),
+			KeyConditionExpression: aws.String("GSI1PK = :pk AND GSI1SK = :id"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: model.OwnerKey(plan.OwnerID)},
+				":id": &types.AttributeValueMemberS{Value: model.TransactionIDKey(id)},
+			},
+			Limit: aws.Int32(1),
+		})
+		if err != nil {
+			logger.Error("Failed to fetch installment from DynamoDB", err, zap.String("transaction_id", id))
+			return nil, fmt.Errorf("failed to get installment with ID '%s': %w", id, storageError(err))
+		}
+		if len(resp.Items) == 0 {
+			continue
+		}
+
+		var installment model.Transaction
+		if err := attributevalue.UnmarshalMap(resp.Items[0], &installment); err != nil {
+			logger.Error("Failed to unmarshal installment", err, zap.String("transaction_id", id))
+			return nil, fmt.Errorf("failed to unmarshal installment with ID '%s': %w", id, storageError(err))
+		}
+		installments = append(installments, installment)
+	}
+
+	logger.Info("Installments successfully retrieved", zap.String("plan_id", plan.ID), zap.Int("count", len(installments)))
+	return installments, nil
+}
+
+func (r *InstallmentRepo) ListInstallmentPlans(ctx context.Context, owner string) ([]model.InstallmentPlan, error) {
+	logger.Info("Attempting to list installment plans", zap.String("owner", owner))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: model.InstallmentPlanKey("")},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query installment plans from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query installment plans from table: %w", storageError(err))
+	}
+
+	plans := []model.InstallmentPlan{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &plans); err != nil {
+		logger.Error("Failed to unmarshal installment plans list", err)
+		return nil, fmt.Errorf("failed to unmarshal installment plans list: %w", storageError(err))
+	}
+
+	logger.Info("Installment plans successfully retrieved", zap.Int("count", len(plans)))
+	return plans, nil
+}
+
+func (r *InstallmentRepo) GetInstallmentPlanByID(ctx context.Context, owner string, id string) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to fetch installment plan", zap.String("owner", owner), zap.String("plan_id", id))
+
+	key := model.InstallmentPlan{OwnerID: owner, ID: id}
+	key.SetKeys()
+
+	response, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       key.GetKey(),
+	})
+	if err != nil {
+		logger.Error("Failed to fetch installment plan from DynamoDB", err, zap.String("plan_id", id))
+		return nil, fmt.Errorf("failed to get installment plan with ID '%s': %w", id, storageError(err))
+	}
+
+	if len(response.Item) == 0 {
+		logger.Error("Installment plan not found", ErrNotFound, zap.String("plan_id", id))
+		return nil, fmt.Errorf("installment plan with ID '%s': %w", id, ErrNotFound)
+	}
+
+	var plan model.InstallmentPlan
+	if err := attributevalue.UnmarshalMap(response.Item, &plan); err != nil {
+		logger.Error("Failed to unmarshal installment plan", err, zap.String("plan_id", id))
+		return nil, fmt.Errorf("failed to unmarshal installment plan with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Installment plan successfully retrieved", zap.String("plan_id", id))
+	return &plan, nil
+}
+
+// writePlan replaces the stored plan along with writes to its installments
+// and the aggregate changes they make, in one TransactWriteItems call. It
+// fails with ErrNotFound when the plan is gone and ErrVersionMismatch when
+// an installment changed since it was read.
+func (r *InstallmentRepo) writePlan(ctx context.Context, plan *model.InstallmentPlan, writes []types.TransactWriteItem, changes []model.MonthlyAggregate) error {
+	item, err := attributevalue.MarshalMap(plan)
+	if err != nil {
+		return fmt.Errorf("failed to marshal installment plan: %w", storageError(err))
+	}
+	counts, err := aggregateWrites(r.tableName, changes)
+	if err != nil {
+		return err
+	}
+
+	writes = append([]types.TransactWriteItem{{Put: &types.Put{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	}}}, writes...)
+	writes = append(writes, counts...)
+	if len(writes) > maxTransactItems {
+		return fmt.Errorf("installment plan with ID '%s' needs %d writes, at most %d fit in one transaction: %w", plan.ID, len(writes), maxTransactItems, ErrValidation)
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	if conditionFailedAt(err, 0) {
+		return fmt.Errorf("installment plan with ID '%s': %w", plan.ID, ErrNotFound)
+	}
+	if isConditionCancellation(err) {
+		return transactConflict(err, "installment plan", plan.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update installment plan with ID '%s': %w", plan.ID, storageError(err))
+	}
+	return nil
+}
+
+// transactions returns a transaction repository on the same table, whose
+// write builders the plan operations share.
+func (r *InstallmentRepo) transactions() *TransactionRepo {
+	return NewTransactionRepository(r.db, r.tableName)
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MemoryAccountRepo keeps accounts in process memory. It is safe for
+// concurrent use.
+type MemoryAccountRepo struct {
+	mu    sync.RWMutex
+	items map[string]map[string]model.Account
+}
+
+func NewMemoryAccountRepository() *MemoryAccountRepo {
+	return &MemoryAccountRepo{
+		items: map[string]map[string]model.Account{},
+	}
+}
+
+func (r *MemoryAccountRepo) NewAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to create new account", zap.String("account_id", account.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	partition, ok := r.items[account.PK]
+	if !ok {
+		partition = map[string]model.Account{}
+		r.items[account.PK] = partition
+	}
+	partition[account.SK] = *account
+
+	logger.Info("Account successfully created", zap.String("account_id", account.ID))
+	return account, nil
+}
+
+func (r *MemoryAccountRepo) UpdateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to update account", zap.String("account_id", account.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[account.PK][account.SK]
+	if !ok {
+		logger.Error("No account found to update", ErrNotFound, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("account with ID '%s': %w", account.ID, ErrNotFound)
+	}
+
+	stored.Name = account.Name
+	stored.Kind = account.Kind
+	stored.Currency = account.Currency
+	stored.OpeningBalance = account.OpeningBalance
+	stored.UpdatedAt = account.UpdatedAt
+	r.items[account.PK][account.SK] = stored
+
+	logger.Info("Account successfully updated", zap.String("account_id", account.ID))
+	return &stored, nil
+}
+
+func (r *MemoryAccountRepo) ListAccounts(ctx context.Context, owner string) ([]model.Account, error) {
+	logger.Info("Attempting to list accounts", zap.String("owner", owner))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := []model.Account{}
+	for _, account := range r.items[model.OwnerKey(owner)] {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].SK < accounts[j].SK })
+
+	logger.Info("Accounts successfully retrieved", zap.Int("count", len(accounts)))
+	return accounts, nil
+}
+
+func (r *MemoryAccountRepo) GetAccountByID(ctx context.Context, owner string, id string) (*model.Account, error) {
+	logger.Info("Attempting to fetch account", zap.String("owner", owner), zap.String("account_id", id))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.items[model.OwnerKey(owner)][model.AccountKey(id)]
+	if !ok {
+		logger.Error("Account not found", ErrNotFound, zap.String("account_id", id))
+		return nil, fmt.Errorf("account with ID '%s': %w", id, ErrNotFound)
+	}
+
+	logger.Info("Account successfully retrieved", zap.String("account_id", id))
+	return &stored, nil
+}
+
+func (r *MemoryAccountRepo) DeleteAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to delete account", zap.String("account_id", account.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[account.PK][account.SK]
+	if !ok {
+		logger.Error("No account found to delete", ErrNotFound, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("no account found to delete with ID '%s': %w", account.ID, ErrNotFound)
+	}
+	delete(r.items[account.PK], account.SK)
+
+	logger.Info("Account successfully deleted", zap.String("account_id", account.ID))
+	return &stored, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// ListMonthlyAggregates sums the stored transactions on every call, so the
+// aggregates of this backend never drift.
+func (r *MemoryTransactionRepo) ListMonthlyAggregates(ctx context.Context, owner string, startMonth string, endMonth string) ([]model.MonthlyAggregate, error) {
+	logger.Info("Attempting to list monthly aggregates", zap.String("owner", owner), zap.String("start_month", startMonth), zap.String("end_month", endMonth))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	aggregates := model.Aggregates{}
+	for _, transaction := range r.items[model.OwnerKey(owner)] {
+		month := transaction.OccurredAt.Format(model.MonthLayout)
+		if month >= startMonth && month <= endMonth {
+			aggregates.Add(transaction, 1)
+		}
+	}
+
+	list := aggregates.List()
+	logger.Info("Monthly aggregates successfully retrieved", zap.Int("count", len(list)))
+	return list, nil
+}
+
+// RebuildMonthlyAggregates has nothing to repair, the aggregates being
+// summed on read, and returns them all.
+func (r *MemoryTransactionRepo) RebuildMonthlyAggregates(ctx context.Context, owner string) ([]model.MonthlyAggregate, error) {
+	return r.ListMonthlyAggregates(ctx, owner, "0001-01", "9999-12")
+}
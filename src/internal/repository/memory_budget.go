@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MemoryBudgetRepo keeps budgets in process memory. It is safe for
+// concurrent use.
+type MemoryBudgetRepo struct {
+	mu    sync.RWMutex
+	items map[string]map[string]model.Budget
+}
+
+func NewMemoryBudgetRepository() *MemoryBudgetRepo {
+	return &MemoryBudgetRepo{
+		items: map[string]map[string]model.Budget{},
+	}
+}
+
+func (r *MemoryBudgetRepo) NewBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to create new budget", zap.String("budget_id", budget.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	partition, ok := r.items[budget.PK]
+	if !ok {
+		partition = map[string]model.Budget{}
+		r.items[budget.PK] = partition
+	}
+	partition[budget.SK] = copyBudget(*budget)
+
+	logger.Info("Budget successfully created", zap.String("budget_id", budget.ID))
+	return budget, nil
+}
+
+func (r *MemoryBudgetRepo) UpdateBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to update budget", zap.String("budget_id", budget.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[budget.PK][budget.SK]
+	if !ok {
+		logger.Error("No budget found to update", ErrNotFound, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("budget with ID '%s': %w", budget.ID, ErrNotFound)
+	}
+
+	stored.Name = budget.Name
+	stored.CategoryID = budget.CategoryID
+	stored.Type = budget.Type
+	stored.Limit = budget.Limit
+	stored.StartMonth = budget.StartMonth
+	stored.EndMonth = budget.EndMonth
+	stored.Rollover = budget.Rollover
+	stored.UpdatedAt = budget.UpdatedAt
+	r.items[budget.PK][budget.SK] = copyBudget(stored)
+
+	logger.Info("Budget successfully updated", zap.String("budget_id", budget.ID))
+	updated := copyBudget(stored)
+	return &updated, nil
+}
+
+func (r *MemoryBudgetRepo) ListBudgets(ctx context.Context, owner string) ([]model.Budget, error) {
+	logger.Info("Attempting to list budgets", zap.String("owner", owner))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	budgets := []model.Budget{}
+	for _, budget := range r.items[model.OwnerKey(owner)] {
+		budgets = append(budgets, copyBudget(budget))
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].SK < budgets[j].SK })
+
+	logger.Info("Budgets successfully retrieved", zap.Int("count", len(budgets)))
+	return budgets, nil
+}
+
+func (r *MemoryBudgetRepo) GetBudgetByID(ctx context.Context, owner string, id string) (*model.Budget, error) {
+	logger.Info("Attempting to fetch budget", zap.String("owner", owner), zap.String("budget_id", id))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.items[model.OwnerKey(owner)][model.BudgetKey(id)]
+	if !ok {
+		logger.Error("Budget not found", ErrNotFound, zap.String("budget_id", id))
+		return nil, fmt.Errorf("budget with ID '%s': %w", id, ErrNotFound)
+	}
+
+	budget := copyBudget(stored)
+	logger.Info("Budget successfully retrieved", zap.String("budget_id", id))
+	return &budget, nil
+}
+
+func (r *MemoryBudgetRepo) DeleteBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to delete budget", zap.String("budget_id", budget.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[budget.PK][budget.SK]
+	if !ok {
+		logger.Error("No budget found to delete", ErrNotFound, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("no budget found to delete with ID '%s': %w", budget.ID, ErrNotFound)
+	}
+	delete(r.items[budget.PK], budget.SK)
+
+	logger.Info("Budget successfully deleted", zap.String("budget_id", budget.ID))
+	return &stored, nil
+}
+
+func copyBudget(b model.Budget) model.Budget {
+	b.CategoryID = copyString(b.CategoryID)
+	b.EndMonth = copyString(b.EndMonth)
+	if b.Type != nil {
+		transactionType := *b.Type
+		b.Type = &transactionType
+	}
+	return b
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MemoryCategoryRepo keeps categories in process memory. It is safe for
+// concurrent use.
+type MemoryCategoryRepo struct {
+	mu    sync.RWMutex
+	items map[string]map[string]model.Category
+}
+
+func NewMemoryCategoryRepository() *MemoryCategoryRepo {
+	return &MemoryCategoryRepo{
+		items: map[string]map[string]model.Category{},
+	}
+}
+
+func (r *MemoryCategoryRepo) NewCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to create new category", zap.String("category_id", category.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	partition, ok := r.items[category.PK]
+	if !ok {
+		partition = map[string]model.Category{}
+		r.items[category.PK] = partition
+	}
+	partition[category.SK] = copyCategory(*category)
+
+	logger.Info("Category successfully created", zap.String("category_id", category.ID))
+	return category, nil
+}
+
+func (r *MemoryCategoryRepo) UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to update category", zap.String("category_id", category.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[category.PK][category.SK]
+	if !ok {
+		logger.Error("No category found to update", ErrNotFound, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("category with ID '%s': %w", category.ID, ErrNotFound)
+	}
+
+	stored.Name = category.Name
+	stored.Color = category.Color
+	stored.Icon = category.Icon
+	stored.ParentID = category.ParentID
+	stored.UpdatedAt = category.UpdatedAt
+	r.items[category.PK][category.SK] = copyCategory(stored)
+
+	logger.Info("Category successfully updated", zap.String("category_id", category.ID))
+	updated := copyCategory(stored)
+	return &updated, nil
+}
+
+func (r *MemoryCategoryRepo) ListCategories(ctx context.Context, owner string) ([]model.Category, error) {
+	logger.Info("Attempting to list categories", zap.String("owner", owner))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := []model.Category{}
+	for _, category := range r.items[model.OwnerKey(owner)] {
+		categories = append(categories, copyCategory(category))
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].SK < categories[j].SK })
+
+	logger.Info("Categories successfully retrieved", zap.Int("count", len(categories)))
+	return categories, nil
+}
+
+func (r *MemoryCategoryRepo) GetCategoryByID(ctx context.Context, owner string, id string) (*model.Category, error) {
+	logger.Info("Attempting to fetch category", zap.String("owner", owner), zap.String("category_id", id))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.items[model.OwnerKey(owner)][model.CategoryKey(id)]
+	if !ok {
+		logger.Error("Category not found", ErrNotFound, zap.String("category_id", id))
+		return nil, fmt.Errorf("category with ID '%s': %w", id, ErrNotFound)
+	}
+
+	category := copyCategory(stored)
+	logger.Info("Category successfully retrieved", zap.String("category_id", id))
+	return &category, nil
+}
+
+func (r *MemoryCategoryRepo) DeleteCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to delete category", zap.String("category_id", category.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[category.PK][category.SK]
+	if !ok {
+		logger.Error("No category found to delete", ErrNotFound, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("no category found to delete with ID '%s': %w", category.ID, ErrNotFound)
+	}
+	delete(r.items[category.PK], category.SK)
+
+	logger.Info("Category successfully deleted", zap.String("category_id", category.ID))
+	return &stored, nil
+}
+
+func copyCategory(c model.Category) model.Category {
+	c.Color = copyString(c.Color)
+	c.Icon = copyString(c.Icon)
+	c.ParentID = copyString(c.ParentID)
+	return c
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MemoryExchangeRateRepo keeps exchange rates in process memory. It is safe
+// for concurrent use.
+type MemoryExchangeRateRepo struct {
+	mu    sync.RWMutex
+	items map[string]map[string]model.ExchangeRate
+}
+
+func NewMemoryExchangeRateRepository() *MemoryExchangeRateRepo {
+	return &MemoryExchangeRateRepo{
+		items: map[string]map[string]model.ExchangeRate{},
+	}
+}
+
+func (r *MemoryExchangeRateRepo) PutExchangeRates(ctx context.Context, rates []model.ExchangeRate) ([]model.ExchangeRate, error) {
+	logger.Info("Attempting to store exchange rates", zap.Int("count", len(rates)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rate := range rates {
+		partition, ok := r.items[rate.PK]
+		if !ok {
+			partition = map[string]model.ExchangeRate{}
+			r.items[rate.PK] = partition
+		}
+		partition[rate.SK] = rate
+	}
+
+	logger.Info("Exchange rates successfully stored", zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (r *MemoryExchangeRateRepo) ListExchangeRates(ctx context.Context, owner string) ([]model.ExchangeRate, error) {
+	logger.Info("Attempting to list exchange rates", zap.String("owner", owner))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rates := []model.ExchangeRate{}
+	for _, rate := range r.items[model.OwnerKey(owner)] {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].SK < rates[j].SK })
+
+	logger.Info("Exchange rates successfully retrieved", zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (r *MemoryExchangeRateRepo) DeleteExchangeRate(ctx context.Context, rate *model.ExchangeRate) (*model.ExchangeRate, error) {
+	logger.Info("Attempting to delete exchange rate", zap.String("sk", rate.SK))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[rate.PK][rate.SK]
+	if !ok {
+		logger.Error("No exchange rate found to delete", ErrNotFound, zap.String("sk", rate.SK))
+		return nil, fmt.Errorf("no exchange rate found to delete for %s/%s on %s: %w", rate.Base, rate.Quote, rate.Date, ErrNotFound)
+	}
+	delete(r.items[rate.PK], rate.SK)
+
+	logger.Info("Exchange rate successfully deleted", zap.String("sk", rate.SK))
+	return &stored, nil
+}
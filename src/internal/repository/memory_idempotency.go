@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+func (r *MemoryTransactionRepo) GetIdempotencyRecord(ctx context.Context, owner string, key string) (*model.IdempotencyRecord, error) {
+	logger.Info("Attempting to fetch idempotency record", zap.String("owner", owner), zap.String("idempotency_key", key))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[model.OwnerKey(owner)][model.IdempotencyKey(key)]
+	if !ok || record.Expired(time.Now()) {
+		logger.Info("Idempotency record not found", zap.String("idempotency_key", key))
+		return nil, fmt.Errorf("idempotency record '%s': %w", key, ErrNotFound)
+	}
+
+	logger.Info("Idempotency record successfully retrieved", zap.String("idempotency_key", key))
+	return &record, nil
+}
+
+// checkRecord fails with ErrIdempotencyKeyUsed when a live record is stored
+// for the key of record. A nil record passes. The caller must hold r.mu.
+func (r *MemoryTransactionRepo) checkRecord(record *model.IdempotencyRecord) error {
+	if record == nil {
+		return nil
+	}
+	if stored, ok := r.records[record.PK][record.SK]; ok && !stored.Expired(time.Now()) {
+		return fmt.Errorf("idempotency key '%s': %w", record.Key, ErrIdempotencyKeyUsed)
+	}
+	return nil
+}
+
+// storeRecord saves record, when there is one, holding created as the
+// response. The caller must hold r.mu and have passed checkRecord.
+func (r *MemoryTransactionRepo) storeRecord(record *model.IdempotencyRecord, created any) error {
+	if record == nil {
+		return nil
+	}
+	if err := record.SetResponse(created); err != nil {
+		return fmt.Errorf("failed to marshal idempotent response: %w", err)
+	}
+
+	partition, ok := r.records[record.PK]
+	if !ok {
+		partition = map[string]model.IdempotencyRecord{}
+		r.records[record.PK] = partition
+	}
+	partition[record.SK] = *record
+	return nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MemoryInstallmentRepo keeps installment plans in process memory and
+// writes their installments straight into transactions, holding both locks
+// so a plan is stored all or nothing. It is safe for concurrent use.
+type MemoryInstallmentRepo struct {
+	mu           sync.RWMutex
+	items        map[string]map[string]model.InstallmentPlan
+	transactions *MemoryTransactionRepo
+}
+
+func NewMemoryInstallmentRepository(transactions *MemoryTransactionRepo) *MemoryInstallmentRepo {
+	return &MemoryInstallmentRepo{
+		items:        map[string]map[string]model.InstallmentPlan{},
+		transactions: transactions,
+	}
+}
+
+func (r *MemoryInstallmentRepo) MaxInstallments() int {
+	return MaxInstallments
+}
+
+func (r *MemoryInstallmentRepo) NewInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction, record *model.IdempotencyRecord) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to create new installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transactions.mu.Lock()
+	defer r.transactions.mu.Unlock()
+
+	if err := r.transactions.checkRecord(record); err != nil {
+		logger.Error("Idempotency key already used", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+	for _, installment := range installments {
+		if _, exists := r.transactions.items[installment.PK][installment.SK]; exists {
+			logger.Error("Installment already exists", ErrTransactionExists, zap.String("transaction_id", installment.ID))
+			return nil, fmt.Errorf("failed to add installment plan: transaction with ID '%s': %w", installment.ID, ErrTransactionExists)
+		}
+	}
+	for i := range installments {
+		installments[i].Version = firstVersion
+	}
+	if err := r.transactions.storeRecord(record, plan); err != nil {
+		logger.Error("Failed to store idempotency record", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+
+	for i := range installments {
+		installment := &installments[i]
+		partition, ok := r.transactions.items[installment.PK]
+		if !ok {
+			partition = map[string]model.Transaction{}
+			r.transactions.items[installment.PK] = partition
+		}
+		partition[installment.SK] = copyTransaction(*installment)
+	}
+
+	partition, ok := r.items[plan.PK]
+	if !ok {
+		partition = map[string]model.InstallmentPlan{}
+		r.items[plan.PK] = partition
+	}
+	partition[plan.SK] = copyInstallmentPlan(*plan)
+
+	logger.Info("Installment plan successfully created", zap.String("plan_id", plan.ID))
+	return plan, nil
+}
+
+func (r *MemoryInstallmentRepo) UpdateInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to update installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transactions.mu.Lock()
+	defer r.transactions.mu.Unlock()
+
+	if err := r.checkPlan(plan, installments); err != nil {
+		logger.Error("Installment plan not updated", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+	for i := range installments {
+		sk := installments[i].SK
+		installments[i].Version++
+		installments[i] = r.transactions.replace(sk, installments[i])
+	}
+	r.items[plan.PK][plan.SK] = copyInstallmentPlan(*plan)
+
+	logger.Info("Installment plan successfully updated", zap.String("plan_id", plan.ID))
+	return plan, nil
+}
+
+func (r *MemoryInstallmentRepo) CancelInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to cancel installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transactions.mu.Lock()
+	defer r.transactions.mu.Unlock()
+
+	if err := r.checkPlan(plan, installments); err != nil {
+		logger.Error("Installment plan not cancelled", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+	for _, installment := range installments {
+		delete(r.transactions.items[installment.PK], installment.SK)
+	}
+	r.items[plan.PK][plan.SK] = copyInstallmentPlan(*plan)
+
+	logger.Info("Installment plan successfully cancelled", zap.String("plan_id", plan.ID))
+	return plan, nil
+}
+
+func (r *MemoryInstallmentRepo) ListInstallments(ctx context.Context, plan *model.InstallmentPlan) ([]model.Transaction, error) {
+	logger.Info("Attempting to list installments", zap.String("plan_id", plan.ID), zap.Int("installments", plan.Installments))
+
+	r.transactions.mu.RLock()
+	defer r.transactions.mu.RUnlock()
+
+	installments := []model.Transaction{}
+	for _, transaction := range r.transactions.items[plan.PK] {
+		if transaction.ParentID != nil && *transaction.ParentID == plan.ID {
+			installments = append(installments, copyTransaction(transaction))
+		}
+	}
+	sort.Slice(installments, func(i, j int) bool { return installments[i].Installment < installments[j].Installment })
+
+	logger.Info("Installments successfully retrieved", zap.String("plan_id", plan.ID), zap.Int("count", len(installments)))
+	return installments, nil
+}
+
+func (r *MemoryInstallmentRepo) ListInstallmentPlans(ctx context.Context, owner string) ([]model.InstallmentPlan, error) {
+	logger.Info("Attempting to list installment plans", zap.String("owner", owner))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plans := []model.InstallmentPlan{}
+	for _, plan := range r.items[model.OwnerKey(owner)] {
+		plans = append(plans, copyInstallmentPlan(plan))
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].SK < plans[j].SK })
+
+	logger.Info("Installment plans successfully retrieved", zap.Int("count", len(plans)))
+	return plans, nil
+}
+
+func (r *MemoryInstallmentRepo) GetInstallmentPlanByID(ctx context.Context, owner string, id string) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to fetch installment plan", zap.String("owner", owner), zap.String("plan_id", id))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.items[model.OwnerKey(owner)][model.InstallmentPlanKey(id)]
+	if !ok {
+		logger.Error("Installment plan not found", ErrNotFound, zap.String("plan_id", id))
+		return nil, fmt.Errorf("installment plan with ID '%s': %w", id, ErrNotFound)
+	}
+
+	plan := copyInstallmentPlan(stored)
+	logger.Info("Installment plan successfully retrieved", zap.String("plan_id", id))
+	return &plan, nil
+}
+
+// checkPlan ensures plan is stored and each of installments still has the
+// version it names. The caller must hold both locks.
+func (r *MemoryInstallmentRepo) checkPlan(plan *model.InstallmentPlan, installments []model.Transaction) error {
+	if _, ok := r.items[plan.PK][plan.SK]; !ok {
+		return fmt.Errorf("installment plan with ID '%s': %w", plan.ID, ErrNotFound)
+	}
+	for i := range installments {
+		if _, err := r.transactions.versioned(&installments[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyInstallmentPlan(p model.InstallmentPlan) model.InstallmentPlan {
+	p.Description = copyString(p.Description)
+	p.CategoryID = copyString(p.CategoryID)
+	p.AccountID = copyString(p.AccountID)
+	if p.Tags != nil {
+		p.Tags = append([]string(nil), p.Tags...)
+	}
+	if p.CancelledAt != nil {
+		cancelledAt := *p.CancelledAt
+		p.CancelledAt = &cancelledAt
+	}
+	p.Transactions = nil
+	return p
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MemoryRecurrenceRepo keeps recurrences in process memory. It is safe for
+// concurrent use.
+type MemoryRecurrenceRepo struct {
+	mu    sync.RWMutex
+	items map[string]map[string]model.Recurrence
+}
+
+func NewMemoryRecurrenceRepository() *MemoryRecurrenceRepo {
+	return &MemoryRecurrenceRepo{
+		items: map[string]map[string]model.Recurrence{},
+	}
+}
+
+func (r *MemoryRecurrenceRepo) NewRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to create new recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	partition, ok := r.items[recurrence.PK]
+	if !ok {
+		partition = map[string]model.Recurrence{}
+		r.items[recurrence.PK] = partition
+	}
+	recurrence.Version = firstVersion
+	partition[recurrence.SK] = copyRecurrence(*recurrence)
+
+	logger.Info("Recurrence successfully created", zap.String("recurrence_id", recurrence.ID))
+	return recurrence, nil
+}
+
+func (r *MemoryRecurrenceRepo) UpdateRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to update recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[recurrence.PK][recurrence.SK]
+	if !ok {
+		logger.Error("No recurrence found to update", ErrNotFound, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("recurrence with ID '%s': %w", recurrence.ID, ErrNotFound)
+	}
+	if stored.Version != recurrence.Version {
+		err := fmt.Errorf("recurrence with ID '%s': %w", recurrence.ID, ErrVersionMismatch)
+		logger.Error("Recurrence not updated", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, err
+	}
+	updated := copyRecurrence(*recurrence)
+	updated.Version++
+	r.items[recurrence.PK][recurrence.SK] = updated
+
+	logger.Info("Recurrence successfully updated", zap.String("recurrence_id", recurrence.ID))
+	updated = copyRecurrence(updated)
+	return &updated, nil
+}
+
+func (r *MemoryRecurrenceRepo) ListRecurrences(ctx context.Context, owner string) ([]model.Recurrence, error) {
+	logger.Info("Attempting to list recurrences", zap.String("owner", owner))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recurrences := []model.Recurrence{}
+	for _, recurrence := range r.items[model.OwnerKey(owner)] {
+		recurrences = append(recurrences, copyRecurrence(recurrence))
+	}
+	sort.Slice(recurrences, func(i, j int) bool { return recurrences[i].SK < recurrences[j].SK })
+
+	logger.Info("Recurrences successfully retrieved", zap.Int("count", len(recurrences)))
+	return recurrences, nil
+}
+
+func (r *MemoryRecurrenceRepo) GetRecurrenceByID(ctx context.Context, owner string, id string) (*model.Recurrence, error) {
+	logger.Info("Attempting to fetch recurrence", zap.String("owner", owner), zap.String("recurrence_id", id))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.items[model.OwnerKey(owner)][model.RecurrenceKey(id)]
+	if !ok {
+		logger.Error("Recurrence not found", ErrNotFound, zap.String("recurrence_id", id))
+		return nil, fmt.Errorf("recurrence with ID '%s': %w", id, ErrNotFound)
+	}
+
+	recurrence := copyRecurrence(stored)
+	logger.Info("Recurrence successfully retrieved", zap.String("recurrence_id", id))
+	return &recurrence, nil
+}
+
+func (r *MemoryRecurrenceRepo) DeleteRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to delete recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[recurrence.PK][recurrence.SK]
+	if !ok {
+		logger.Error("No recurrence found to delete", ErrNotFound, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("no recurrence found to delete with ID '%s': %w", recurrence.ID, ErrNotFound)
+	}
+	delete(r.items[recurrence.PK], recurrence.SK)
+
+	logger.Info("Recurrence successfully deleted", zap.String("recurrence_id", recurrence.ID))
+	return &stored, nil
+}
+
+func (r *MemoryRecurrenceRepo) ListDueRecurrences(ctx context.Context, date string) ([]model.Recurrence, error) {
+	logger.Info("Attempting to list due recurrences", zap.String("date", date))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recurrences := []model.Recurrence{}
+	for _, partition := range r.items {
+		for _, recurrence := range partition {
+			if recurrence.NextOccurrence != nil && *recurrence.NextOccurrence <= date {
+				recurrences = append(recurrences, copyRecurrence(recurrence))
+			}
+		}
+	}
+	sort.Slice(recurrences, func(i, j int) bool { return recurrences[i].GSI1SK < recurrences[j].GSI1SK })
+
+	logger.Info("Due recurrences successfully retrieved", zap.Int("count", len(recurrences)))
+	return recurrences, nil
+}
+
+func copyRecurrence(r model.Recurrence) model.Recurrence {
+	r.NextOccurrence = copyString(r.NextOccurrence)
+	r.Description = copyString(r.Description)
+	r.CategoryID = copyString(r.CategoryID)
+	r.AccountID = copyString(r.AccountID)
+	if r.Tags != nil {
+		r.Tags = append([]string(nil), r.Tags...)
+	}
+	return r
+}
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// MemoryTransactionRepo keeps transactions in process memory using the same
+// PK/SK layout as the DynamoDB table. It is safe for concurrent use.
+type MemoryTransactionRepo struct {
+	mu      sync.RWMutex
+	items   map[string]map[string]model.Transaction
+	records map[string]map[string]model.IdempotencyRecord
+}
+
+func NewMemoryTransactionRepository() *MemoryTransactionRepo {
+	return &MemoryTransactionRepo{
+		items:   map[string]map[string]model.Transaction{},
+		records: map[string]map[string]model.IdempotencyRecord{},
+	}
+}
+
+func (r *MemoryTransactionRepo) NewTransaction(ctx context.Context, transaction *model.Transaction, record *model.IdempotencyRecord) (*model.Transaction, error) {
+	logger.Info("Attempting to create new transaction", zap.String("transaction_id", transaction.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkRecord(record); err != nil {
+		logger.Error("Idempotency key already used", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	partition, ok := r.items[transaction.PK]
+	if !ok {
+		partition = map[string]model.Transaction{}
+		r.items[transaction.PK] = partition
+	}
+	if _, exists := partition[transaction.SK]; exists {
+		logger.Error("Transaction already exists", ErrTransactionExists, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("transaction with ID '%s': %w", transaction.ID, ErrTransactionExists)
+	}
+	transaction.Version = firstVersion
+	if err := r.storeRecord(record, transaction); err != nil {
+		logger.Error("Failed to store idempotency record", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	partition[transaction.SK] = copyTransaction(*transaction)
+
+	logger.Info("Transaction successfully created", zap.String("transaction_id", transaction.ID))
+	return transaction, nil
+}
+
+func (r *MemoryTransactionRepo) UpdateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	logger.Info("Attempting to update transaction", zap.String("transaction_id", transaction.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.versioned(transaction)
+	if err != nil {
+		logger.Error("Transaction not updated", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+
+	applyUpdate(&stored, transaction)
+	stored.Version++
+	stored = r.replace(transaction.SK, stored)
+
+	logger.Info("Transaction successfully updated", zap.String("transaction_id", transaction.ID))
+	updated := copyTransaction(stored)
+	return &updated, nil
+}
+
+func (r *MemoryTransactionRepo) PatchTransaction(ctx context.Context, transaction *model.Transaction, patch *model.TransactionPatch) (*model.Transaction, error) {
+	logger.Info("Attempting to patch transaction", zap.String("transaction_id", transaction.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.versioned(transaction)
+	if err != nil {
+		logger.Error("Transaction not patched", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+
+	patch.ApplyTo(&stored)
+	stored.Version++
+	stored = r.replace(transaction.SK, stored)
+
+	logger.Info("Transaction successfully patched", zap.String("transaction_id", transaction.ID))
+	patched := copyTransaction(stored)
+	return &patched, nil
+}
+
+func (r *MemoryTransactionRepo) ListTransactions(ctx context.Context, owner string, startDate string, endDate string, options ListOptions) (*TransactionPage, error) {
+	logger.Info("Attempting to list transactions", zap.String("owner", owner), zap.String("startDate", startDate), zap.String("endDate", endDate))
+
+	startSK := fmt.Sprintf("CREATEDAT#%s#", startDate)
+	endSK := fmt.Sprintf("CREATEDAT#%s#z", endDate)
+	afterSK := options.StartKey["SK"]
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []model.Transaction{}
+	for sk, transaction := range r.items[model.OwnerKey(owner)] {
+		if options.AccountID != "" && (transaction.AccountID == nil || *transaction.AccountID != options.AccountID) {
+			continue
+		}
+		if options.Tag != "" && !model.HasTag(transaction.Tags, options.Tag) {
+			continue
+		}
+		if sk >= startSK && sk <= endSK && sk > afterSK {
+			entries = append(entries, copyTransaction(transaction))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].SK < entries[j].SK })
+
+	page := &TransactionPage{Items: entries}
+	if options.Limit > 0 && len(entries) > options.Limit {
+		page.Items = entries[:options.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextKey = PageKey{"PK": last.PK, "SK": last.SK}
+	}
+
+	logger.Info("Transactions successfully retrieved", zap.Int("count", len(page.Items)))
+	return page, nil
+}
+
+func (r *MemoryTransactionRepo) GetTransactionByID(ctx context.Context, owner string, id string, occurredAt string) (*model.Transaction, error) {
+	logger.Info("Attempting to fetch transaction",
+		zap.String("owner", owner),
+		zap.String("transaction_id", id),
+		zap.String("occurred_at", occurredAt),
+	)
+
+	prefix := model.TransactionDateKey(occurredAt, id)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []string
+	for sk, transaction := range r.items[model.OwnerKey(owner)] {
+		if occurredAt == "" && transaction.ID == id || occurredAt != "" && strings.HasPrefix(sk, prefix) {
+			keys = append(keys, sk)
+		}
+	}
+
+	if len(keys) == 0 {
+		logger.Error("Transaction not found", ErrNotFound,
+			zap.String("transaction_id", id),
+			zap.String("occurred_at", occurredAt),
+		)
+		return nil, fmt.Errorf("transaction with ID '%s': %w", id, ErrNotFound)
+	}
+	sort.Strings(keys)
+
+	transaction := copyTransaction(r.items[model.OwnerKey(owner)][keys[0]])
+
+	logger.Info("Transaction successfully retrieved",
+		zap.String("transaction_id", id),
+		zap.String("occurred_at", occurredAt),
+	)
+	return &transaction, nil
+}
+
+func (r *MemoryTransactionRepo) DeleteTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	logger.Info("Attempting to delete transaction", zap.String("transaction_id", transaction.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.versioned(transaction)
+	if err != nil {
+		logger.Error("Transaction not deleted", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	delete(r.items[transaction.PK], transaction.SK)
+
+	logger.Info("Transaction successfully deleted", zap.String("transaction_id", transaction.ID))
+	return &stored, nil
+}
+
+func (r *MemoryTransactionRepo) ReassignCategory(ctx context.Context, owner string, from string, to *string) (int, error) {
+	logger.Info("Attempting to reassign category", zap.String("owner", owner), zap.String("category_id", from))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	partition := r.items[model.OwnerKey(owner)]
+	for sk, transaction := range partition {
+		if transaction.CategoryID == nil || *transaction.CategoryID != from {
+			continue
+		}
+		transaction.CategoryID = copyString(to)
+		transaction.Version++
+		partition[sk] = transaction
+		count++
+	}
+
+	logger.Info("Category successfully reassigned", zap.String("category_id", from), zap.Int("count", count))
+	return count, nil
+}
+
+func (r *MemoryTransactionRepo) ListTags(ctx context.Context, owner string) ([]model.TagUsage, error) {
+	logger.Info("Attempting to list tags", zap.String("owner", owner))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transactions := make([]model.Transaction, 0, len(r.items[model.OwnerKey(owner)]))
+	for _, transaction := range r.items[model.OwnerKey(owner)] {
+		transactions = append(transactions, transaction)
+	}
+
+	usage := countTags(transactions)
+	logger.Info("Tags successfully retrieved", zap.Int("count", len(usage)))
+	return usage, nil
+}
+
+func (r *MemoryTransactionRepo) RenameTag(ctx context.Context, owner string, from string, to string) (int, error) {
+	logger.Info("Attempting to rename tag", zap.String("owner", owner), zap.String("from", from), zap.String("to", to))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	partition := r.items[model.OwnerKey(owner)]
+	for sk, transaction := range partition {
+		if !model.HasTag(transaction.Tags, from) {
+			continue
+		}
+		transaction.Tags = model.RenameTag(transaction.Tags, from, to)
+		transaction.Version++
+		partition[sk] = transaction
+		count++
+	}
+
+	logger.Info("Tag successfully renamed", zap.String("from", from), zap.String("to", to), zap.Int("count", count))
+	return count, nil
+}
+
+// versioned returns the stored copy of transaction when it still has the
+// version transaction names. The caller must hold r.mu.
+func (r *MemoryTransactionRepo) versioned(transaction *model.Transaction) (model.Transaction, error) {
+	stored, ok := r.items[transaction.PK][transaction.SK]
+	if !ok {
+		return model.Transaction{}, fmt.Errorf("transaction with ID '%s': %w", transaction.ID, ErrNotFound)
+	}
+	if stored.Version != transaction.Version {
+		return model.Transaction{}, fmt.Errorf("transaction with ID '%s': %w", transaction.ID, ErrVersionMismatch)
+	}
+	return stored, nil
+}
+
+// replace stores transaction in place of the item under sk, filed under the
+// key of its date, which differs from sk once the date changed. It returns
+// transaction with its new keys. The caller must hold r.mu.
+func (r *MemoryTransactionRepo) replace(sk string, transaction model.Transaction) model.Transaction {
+	delete(r.items[transaction.PK], sk)
+	transaction.SetKeys()
+	r.items[transaction.PK][transaction.SK] = copyTransaction(transaction)
+	return transaction
+}
+
+// copyTransaction detaches pointer fields so callers cannot mutate stored
+// state through a returned value.
+func copyTransaction(t model.Transaction) model.Transaction {
+	t.Description = copyString(t.Description)
+	t.CategoryID = copyString(t.CategoryID)
+	t.AccountID = copyString(t.AccountID)
+	t.RecurrenceID = copyString(t.RecurrenceID)
+	t.ParentID = copyString(t.ParentID)
+	t.CounterpartID = copyString(t.CounterpartID)
+	if t.Tags != nil {
+		t.Tags = append([]string(nil), t.Tags...)
+	}
+	return t
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	value := *s
+	return &value
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+func (r *MemoryTransactionRepo) NewTransfer(ctx context.Context, transfer *model.Transfer, record *model.IdempotencyRecord) (*model.Transfer, error) {
+	logger.Info("Attempting to create new transfer", zap.String("transaction_id", transfer.From.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkRecord(record); err != nil {
+		logger.Error("Idempotency key already used", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+	transfer.From.Version = firstVersion
+	transfer.To.Version = firstVersion
+	for _, leg := range []model.Transaction{transfer.From, transfer.To} {
+		if _, exists := r.items[leg.PK][leg.SK]; exists {
+			logger.Error("Transfer already exists", ErrTransactionExists, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("transfer with ID '%s': %w", transfer.From.ID, ErrTransactionExists)
+		}
+	}
+	if err := r.storeRecord(record, transfer); err != nil {
+		logger.Error("Failed to store idempotency record", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+	for _, leg := range []model.Transaction{transfer.From, transfer.To} {
+		partition, ok := r.items[leg.PK]
+		if !ok {
+			partition = map[string]model.Transaction{}
+			r.items[leg.PK] = partition
+		}
+		partition[leg.SK] = copyTransaction(leg)
+	}
+
+	logger.Info("Transfer successfully created", zap.String("transaction_id", transfer.From.ID))
+	return transfer, nil
+}
+
+func (r *MemoryTransactionRepo) UpdateTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error) {
+	logger.Info("Attempting to update transfer", zap.String("transaction_id", transfer.From.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkTransfer(transfer); err != nil {
+		logger.Error("Transfer not updated", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+	updated := *transfer
+	updated.From.Version++
+	updated.To.Version++
+	updated.From = r.replace(transfer.From.SK, updated.From)
+	updated.To = r.replace(transfer.To.SK, updated.To)
+
+	logger.Info("Transfer successfully updated", zap.String("transaction_id", transfer.From.ID))
+	return &updated, nil
+}
+
+func (r *MemoryTransactionRepo) DeleteTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error) {
+	logger.Info("Attempting to delete transfer", zap.String("transaction_id", transfer.From.ID))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkTransfer(transfer); err != nil {
+		logger.Error("Transfer not deleted", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+	for _, leg := range []model.Transaction{transfer.From, transfer.To} {
+		delete(r.items[leg.PK], leg.SK)
+	}
+
+	logger.Info("Transfer successfully deleted", zap.String("transaction_id", transfer.From.ID))
+	return transfer, nil
+}
+
+// checkTransfer ensures both legs of transfer are stored with the versions
+// it names. The caller must hold r.mu.
+func (r *MemoryTransactionRepo) checkTransfer(transfer *model.Transfer) error {
+	for _, leg := range []*model.Transaction{&transfer.From, &transfer.To} {
+		stored, ok := r.items[leg.PK][leg.SK]
+		if !ok {
+			return fmt.Errorf("transfer with ID '%s': %w", transfer.From.ID, ErrNotFound)
+		}
+		if stored.Version != leg.Version {
+			return fmt.Errorf("transfer with ID '%s': %w", transfer.From.ID, ErrVersionMismatch)
+		}
+	}
+	return nil
+}
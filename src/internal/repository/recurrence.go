@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+type RecurrenceRepo struct {
+	db        *dynamodb.Client
+	tableName string
+}
+
+func NewRecurrenceRepository(db *dynamodb.Client, tableName string) *RecurrenceRepo {
+	return &RecurrenceRepo{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+func (r *RecurrenceRepo) NewRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to create new recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	recurrence.Version = firstVersion
+	item, err := attributevalue.MarshalMap(recurrence)
+	if err != nil {
+		logger.Error("Failed to marshal recurrence", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to marshal recurrence: %w", storageError(err))
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		logger.Error("Failed to add recurrence to DynamoDB", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to add recurrence: %w", storageError(err))
+	}
+
+	logger.Info("Recurrence successfully created", zap.String("recurrence_id", recurrence.ID))
+	return recurrence, nil
+}
+
+// UpdateRecurrence replaces the whole item rather than updating it in
+// place, so the GSI1 attributes disappear once the rule has ended. The
+// stored item must still have the version recurrence holds.
+func (r *RecurrenceRepo) UpdateRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to update recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	updated := *recurrence
+	updated.Version++
+	item, err := attributevalue.MarshalMap(&updated)
+	if err != nil {
+		logger.Error("Failed to marshal recurrence", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to marshal recurrence: %w", storageError(err))
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(versionCondition(recurrence.Version)).Build()
+	if err != nil {
+		logger.Error("Failed to build condition expression", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to build condition expression: %w", storageError(err))
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                           aws.String(r.tableName),
+		Item:                                item,
+		ExpressionAttributeNames:            expr.Names(),
+		ExpressionAttributeValues:           expr.Values(),
+		ConditionExpression:                 expr.Condition(),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		err = versionConflict("recurrence", recurrence.ID, conditionErr.Item)
+		logger.Error("Recurrence not updated", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to update recurrence in DynamoDB", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to update recurrence with ID '%s': %w", recurrence.ID, storageError(err))
+	}
+
+	logger.Info("Recurrence successfully updated", zap.String("recurrence_id", recurrence.ID))
+	return &updated, nil
+}
+
+func (r *RecurrenceRepo) ListRecurrences(ctx context.Context, owner string) ([]model.Recurrence, error) {
+	logger.Info("Attempting to list recurrences", zap.String("owner", owner))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: model.RecurrenceKey("")},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query recurrences from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query recurrences from table: %w", storageError(err))
+	}
+
+	recurrences := []model.Recurrence{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &recurrences); err != nil {
+		logger.Error("Failed to unmarshal recurrences list", err)
+		return nil, fmt.Errorf("failed to unmarshal recurrences list: %w", storageError(err))
+	}
+
+	logger.Info("Recurrences successfully retrieved", zap.Int("count", len(recurrences)))
+	return recurrences, nil
+}
+
+func (r *RecurrenceRepo) GetRecurrenceByID(ctx context.Context, owner string, id string) (*model.Recurrence, error) {
+	logger.Info("Attempting to fetch recurrence", zap.String("owner", owner), zap.String("recurrence_id", id))
+
+	key := model.Recurrence{OwnerID: owner, ID: id}
+	key.SetKeys()
+
+	response, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       key.GetKey(),
+	})
+	if err != nil {
+		logger.Error("Failed to fetch recurrence from DynamoDB", err, zap.String("recurrence_id", id))
+		return nil, fmt.Errorf("failed to get recurrence with ID '%s': %w", id, storageError(err))
+	}
+
+	if len(response.Item) == 0 {
+		logger.Error("Recurrence not found", ErrNotFound, zap.String("recurrence_id", id))
+		return nil, fmt.Errorf("recurrence with ID '%s': %w", id, ErrNotFound)
+	}
+
+	var recurrence model.Recurrence
+	if err := attributevalue.UnmarshalMap(response.Item, &recurrence); err != nil {
+		logger.Error("Failed to unmarshal recurrence", err, zap.String("recurrence_id", id))
+		return nil, fmt.Errorf("failed to unmarshal recurrence with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Recurrence successfully retrieved", zap.String("recurrence_id", id))
+	return &recurrence, nil
+}
+
+func (r *RecurrenceRepo) DeleteRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to delete recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	response, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.tableName),
+		Key:          recurrence.GetKey(),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logger.Error("Failed to delete recurrence from DynamoDB", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to delete recurrence with ID '%s': %w", recurrence.ID, storageError(err))
+	}
+
+	if len(response.Attributes) == 0 {
+		logger.Error("No recurrence found to delete", ErrNotFound, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("no recurrence found to delete with ID '%s': %w", recurrence.ID, ErrNotFound)
+	}
+
+	var deleted model.Recurrence
+	if err := attributevalue.UnmarshalMap(response.Attributes, &deleted); err != nil {
+		logger.Error("Failed to unmarshal deleted recurrence", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to unmarshal deleted recurrence: %w", storageError(err))
+	}
+
+	logger.Info("Recurrence successfully deleted", zap.String("recurrence_id", recurrence.ID))
+	return &deleted, nil
+}
+
+func (r *RecurrenceRepo) ListDueRecurrences(ctx context.Context, date string) ([]model.Recurrence, error) {
+	logger.Info("Attempting to list due recurrences", zap.String("date", date))
+
+	// "~" sorts after "#", so every entry of date itself is included.
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String(GSI1),
+		KeyConditionExpression: aws.String("GSI1PK = :pk AND GSI1SK <= :due"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":  &types.AttributeValueMemberS{Value: model.DueRecurrencesKey},
+			":due": &types.AttributeValueMemberS{Value: date + "~"},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query due recurrences from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query due recurrences from table: %w", storageError(err))
+	}
+
+	recurrences := []model.Recurrence{}
+	if err := attributevalue.UnmarshalListOfMaps(items, &recurrences); err != nil {
+		logger.Error("Failed to unmarshal due recurrences list", err)
+		return nil, fmt.Errorf("failed to unmarshal due recurrences list: %w", storageError(err))
+	}
+
+	logger.Info("Due recurrences successfully retrieved", zap.Int("count", len(recurrences)))
+	return recurrences, nil
+}
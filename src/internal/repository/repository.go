@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/joaoleau/muquirango/internal/model"
+)
+
+// TransactionRepository is the storage contract used by the transaction
+// handlers. Every implementation must pass the repositorytest suite.
+//
+// Transactions are listed and keyed by the date they occurred on.
+// GetTransactionByID accepts an empty occurredAt to look a transaction up by
+// ID alone. The update calls take the transaction under its stored keys and
+// move it to the keys of its new date when that changed, in the same write.
+//
+// Transactions are versioned. NewTransaction stores version 1; the
+// UpdateTransaction, PatchTransaction and DeleteTransaction calls only apply
+// while transaction.Version is still the stored version and return
+// ErrVersionMismatch otherwise. An update or patch stores the next version. The
+// transfer calls check and advance the version of each leg the same way, and
+// the bulk calls advance the version of every transaction they change.
+//
+// The monthly aggregates of an owner sum its income, purchases and
+// investments per month and currency. The DynamoDB backend stores them and
+// updates them in the same write as each create, update, patch and delete,
+// including the installments NewInstallmentPlan creates, so reports read a
+// few aggregates instead of every transaction. The other backends sum their
+// rows when asked.
+//
+// The create calls take the idempotency record of the request, or nil when
+// it carried no Idempotency-Key. The record is stored in the same write as
+// what it created; when a live record is stored for its key already, nothing
+// is written and the call returns ErrIdempotencyKeyUsed.
+type TransactionRepository interface {
+	NewTransaction(ctx context.Context, transaction *model.Transaction, record *model.IdempotencyRecord) (*model.Transaction, error)
+	UpdateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error)
+
+	// PatchTransaction writes only the fields set in patch and leaves every
+	// other attribute as stored. transaction names the item and the version
+	// the patch is based on.
+	PatchTransaction(ctx context.Context, transaction *model.Transaction, patch *model.TransactionPatch) (*model.Transaction, error)
+	ListTransactions(ctx context.Context, owner string, startDate string, endDate string, options ListOptions) (*TransactionPage, error)
+	GetTransactionByID(ctx context.Context, owner string, id string, occurredAt string) (*model.Transaction, error)
+	DeleteTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error)
+
+	// ReassignCategory moves every transaction of owner filed under the
+	// category from to the category to, or leaves them uncategorized when
+	// to is nil. It returns how many transactions changed.
+	ReassignCategory(ctx context.Context, owner string, from string, to *string) (int, error)
+
+	// ListTags counts how many transactions of owner carry each tag.
+	ListTags(ctx context.Context, owner string) ([]model.TagUsage, error)
+
+	// RenameTag replaces the tag from with to on every transaction of
+	// owner, merging both tags where a transaction already has to. It
+	// returns how many transactions changed.
+	RenameTag(ctx context.Context, owner string, from string, to string) (int, error)
+
+	// NewTransfer, UpdateTransfer and DeleteTransfer write both legs of a
+	// transfer, all or nothing, so one account is never debited without
+	// the other being credited.
+	NewTransfer(ctx context.Context, transfer *model.Transfer, record *model.IdempotencyRecord) (*model.Transfer, error)
+	UpdateTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error)
+	DeleteTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error)
+
+	// ListMonthlyAggregates returns the aggregates of owner from startMonth
+	// through endMonth, "2006-01" months, ordered by month and currency.
+	// Aggregates with nothing left to count are left out.
+	ListMonthlyAggregates(ctx context.Context, owner string, startMonth string, endMonth string) ([]model.MonthlyAggregate, error)
+
+	// RebuildMonthlyAggregates recomputes the aggregates of owner from its
+	// transactions, replacing whatever drifted, and returns them.
+	RebuildMonthlyAggregates(ctx context.Context, owner string) ([]model.MonthlyAggregate, error)
+
+	// GetIdempotencyRecord returns the live record stored for key by a
+	// create of owner, or ErrNotFound.
+	GetIdempotencyRecord(ctx context.Context, owner string, key string) (*model.IdempotencyRecord, error)
+}
+
+type CategoryRepository interface {
+	NewCategory(ctx context.Context, category *model.Category) (*model.Category, error)
+	UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error)
+	ListCategories(ctx context.Context, owner string) ([]model.Category, error)
+	GetCategoryByID(ctx context.Context, owner string, id string) (*model.Category, error)
+	DeleteCategory(ctx context.Context, category *model.Category) (*model.Category, error)
+}
+
+type BudgetRepository interface {
+	NewBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error)
+	UpdateBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error)
+	ListBudgets(ctx context.Context, owner string) ([]model.Budget, error)
+	GetBudgetByID(ctx context.Context, owner string, id string) (*model.Budget, error)
+	DeleteBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error)
+}
+
+type AccountRepository interface {
+	NewAccount(ctx context.Context, account *model.Account) (*model.Account, error)
+	UpdateAccount(ctx context.Context, account *model.Account) (*model.Account, error)
+	ListAccounts(ctx context.Context, owner string) ([]model.Account, error)
+	GetAccountByID(ctx context.Context, owner string, id string) (*model.Account, error)
+	DeleteAccount(ctx context.Context, account *model.Account) (*model.Account, error)
+}
+
+// RecurrenceRepository stores recurrence templates. ListDueRecurrences
+// returns the active rules of every owner whose next occurrence is on or
+// before date, which is what the scheduler materializes.
+type RecurrenceRepository interface {
+	NewRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error)
+	UpdateRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error)
+	ListRecurrences(ctx context.Context, owner string) ([]model.Recurrence, error)
+	GetRecurrenceByID(ctx context.Context, owner string, id string) (*model.Recurrence, error)
+	DeleteRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error)
+	ListDueRecurrences(ctx context.Context, date string) ([]model.Recurrence, error)
+}
+
+// InstallmentRepository stores installment plans. NewInstallmentPlan
+// writes the plan together with its installments and the idempotency record
+// of the request, as the TransactionRepository create calls do, all or
+// nothing, so a purchase is never left half split. UpdateInstallmentPlan
+// and CancelInstallmentPlan likewise write the plan with the installments
+// they replace or delete in one go, each guarded by the version it was read
+// with; UpdateInstallmentPlan leaves the new versions in installments.
+// ListInstallments returns the installments of a plan still stored, in
+// order. MaxInstallments is the most installments a plan fits in the
+// store, at most the MaxInstallments constant.
+type InstallmentRepository interface {
+	MaxInstallments() int
+	NewInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction, record *model.IdempotencyRecord) (*model.InstallmentPlan, error)
+	UpdateInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error)
+	CancelInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error)
+	ListInstallments(ctx context.Context, plan *model.InstallmentPlan) ([]model.Transaction, error)
+	ListInstallmentPlans(ctx context.Context, owner string) ([]model.InstallmentPlan, error)
+	GetInstallmentPlanByID(ctx context.Context, owner string, id string) (*model.InstallmentPlan, error)
+}
+
+// ExchangeRateRepository stores the exchange rates of each owner, one per
+// currency pair and day. PutExchangeRates writes rates as given, replacing
+// those already stored for the same pair and day, so importing the same
+// file twice changes nothing.
+type ExchangeRateRepository interface {
+	PutExchangeRates(ctx context.Context, rates []model.ExchangeRate) ([]model.ExchangeRate, error)
+	ListExchangeRates(ctx context.Context, owner string) ([]model.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, rate *model.ExchangeRate) (*model.ExchangeRate, error)
+}
+
+// PageKey is the primary key of the last item of a page. Passing it back as
+// ListOptions.StartKey resumes the listing right after that item.
+type PageKey map[string]string
+
+// ListOptions bounds a single page of a listing. A zero Limit lets the
+// backend pick the page size. A non-empty Tag keeps only transactions
+// carrying that tag, and a non-empty AccountID only those of that account.
+type ListOptions struct {
+	Limit     int
+	StartKey  PageKey
+	Tag       string
+	AccountID string
+}
+
+// TransactionPage is one page of a listing. NextKey is nil on the last page.
+type TransactionPage struct {
+	Items   []model.Transaction
+	NextKey PageKey
+}
+
+// ListAllTransactions follows NextKey until the listing is exhausted. It is
+// meant for reports that need the whole date range at once.
+func ListAllTransactions(ctx context.Context, repo TransactionRepository, owner string, startDate string, endDate string) ([]model.Transaction, error) {
+	return ListAllTransactionsWith(ctx, repo, owner, startDate, endDate, ListOptions{})
+}
+
+// ListAllTransactionsWith is ListAllTransactions with filters applied.
+// options.StartKey is ignored.
+func ListAllTransactionsWith(ctx context.Context, repo TransactionRepository, owner string, startDate string, endDate string, options ListOptions) ([]model.Transaction, error) {
+	var all []model.Transaction
+	options.StartKey = nil
+
+	for {
+		page, err := repo.ListTransactions(ctx, owner, startDate, endDate, options)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Items...)
+
+		if page.NextKey == nil {
+			return all, nil
+		}
+		options.StartKey = page.NextKey
+	}
+}
+
+var (
+	_ TransactionRepository = (*TransactionRepo)(nil)
+	_ TransactionRepository = (*MemoryTransactionRepo)(nil)
+	_ TransactionRepository = (*SQLiteTransactionRepo)(nil)
+
+	_ CategoryRepository = (*CategoryRepo)(nil)
+	_ CategoryRepository = (*MemoryCategoryRepo)(nil)
+	_ CategoryRepository = (*SQLiteCategoryRepo)(nil)
+
+	_ BudgetRepository = (*BudgetRepo)(nil)
+	_ BudgetRepository = (*MemoryBudgetRepo)(nil)
+	_ BudgetRepository = (*SQLiteBudgetRepo)(nil)
+
+	_ AccountRepository = (*AccountRepo)(nil)
+	_ AccountRepository = (*MemoryAccountRepo)(nil)
+	_ AccountRepository = (*SQLiteAccountRepo)(nil)
+
+	_ RecurrenceRepository = (*RecurrenceRepo)(nil)
+	_ RecurrenceRepository = (*MemoryRecurrenceRepo)(nil)
+	_ RecurrenceRepository = (*SQLiteRecurrenceRepo)(nil)
+
+	_ InstallmentRepository = (*InstallmentRepo)(nil)
+	_ InstallmentRepository = (*MemoryInstallmentRepo)(nil)
+	_ InstallmentRepository = (*SQLiteInstallmentRepo)(nil)
+
+	_ ExchangeRateRepository = (*ExchangeRateRepo)(nil)
+	_ ExchangeRateRepository = (*MemoryExchangeRateRepo)(nil)
+	_ ExchangeRateRepository = (*SQLiteExchangeRateRepo)(nil)
+)
+
+// applyUpdate copies the fields UpdateTransaction replaces from transaction
+// onto stored, leaving the rest as stored.
+func applyUpdate(stored *model.Transaction, transaction *model.Transaction) {
+	stored.Type = transaction.Type
+	stored.Title = transaction.Title
+	stored.Description = transaction.Description
+	stored.Money = transaction.Money
+	stored.CategoryID = transaction.CategoryID
+	stored.AccountID = transaction.AccountID
+	stored.Tags = transaction.Tags
+	stored.OccurredAt = transaction.OccurredAt
+	stored.UpdatedAt = transaction.UpdatedAt
+}
+
+// countTags tallies tag usage across transactions, ordered by tag.
+func countTags(transactions []model.Transaction) []model.TagUsage {
+	counts := map[string]int{}
+	for _, transaction := range transactions {
+		for _, tag := range transaction.Tags {
+			counts[tag]++
+		}
+	}
+
+	usage := make([]model.TagUsage, 0, len(counts))
+	for tag, count := range counts {
+		usage = append(usage, model.TagUsage{Tag: tag, Count: count})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Tag < usage[j].Tag })
+	return usage
+}
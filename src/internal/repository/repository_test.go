@@ -0,0 +1,138 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joaoleau/muquirango/internal/config"
+	"github.com/joaoleau/muquirango/internal/repository"
+	"github.com/joaoleau/muquirango/internal/repository/repositorytest"
+)
+
+// repositories are the repositories of one backend sharing a single store,
+// as the application wires them.
+type repositories struct {
+	transactions repository.TransactionRepository
+	categories   repository.CategoryRepository
+	budgets      repository.BudgetRepository
+	accounts     repository.AccountRepository
+	recurrences  repository.RecurrenceRepository
+	installments repository.InstallmentRepository
+	rates        repository.ExchangeRateRepository
+}
+
+// backends open an empty store per subtest.
+var backends = []struct {
+	name string
+	open func(t *testing.T) repositories
+}{
+	{"Memory", openMemory},
+	{"SQLite", openSQLite},
+	{"DynamoDB", openDynamoDB},
+}
+
+func TestRepositories(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			if backend.name == "DynamoDB" && os.Getenv(repositorytest.DYNAMODB_LOCAL_ENDPOINT) == "" {
+				t.Skipf("set %s to run against DynamoDB Local", repositorytest.DYNAMODB_LOCAL_ENDPOINT)
+			}
+			open := backend.open
+
+			t.Run("Transaction", func(t *testing.T) {
+				repositorytest.RunTransactionRepositoryTests(t, func(t *testing.T) repository.TransactionRepository {
+					return open(t).transactions
+				})
+			})
+			t.Run("Category", func(t *testing.T) {
+				repositorytest.RunCategoryRepositoryTests(t, func(t *testing.T) repository.CategoryRepository {
+					return open(t).categories
+				})
+			})
+			t.Run("Budget", func(t *testing.T) {
+				repositorytest.RunBudgetRepositoryTests(t, func(t *testing.T) repository.BudgetRepository {
+					return open(t).budgets
+				})
+			})
+			t.Run("Account", func(t *testing.T) {
+				repositorytest.RunAccountRepositoryTests(t, func(t *testing.T) repository.AccountRepository {
+					return open(t).accounts
+				})
+			})
+			t.Run("Recurrence", func(t *testing.T) {
+				repositorytest.RunRecurrenceRepositoryTests(t, func(t *testing.T) repository.RecurrenceRepository {
+					return open(t).recurrences
+				})
+			})
+			t.Run("Installment", func(t *testing.T) {
+				repositorytest.RunInstallmentRepositoryTests(t, func(t *testing.T) (repository.InstallmentRepository, repository.TransactionRepository) {
+					repos := open(t)
+					return repos.installments, repos.transactions
+				})
+			})
+			t.Run("ExchangeRate", func(t *testing.T) {
+				repositorytest.RunExchangeRateRepositoryTests(t, func(t *testing.T) repository.ExchangeRateRepository {
+					return open(t).rates
+				})
+			})
+		})
+	}
+}
+
+func openMemory(t *testing.T) repositories {
+	transactions := repository.NewMemoryTransactionRepository()
+	return repositories{
+		transactions: transactions,
+		categories:   repository.NewMemoryCategoryRepository(),
+		budgets:      repository.NewMemoryBudgetRepository(),
+		accounts:     repository.NewMemoryAccountRepository(),
+		recurrences:  repository.NewMemoryRecurrenceRepository(),
+		installments: repository.NewMemoryInstallmentRepository(transactions),
+		rates:        repository.NewMemoryExchangeRateRepository(),
+	}
+}
+
+// openSQLite opens a database file of its own, migrated twice to check
+// migrations are skipped once applied.
+func openSQLite(t *testing.T) repositories {
+	ctx := context.Background()
+	t.Setenv(config.SQLITE_PATH, filepath.Join(t.TempDir(), "muquirango.db"))
+
+	db, err := config.SQLiteClient(ctx)
+	if err != nil {
+		t.Fatalf("SQLiteClient: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for range 2 {
+		if err := repository.MigrateSQLite(ctx, db); err != nil {
+			t.Fatalf("MigrateSQLite: %v", err)
+		}
+	}
+
+	return repositories{
+		transactions: repository.NewSQLiteTransactionRepository(db),
+		categories:   repository.NewSQLiteCategoryRepository(db),
+		budgets:      repository.NewSQLiteBudgetRepository(db),
+		accounts:     repository.NewSQLiteAccountRepository(db),
+		recurrences:  repository.NewSQLiteRecurrenceRepository(db),
+		installments: repository.NewSQLiteInstallmentRepository(db),
+		rates:        repository.NewSQLiteExchangeRateRepository(db),
+	}
+}
+
+// openDynamoDB creates a table of its own on DynamoDB Local and deletes it
+// once the subtest is done.
+func openDynamoDB(t *testing.T) repositories {
+	db, table := repositorytest.OpenDynamoDBLocal(t)
+	return repositories{
+		transactions: repository.NewTransactionRepository(db, table),
+		categories:   repository.NewCategoryRepository(db, table),
+		budgets:      repository.NewBudgetRepository(db, table),
+		accounts:     repository.NewAccountRepository(db, table),
+		recurrences:  repository.NewRecurrenceRepository(db, table),
+		installments: repository.NewInstallmentRepository(db, table),
+		rates:        repository.NewExchangeRateRepository(db, table),
+	}
+}
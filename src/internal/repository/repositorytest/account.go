@@ -0,0 +1,129 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// AccountFactory returns an empty repository for a single subtest.
+type AccountFactory func(t *testing.T) repository.AccountRepository
+
+// RunAccountRepositoryTests exercises the behaviour shared by every
+// account storage backend.
+func RunAccountRepositoryTests(t *testing.T, newRepository AccountFactory) {
+	t.Run("CRUD", func(t *testing.T) { testAccountCRUD(t, newRepository(t)) })
+	t.Run("OwnerIsolation", func(t *testing.T) { testAccountOwnerIsolation(t, newRepository(t)) })
+	t.Run("NotFound", func(t *testing.T) { testAccountNotFound(t, newRepository(t)) })
+}
+
+// NewAccount builds a keyed checking account for owner.
+func NewAccount(owner string, name string, openingBalance int) *model.Account {
+	now := time.Now().UTC()
+	account := &model.Account{
+		OwnerID:        owner,
+		ID:             uuid.NewString(),
+		Name:           name,
+		Kind:           model.AccountKindChecking,
+		Currency:       "BRL",
+		OpeningBalance: openingBalance,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	account.SetKeys()
+	return account
+}
+
+func testAccountCRUD(t *testing.T, repo repository.AccountRepository) {
+	ctx := context.Background()
+	checking := NewAccount("alice", "Checking", 1000)
+	card := NewAccount("alice", "Visa", 0)
+	card.Kind = model.AccountKindCreditCard
+	card.ClosingDay, card.DueDay = 25, 5
+	for _, account := range []*model.Account{checking, card} {
+		if _, err := repo.NewAccount(ctx, account); err != nil {
+			t.Fatalf("NewAccount: %v", err)
+		}
+	}
+
+	got, err := repo.GetAccountByID(ctx, "alice", checking.ID)
+	if err != nil {
+		t.Fatalf("GetAccountByID: %v", err)
+	}
+	if got.Name != "Checking" || got.Kind != model.AccountKindChecking || got.Currency != "BRL" || got.OpeningBalance != 1000 {
+		t.Fatalf("GetAccountByID returned %+v", got)
+	}
+
+	got, err = repo.GetAccountByID(ctx, "alice", card.ID)
+	if err != nil {
+		t.Fatalf("GetAccountByID: %v", err)
+	}
+	if got.ClosingDay != 25 || got.DueDay != 5 {
+		t.Fatalf("card billing days = %d/%d, want 25/5", got.ClosingDay, got.DueDay)
+	}
+
+	changed := *checking
+	changed.Name = "Main"
+	changed.Currency = "USD"
+	changed.OpeningBalance = 250
+	updated, err := repo.UpdateAccount(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateAccount: %v", err)
+	}
+	if updated.Name != "Main" || updated.Currency != "USD" || updated.OpeningBalance != 250 {
+		t.Fatalf("UpdateAccount returned %+v", updated)
+	}
+
+	accounts, err := repo.ListAccounts(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListAccounts: %v", err)
+	}
+	if len(accounts) != 2 {
+		t.Fatalf("ListAccounts returned %d accounts, want 2", len(accounts))
+	}
+
+	if _, err := repo.DeleteAccount(ctx, card); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+	if got, err := repo.GetAccountByID(ctx, "alice", card.ID); err == nil {
+		t.Fatalf("account still readable after delete: %+v", got)
+	}
+}
+
+func testAccountOwnerIsolation(t *testing.T, repo repository.AccountRepository) {
+	ctx := context.Background()
+	account := NewAccount("alice", "Checking", 0)
+	if _, err := repo.NewAccount(ctx, account); err != nil {
+		t.Fatalf("NewAccount: %v", err)
+	}
+
+	if got, err := repo.GetAccountByID(ctx, "bob", account.ID); err == nil {
+		t.Fatalf("bob read alice's account: %+v", got)
+	}
+	accounts, err := repo.ListAccounts(ctx, "bob")
+	if err != nil {
+		t.Fatalf("ListAccounts: %v", err)
+	}
+	if len(accounts) != 0 {
+		t.Fatalf("bob listed %d of alice's accounts", len(accounts))
+	}
+}
+
+func testAccountNotFound(t *testing.T, repo repository.AccountRepository) {
+	ctx := context.Background()
+	missing := NewAccount("alice", "Ghost", 0)
+
+	if _, err := repo.UpdateAccount(ctx, missing); err == nil {
+		t.Fatal("UpdateAccount of unknown account succeeded")
+	}
+	if got, err := repo.GetAccountByID(ctx, "alice", missing.ID); err == nil {
+		t.Fatalf("UpdateAccount created a phantom item: %+v", got)
+	}
+	if _, err := repo.DeleteAccount(ctx, missing); err == nil {
+		t.Fatal("DeleteAccount of unknown account succeeded")
+	}
+}
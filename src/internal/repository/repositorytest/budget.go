@@ -0,0 +1,136 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// BudgetFactory returns an empty repository for a single subtest.
+type BudgetFactory func(t *testing.T) repository.BudgetRepository
+
+// RunBudgetRepositoryTests exercises the behaviour shared by every budget
+// storage backend.
+func RunBudgetRepositoryTests(t *testing.T, newRepository BudgetFactory) {
+	t.Run("CRUD", func(t *testing.T) { testBudgetCRUD(t, newRepository(t)) })
+	t.Run("OwnerIsolation", func(t *testing.T) { testBudgetOwnerIsolation(t, newRepository(t)) })
+	t.Run("NotFound", func(t *testing.T) { testBudgetNotFound(t, newRepository(t)) })
+}
+
+// NewBudget builds a keyed monthly budget for owner capping the purchases
+// of categoryID from October 2026 on.
+func NewBudget(owner string, name string, categoryID string, limit int) *model.Budget {
+	now := time.Now().UTC()
+	budget := &model.Budget{
+		OwnerID:    owner,
+		ID:         uuid.NewString(),
+		Name:       name,
+		CategoryID: &categoryID,
+		Limit:      limit,
+		StartMonth: "2026-10",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	budget.SetKeys()
+	return budget
+}
+
+func testBudgetCRUD(t *testing.T, repo repository.BudgetRepository) {
+	ctx := context.Background()
+	groceries := NewBudget("alice", "Groceries", uuid.NewString(), 80000)
+	purchases := NewBudget("alice", "Everything", "", 300000)
+	kind := model.TransactionTypePurchase
+	purchases.CategoryID = nil
+	purchases.Type = &kind
+	purchases.Rollover = true
+	for _, budget := range []*model.Budget{groceries, purchases} {
+		if _, err := repo.NewBudget(ctx, budget); err != nil {
+			t.Fatalf("NewBudget: %v", err)
+		}
+	}
+
+	got, err := repo.GetBudgetByID(ctx, "alice", groceries.ID)
+	if err != nil {
+		t.Fatalf("GetBudgetByID: %v", err)
+	}
+	if got.Name != "Groceries" || got.Limit != 80000 || got.StartMonth != "2026-10" || got.EndMonth != nil || got.Rollover {
+		t.Fatalf("GetBudgetByID returned %+v", got)
+	}
+	if got.CategoryID == nil || *got.CategoryID != *groceries.CategoryID || got.Type != nil {
+		t.Fatalf("GetBudgetByID returned category %v and type %v", got.CategoryID, got.Type)
+	}
+
+	got, err = repo.GetBudgetByID(ctx, "alice", purchases.ID)
+	if err != nil {
+		t.Fatalf("GetBudgetByID: %v", err)
+	}
+	if got.CategoryID != nil || got.Type == nil || *got.Type != model.TransactionTypePurchase || !got.Rollover {
+		t.Fatalf("GetBudgetByID returned %+v", got)
+	}
+
+	endMonth := "2026-12"
+	changed := *groceries
+	changed.Name = "Food"
+	changed.Limit = 95000
+	changed.EndMonth = &endMonth
+	updated, err := repo.UpdateBudget(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateBudget: %v", err)
+	}
+	if updated.Name != "Food" || updated.Limit != 95000 || updated.EndMonth == nil || *updated.EndMonth != endMonth {
+		t.Fatalf("UpdateBudget returned %+v", updated)
+	}
+
+	budgets, err := repo.ListBudgets(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListBudgets: %v", err)
+	}
+	if len(budgets) != 2 {
+		t.Fatalf("ListBudgets returned %d budgets, want 2", len(budgets))
+	}
+
+	if _, err := repo.DeleteBudget(ctx, purchases); err != nil {
+		t.Fatalf("DeleteBudget: %v", err)
+	}
+	if got, err := repo.GetBudgetByID(ctx, "alice", purchases.ID); err == nil {
+		t.Fatalf("budget still readable after delete: %+v, %v", got, err)
+	}
+}
+
+func testBudgetOwnerIsolation(t *testing.T, repo repository.BudgetRepository) {
+	ctx := context.Background()
+	budget := NewBudget("alice", "Groceries", uuid.NewString(), 80000)
+	if _, err := repo.NewBudget(ctx, budget); err != nil {
+		t.Fatalf("NewBudget: %v", err)
+	}
+
+	if got, err := repo.GetBudgetByID(ctx, "bob", budget.ID); err == nil {
+		t.Fatalf("bob read alice's budget: %+v", got)
+	}
+	budgets, err := repo.ListBudgets(ctx, "bob")
+	if err != nil {
+		t.Fatalf("ListBudgets: %v", err)
+	}
+	if len(budgets) != 0 {
+		t.Fatalf("bob listed %d of alice's budgets", len(budgets))
+	}
+}
+
+func testBudgetNotFound(t *testing.T, repo repository.BudgetRepository) {
+	ctx := context.Background()
+	missing := NewBudget("alice", "Ghost", uuid.NewString(), 1000)
+
+	if _, err := repo.UpdateBudget(ctx, missing); err == nil {
+		t.Fatal("UpdateBudget of unknown budget succeeded")
+	}
+	if got, err := repo.GetBudgetByID(ctx, "alice", missing.ID); err == nil {
+		t.Fatalf("UpdateBudget created a phantom item: %+v", got)
+	}
+	if _, err := repo.DeleteBudget(ctx, missing); err == nil {
+		t.Fatal("DeleteBudget of unknown budget succeeded")
+	}
+}
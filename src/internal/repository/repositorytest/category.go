@@ -0,0 +1,118 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// CategoryFactory returns an empty repository for a single subtest.
+type CategoryFactory func(t *testing.T) repository.CategoryRepository
+
+// RunCategoryRepositoryTests exercises the behaviour shared by every
+// category storage backend.
+func RunCategoryRepositoryTests(t *testing.T, newRepository CategoryFactory) {
+	t.Run("CRUD", func(t *testing.T) { testCategoryCRUD(t, newRepository(t)) })
+	t.Run("OwnerIsolation", func(t *testing.T) { testCategoryOwnerIsolation(t, newRepository(t)) })
+	t.Run("NotFound", func(t *testing.T) { testCategoryNotFound(t, newRepository(t)) })
+}
+
+// NewCategory builds a keyed category for owner.
+func NewCategory(owner string, name string, parentID *string) *model.Category {
+	now := time.Now().UTC()
+	category := &model.Category{
+		OwnerID:   owner,
+		ID:        uuid.NewString(),
+		Name:      name,
+		ParentID:  parentID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	category.SetKeys()
+	return category
+}
+
+func testCategoryCRUD(t *testing.T, repo repository.CategoryRepository) {
+	ctx := context.Background()
+	parent := NewCategory("alice", "Home", nil)
+	child := NewCategory("alice", "Groceries", &parent.ID)
+	for _, category := range []*model.Category{parent, child} {
+		if _, err := repo.NewCategory(ctx, category); err != nil {
+			t.Fatalf("NewCategory: %v", err)
+		}
+	}
+
+	got, err := repo.GetCategoryByID(ctx, "alice", child.ID)
+	if err != nil {
+		t.Fatalf("GetCategoryByID: %v", err)
+	}
+	if got.Name != "Groceries" || got.ParentID == nil || *got.ParentID != parent.ID {
+		t.Fatalf("GetCategoryByID returned %+v", got)
+	}
+
+	color := "#00ff00"
+	changed := *child
+	changed.Name = "Supermarket"
+	changed.Color = &color
+	changed.ParentID = nil
+	updated, err := repo.UpdateCategory(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateCategory: %v", err)
+	}
+	if updated.Name != "Supermarket" || updated.Color == nil || *updated.Color != color || updated.ParentID != nil {
+		t.Fatalf("UpdateCategory returned %+v", updated)
+	}
+
+	categories, err := repo.ListCategories(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListCategories: %v", err)
+	}
+	if len(categories) != 2 {
+		t.Fatalf("ListCategories returned %d categories, want 2", len(categories))
+	}
+
+	if _, err := repo.DeleteCategory(ctx, child); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	if got, err := repo.GetCategoryByID(ctx, "alice", child.ID); err == nil {
+		t.Fatalf("category still readable after delete: %+v", got)
+	}
+}
+
+func testCategoryOwnerIsolation(t *testing.T, repo repository.CategoryRepository) {
+	ctx := context.Background()
+	category := NewCategory("alice", "Home", nil)
+	if _, err := repo.NewCategory(ctx, category); err != nil {
+		t.Fatalf("NewCategory: %v", err)
+	}
+
+	if got, err := repo.GetCategoryByID(ctx, "bob", category.ID); err == nil {
+		t.Fatalf("bob read alice's category: %+v", got)
+	}
+	categories, err := repo.ListCategories(ctx, "bob")
+	if err != nil {
+		t.Fatalf("ListCategories: %v", err)
+	}
+	if len(categories) != 0 {
+		t.Fatalf("bob listed %d of alice's categories", len(categories))
+	}
+}
+
+func testCategoryNotFound(t *testing.T, repo repository.CategoryRepository) {
+	ctx := context.Background()
+	missing := NewCategory("alice", "Ghost", nil)
+
+	if _, err := repo.UpdateCategory(ctx, missing); err == nil {
+		t.Fatal("UpdateCategory of unknown category succeeded")
+	}
+	if got, err := repo.GetCategoryByID(ctx, "alice", missing.ID); err == nil {
+		t.Fatalf("UpdateCategory created a phantom item: %+v", got)
+	}
+	if _, err := repo.DeleteCategory(ctx, missing); err == nil {
+		t.Fatal("DeleteCategory of unknown category succeeded")
+	}
+}
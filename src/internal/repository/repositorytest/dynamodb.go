@@ -0,0 +1,46 @@
+package repositorytest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// DYNAMODB_LOCAL_ENDPOINT points the DynamoDB backend at a DynamoDB Local
+// instance, such as http://localhost:8000. Tests needing it are skipped when
+// it is unset.
+const DYNAMODB_LOCAL_ENDPOINT = "DYNAMODB_LOCAL_ENDPOINT"
+
+// OpenDynamoDBLocal creates a table of its own on DynamoDB Local and deletes
+// it once the test is done. It skips the test when DYNAMODB_LOCAL_ENDPOINT
+// is unset.
+func OpenDynamoDBLocal(t *testing.T) (*dynamodb.Client, string) {
+	t.Helper()
+	endpoint := os.Getenv(DYNAMODB_LOCAL_ENDPOINT)
+	if endpoint == "" {
+		t.Skipf("set %s to run against DynamoDB Local", DYNAMODB_LOCAL_ENDPOINT)
+	}
+
+	ctx := context.Background()
+	db := dynamodb.New(dynamodb.Options{
+		Region:       "sa-east-1",
+		BaseEndpoint: aws.String(endpoint),
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "local", SecretAccessKey: "local"}, nil
+		}),
+	})
+
+	table := "muquirango-test-" + uuid.NewString()
+	if err := repository.CreateTable(ctx, db, table); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	t.Cleanup(func() {
+		db.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{TableName: aws.String(table)})
+	})
+	return db, table
+}
@@ -0,0 +1,130 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// ExchangeRateFactory returns an empty repository for a single subtest.
+type ExchangeRateFactory func(t *testing.T) repository.ExchangeRateRepository
+
+// RunExchangeRateRepositoryTests exercises the behaviour shared by every
+// exchange rate storage backend.
+func RunExchangeRateRepositoryTests(t *testing.T, newRepository ExchangeRateFactory) {
+	t.Run("PutAndList", func(t *testing.T) { testExchangeRatePutAndList(t, newRepository(t)) })
+	t.Run("Replace", func(t *testing.T) { testExchangeRateReplace(t, newRepository(t)) })
+	t.Run("Delete", func(t *testing.T) { testExchangeRateDelete(t, newRepository(t)) })
+	t.Run("OwnerIsolation", func(t *testing.T) { testExchangeRateOwnerIsolation(t, newRepository(t)) })
+}
+
+// NewExchangeRate builds a keyed rate for owner.
+func NewExchangeRate(owner string, base string, quote string, date string, rate string) model.ExchangeRate {
+	exchangeRate := model.ExchangeRate{
+		OwnerID:   owner,
+		Base:      base,
+		Quote:     quote,
+		Date:      date,
+		Rate:      rate,
+		UpdatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	exchangeRate.SetKeys()
+	return exchangeRate
+}
+
+func mustPutRates(t *testing.T, repo repository.ExchangeRateRepository, rates ...model.ExchangeRate) {
+	t.Helper()
+	if _, err := repo.PutExchangeRates(context.Background(), rates); err != nil {
+		t.Fatalf("PutExchangeRates: %v", err)
+	}
+}
+
+func testExchangeRatePutAndList(t *testing.T, repo repository.ExchangeRateRepository) {
+	ctx := context.Background()
+	mustPutRates(t, repo,
+		NewExchangeRate("alice", "USD", "BRL", "2026-01-02", "5.4321"),
+		NewExchangeRate("alice", "EUR", "BRL", "2026-01-02", "6.01"),
+		NewExchangeRate("alice", "USD", "BRL", "2026-01-01", "5.40"),
+	)
+
+	rates, err := repo.ListExchangeRates(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListExchangeRates: %v", err)
+	}
+	want := []string{"EUR/BRL 2026-01-02 6.01", "USD/BRL 2026-01-01 5.40", "USD/BRL 2026-01-02 5.4321"}
+	if len(rates) != len(want) {
+		t.Fatalf("ListExchangeRates returned %d rates, want %d", len(rates), len(want))
+	}
+	for i, rate := range rates {
+		if got := rate.Base + "/" + rate.Quote + " " + rate.Date + " " + rate.Rate; got != want[i] {
+			t.Fatalf("rate %d = %s, want %s", i, got, want[i])
+		}
+		if rate.SK != model.ExchangeRateKey(rate.Base, rate.Quote, rate.Date) {
+			t.Fatalf("rate %d has key %s", i, rate.SK)
+		}
+	}
+}
+
+func testExchangeRateReplace(t *testing.T, repo repository.ExchangeRateRepository) {
+	ctx := context.Background()
+	mustPutRates(t, repo, NewExchangeRate("alice", "USD", "BRL", "2026-01-02", "5.40"))
+	mustPutRates(t, repo,
+		NewExchangeRate("alice", "USD", "BRL", "2026-01-02", "5.41"),
+		NewExchangeRate("alice", "USD", "BRL", "2026-01-02", "5.42"),
+	)
+
+	rates, err := repo.ListExchangeRates(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListExchangeRates: %v", err)
+	}
+	if len(rates) != 1 || rates[0].Rate != "5.42" {
+		t.Fatalf("ListExchangeRates returned %+v, want the last rate put only", rates)
+	}
+}
+
+func testExchangeRateDelete(t *testing.T, repo repository.ExchangeRateRepository) {
+	ctx := context.Background()
+	rate := NewExchangeRate("alice", "USD", "BRL", "2026-01-02", "5.40")
+	mustPutRates(t, repo, rate)
+
+	deleted, err := repo.DeleteExchangeRate(ctx, &rate)
+	if err != nil {
+		t.Fatalf("DeleteExchangeRate: %v", err)
+	}
+	if deleted.Rate != "5.40" {
+		t.Fatalf("DeleteExchangeRate returned %+v", deleted)
+	}
+	if _, err := repo.DeleteExchangeRate(ctx, &rate); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("second DeleteExchangeRate returned %v, want ErrNotFound", err)
+	}
+
+	rates, err := repo.ListExchangeRates(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListExchangeRates: %v", err)
+	}
+	if len(rates) != 0 {
+		t.Fatalf("rates still listed after delete: %+v", rates)
+	}
+}
+
+func testExchangeRateOwnerIsolation(t *testing.T, repo repository.ExchangeRateRepository) {
+	ctx := context.Background()
+	mustPutRates(t, repo, NewExchangeRate("alice", "USD", "BRL", "2026-01-02", "5.40"))
+
+	rates, err := repo.ListExchangeRates(ctx, "bob")
+	if err != nil {
+		t.Fatalf("ListExchangeRates: %v", err)
+	}
+	if len(rates) != 0 {
+		t.Fatalf("bob listed %d of alice's rates", len(rates))
+	}
+
+	theirs := NewExchangeRate("bob", "USD", "BRL", "2026-01-02", "5.40")
+	if _, err := repo.DeleteExchangeRate(ctx, &theirs); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("bob deleted alice's rate: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// InstallmentFactory returns an empty installment repository together with
+// the transaction repository its installments are written to.
+type InstallmentFactory func(t *testing.T) (repository.InstallmentRepository, repository.TransactionRepository)
+
+// RunInstallmentRepositoryTests exercises the behaviour shared by every
+// installment plan storage backend.
+func RunInstallmentRepositoryTests(t *testing.T, newRepositories InstallmentFactory) {
+	t.Run("CreateWithInstallments", func(t *testing.T) { testCreateInstallmentPlan(t, newRepositories) })
+	t.Run("CreateMaxInstallments", func(t *testing.T) { testCreateMaxInstallments(t, newRepositories) })
+	t.Run("Update", func(t *testing.T) { testUpdateInstallmentPlan(t, newRepositories) })
+	t.Run("UpdateWithInstallments", func(t *testing.T) { testUpdateInstallments(t, newRepositories) })
+	t.Run("Cancel", func(t *testing.T) { testCancelInstallmentPlan(t, newRepositories) })
+	t.Run("NotFound", func(t *testing.T) { testInstallmentPlanNotFound(t, newRepositories) })
+}
+
+// NewInstallmentPlan builds a keyed plan for owner purchased at the given
+// day.
+func NewInstallmentPlan(owner string, occurredAt time.Time, amount int, installments int) *model.InstallmentPlan {
+	plan := &model.InstallmentPlan{
+		OwnerID:      owner,
+		ID:           uuid.NewString(),
+		Title:        "Television",
+		Money:        model.Money{Amount: amount, Currency: "BRL"},
+		Installments: installments,
+		Tags:         []string{"home"},
+		OccurredAt:   occurredAt.UTC(),
+		CreatedAt:    occurredAt.UTC(),
+		UpdatedAt:    occurredAt.UTC(),
+	}
+	plan.SetKeys()
+	return plan
+}
+
+func testCreateInstallmentPlan(t *testing.T, newRepositories InstallmentFactory) {
+	ctx := context.Background()
+	repo, transactions := newRepositories(t)
+	plan := NewInstallmentPlan("alice", day("2026-01-31"), 1000, 3)
+
+	if _, err := repo.NewInstallmentPlan(ctx, plan, plan.Split(), nil); err != nil {
+		t.Fatalf("NewInstallmentPlan: %v", err)
+	}
+
+	got, err := repo.GetInstallmentPlanByID(ctx, "alice", plan.ID)
+	if err != nil {
+		t.Fatalf("GetInstallmentPlanByID: %v", err)
+	}
+	if got.Amount != 1000 || got.Currency != "BRL" || got.Installments != 3 || got.CancelledAt != nil {
+		t.Fatalf("GetInstallmentPlanByID returned %+v", got)
+	}
+
+	listed, err := repository.ListAllTransactions(ctx, transactions, "alice", "2026-01-01", "2026-12-31")
+	if err != nil {
+		t.Fatalf("ListAllTransactions: %v", err)
+	}
+	wantDates := []string{"2026-01-31", "2026-02-28", "2026-03-31"}
+	wantAmounts := []int{334, 333, 333}
+	if len(listed) != len(wantDates) {
+		t.Fatalf("listed %d installments, want %d", len(listed), len(wantDates))
+	}
+	for i, installment := range listed {
+		if date := installment.OccurredAt.Format("2006-01-02"); date != wantDates[i] {
+			t.Fatalf("installment %d dated %s, want %s", i+1, date, wantDates[i])
+		}
+		if installment.Amount != wantAmounts[i] || installment.Currency != "BRL" || installment.Installment != i+1 || installment.Installments != 3 {
+			t.Fatalf("installment %d is %+v", i+1, installment)
+		}
+		if installment.ParentID == nil || *installment.ParentID != plan.ID {
+			t.Fatalf("installment %d has parent %v, want %s", i+1, installment.ParentID, plan.ID)
+		}
+	}
+
+	plans, err := repo.ListInstallmentPlans(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListInstallmentPlans: %v", err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("ListInstallmentPlans returned %d plans, want 1", len(plans))
+	}
+	if others, _ := repo.ListInstallmentPlans(ctx, "bob"); len(others) != 0 {
+		t.Fatalf("bob sees %d of alice's plans", len(others))
+	}
+}
+
+// testCreateMaxInstallments checks the store takes a plan of as many
+// installments as it claims, together with an idempotency record.
+func testCreateMaxInstallments(t *testing.T, newRepositories InstallmentFactory) {
+	ctx := context.Background()
+	repo, _ := newRepositories(t)
+	limit := repo.MaxInstallments()
+	if limit < 1 || limit > repository.MaxInstallments {
+		t.Fatalf("MaxInstallments = %d, want between 1 and %d", limit, repository.MaxInstallments)
+	}
+
+	plan := NewInstallmentPlan("alice", day("2026-01-31"), 100000, limit)
+	record := NewIdempotencyRecord("alice", "longest", time.Hour)
+	if _, err := repo.NewInstallmentPlan(ctx, plan, plan.Split(), record); err != nil {
+		t.Fatalf("NewInstallmentPlan of %d installments: %v", limit, err)
+	}
+}
+
+func testUpdateInstallmentPlan(t *testing.T, newRepositories InstallmentFactory) {
+	ctx := context.Background()
+	repo, _ := newRepositories(t)
+	plan := NewInstallmentPlan("alice", day("2026-10-01"), 1200, 12)
+	if _, err := repo.NewInstallmentPlan(ctx, plan, plan.Split(), nil); err != nil {
+		t.Fatalf("NewInstallmentPlan: %v", err)
+	}
+
+	cancelledAt := day("2026-10-17")
+	changed := *plan
+	changed.Title = "TV"
+	changed.CancelledAt = &cancelledAt
+	if _, err := repo.UpdateInstallmentPlan(ctx, &changed, nil); err != nil {
+		t.Fatalf("UpdateInstallmentPlan: %v", err)
+	}
+
+	got, err := repo.GetInstallmentPlanByID(ctx, "alice", plan.ID)
+	if err != nil {
+		t.Fatalf("GetInstallmentPlanByID: %v", err)
+	}
+	if got.Title != "TV" || got.CancelledAt == nil || !got.CancelledAt.Equal(cancelledAt) {
+		t.Fatalf("GetInstallmentPlanByID returned %+v after update", got)
+	}
+}
+
+func testUpdateInstallments(t *testing.T, newRepositories InstallmentFactory) {
+	ctx := context.Background()
+	repo, transactions := newRepositories(t)
+	plan := NewInstallmentPlan("alice", day("2026-10-01"), 900, 3)
+	if _, err := repo.NewInstallmentPlan(ctx, plan, plan.Split(), nil); err != nil {
+		t.Fatalf("NewInstallmentPlan: %v", err)
+	}
+
+	installments, err := repo.ListInstallments(ctx, plan)
+	if err != nil {
+		t.Fatalf("ListInstallments: %v", err)
+	}
+	if len(installments) != 3 {
+		t.Fatalf("ListInstallments returned %d installments, want 3", len(installments))
+	}
+	for i, installment := range installments {
+		if installment.Installment != i+1 {
+			t.Fatalf("installment %d listed at position %d", installment.Installment, i+1)
+		}
+	}
+
+	changed := *plan
+	changed.Title = "TV"
+	remaining := append([]model.Transaction(nil), installments[1:]...)
+	stale := append([]model.Transaction(nil), remaining...)
+	for i := range remaining {
+		changed.ApplyTo(&remaining[i])
+	}
+	if _, err := repo.UpdateInstallmentPlan(ctx, &changed, remaining); err != nil {
+		t.Fatalf("UpdateInstallmentPlan: %v", err)
+	}
+	for _, installment := range remaining {
+		if installment.Version != 2 {
+			t.Fatalf("installment %d has version %d after update, want 2", installment.Installment, installment.Version)
+		}
+		got, err := transactions.GetTransactionByID(ctx, "alice", installment.ID, "")
+		if err != nil {
+			t.Fatalf("GetTransactionByID: %v", err)
+		}
+		if got.Title != "TV" || got.Version != 2 {
+			t.Fatalf("installment %d stored as %+v", installment.Installment, got)
+		}
+	}
+	if got, _ := transactions.GetTransactionByID(ctx, "alice", installments[0].ID, ""); got == nil || got.Title != plan.Title {
+		t.Fatalf("installment left out of the update stored as %+v", got)
+	}
+
+	// A stale installment fails the whole update, leaving the plan as is.
+	again := changed
+	again.Title = "Television set"
+	if _, err := repo.UpdateInstallmentPlan(ctx, &again, stale); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale UpdateInstallmentPlan returned %v, want ErrVersionMismatch", err)
+	}
+	if got, _ := repo.GetInstallmentPlanByID(ctx, "alice", plan.ID); got == nil || got.Title != "TV" {
+		t.Fatalf("plan stored as %+v after a stale update", got)
+	}
+}
+
+func testCancelInstallmentPlan(t *testing.T, newRepositories InstallmentFactory) {
+	ctx := context.Background()
+	repo, transactions := newRepositories(t)
+	plan := NewInstallmentPlan("alice", day("2026-10-01"), 900, 3)
+	if _, err := repo.NewInstallmentPlan(ctx, plan, plan.Split(), nil); err != nil {
+		t.Fatalf("NewInstallmentPlan: %v", err)
+	}
+	installments, err := repo.ListInstallments(ctx, plan)
+	if err != nil {
+		t.Fatalf("ListInstallments: %v", err)
+	}
+
+	cancelledAt := day("2026-10-17")
+	cancelled := *plan
+	cancelled.CancelledAt = &cancelledAt
+
+	// A stale installment fails the whole cancellation.
+	stale := append([]model.Transaction(nil), installments[1:]...)
+	stale[1].Version++
+	if _, err := repo.CancelInstallmentPlan(ctx, &cancelled, stale); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale CancelInstallmentPlan returned %v, want ErrVersionMismatch", err)
+	}
+	if left, _ := repo.ListInstallments(ctx, plan); len(left) != 3 {
+		t.Fatalf("%d installments left after a stale cancellation, want 3", len(left))
+	}
+
+	if _, err := repo.CancelInstallmentPlan(ctx, &cancelled, installments[1:]); err != nil {
+		t.Fatalf("CancelInstallmentPlan: %v", err)
+	}
+	got, err := repo.GetInstallmentPlanByID(ctx, "alice", plan.ID)
+	if err != nil {
+		t.Fatalf("GetInstallmentPlanByID: %v", err)
+	}
+	if got.CancelledAt == nil || !got.CancelledAt.Equal(cancelledAt) {
+		t.Fatalf("GetInstallmentPlanByID returned %+v after cancellation", got)
+	}
+	left, err := repo.ListInstallments(ctx, plan)
+	if err != nil {
+		t.Fatalf("ListInstallments: %v", err)
+	}
+	if len(left) != 1 || left[0].ID != installments[0].ID {
+		t.Fatalf("ListInstallments returned %+v after cancellation, want the first installment", left)
+	}
+	listed, err := repository.ListAllTransactions(ctx, transactions, "alice", "2026-01-01", "2026-12-31")
+	if err != nil {
+		t.Fatalf("ListAllTransactions: %v", err)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("listed %d transactions after cancellation, want 1", len(listed))
+	}
+}
+
+func testInstallmentPlanNotFound(t *testing.T, newRepositories InstallmentFactory) {
+	ctx := context.Background()
+	repo, _ := newRepositories(t)
+	missing := NewInstallmentPlan("alice", day("2026-10-01"), 1000, 2)
+
+	if _, err := repo.GetInstallmentPlanByID(ctx, "alice", missing.ID); err == nil {
+		t.Fatal("GetInstallmentPlanByID found a missing plan")
+	}
+	if _, err := repo.UpdateInstallmentPlan(ctx, missing, nil); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("UpdateInstallmentPlan of a missing plan returned %v, want ErrNotFound", err)
+	}
+	if _, err := repo.CancelInstallmentPlan(ctx, missing, nil); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("CancelInstallmentPlan of a missing plan returned %v, want ErrNotFound", err)
+	}
+}
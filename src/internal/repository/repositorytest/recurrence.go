@@ -0,0 +1,156 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// RecurrenceFactory returns an empty repository for a single subtest.
+type RecurrenceFactory func(t *testing.T) repository.RecurrenceRepository
+
+// RunRecurrenceRepositoryTests exercises the behaviour shared by every
+// recurrence storage backend, including the cross-owner due listing the
+// scheduler relies on.
+func RunRecurrenceRepositoryTests(t *testing.T, newRepository RecurrenceFactory) {
+	t.Run("CRUD", func(t *testing.T) { testRecurrenceCRUD(t, newRepository(t)) })
+	t.Run("NotFound", func(t *testing.T) { testRecurrenceNotFound(t, newRepository(t)) })
+	t.Run("ListDue", func(t *testing.T) { testListDueRecurrences(t, newRepository(t)) })
+}
+
+// NewRecurrence builds a keyed monthly recurrence for owner whose next
+// occurrence is next, or an ended one when next is empty.
+func NewRecurrence(owner string, next string) *model.Recurrence {
+	now := time.Now().UTC()
+	recurrence := &model.Recurrence{
+		OwnerID:   owner,
+		ID:        uuid.NewString(),
+		RRule:     "FREQ=MONTHLY;BYMONTHDAY=5",
+		StartDate: "2026-01-05",
+		Type:      model.TransactionTypePurchase,
+		Title:     "Rent",
+		Money:     model.Money{Amount: 150000, Currency: "BRL"},
+		Tags:      []string{"home"},
+		TimeZone:  "America/Sao_Paulo",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if next != "" {
+		recurrence.NextOccurrence = &next
+	}
+	recurrence.SetKeys()
+	return recurrence
+}
+
+func mustCreateRecurrence(t *testing.T, repo repository.RecurrenceRepository, recurrence *model.Recurrence) {
+	t.Helper()
+	if _, err := repo.NewRecurrence(context.Background(), recurrence); err != nil {
+		t.Fatalf("NewRecurrence: %v", err)
+	}
+}
+
+func testRecurrenceCRUD(t *testing.T, repo repository.RecurrenceRepository) {
+	ctx := context.Background()
+	created := NewRecurrence("alice", "2026-11-05")
+	mustCreateRecurrence(t, repo, created)
+
+	got, err := repo.GetRecurrenceByID(ctx, "alice", created.ID)
+	if err != nil {
+		t.Fatalf("GetRecurrenceByID: %v", err)
+	}
+	if got.RRule != created.RRule || got.Money != created.Money || got.NextOccurrence == nil || *got.NextOccurrence != "2026-11-05" {
+		t.Fatalf("GetRecurrenceByID returned %+v", got)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "home" {
+		t.Fatalf("tags = %v, want [home]", got.Tags)
+	}
+	if got.TimeZone != "America/Sao_Paulo" {
+		t.Fatalf("time zone = %q, want America/Sao_Paulo", got.TimeZone)
+	}
+	if got.Version != 1 {
+		t.Fatalf("new recurrence has version %d, want 1", got.Version)
+	}
+
+	changed := *created
+	changed.Title = "Rent and condo"
+	changed.NextOccurrence = nil
+	changed.SetKeys()
+	updated, err := repo.UpdateRecurrence(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateRecurrence: %v", err)
+	}
+	if updated.Title != "Rent and condo" || updated.NextOccurrence != nil || updated.Version != 2 {
+		t.Fatalf("UpdateRecurrence returned %+v", updated)
+	}
+
+	// The copy read before the update is stale now.
+	changed.Title = "Rent"
+	if _, err := repo.UpdateRecurrence(ctx, &changed); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale UpdateRecurrence returned %v, want ErrVersionMismatch", err)
+	}
+	if got, _ := repo.GetRecurrenceByID(ctx, "alice", created.ID); got == nil || got.Title != "Rent and condo" || got.Version != 2 {
+		t.Fatalf("stale update left %+v", got)
+	}
+
+	recurrences, err := repo.ListRecurrences(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListRecurrences: %v", err)
+	}
+	if len(recurrences) != 1 {
+		t.Fatalf("ListRecurrences returned %d recurrences, want 1", len(recurrences))
+	}
+	if others, _ := repo.ListRecurrences(ctx, "bob"); len(others) != 0 {
+		t.Fatalf("bob sees %d of alice's recurrences", len(others))
+	}
+
+	if _, err := repo.DeleteRecurrence(ctx, created); err != nil {
+		t.Fatalf("DeleteRecurrence: %v", err)
+	}
+	if _, err := repo.GetRecurrenceByID(ctx, "alice", created.ID); err == nil {
+		t.Fatal("GetRecurrenceByID found a deleted recurrence")
+	}
+}
+
+func testRecurrenceNotFound(t *testing.T, repo repository.RecurrenceRepository) {
+	ctx := context.Background()
+	missing := NewRecurrence("alice", "2026-11-05")
+
+	if _, err := repo.GetRecurrenceByID(ctx, "alice", missing.ID); err == nil {
+		t.Fatal("GetRecurrenceByID found a missing recurrence")
+	}
+	if _, err := repo.UpdateRecurrence(ctx, missing); err == nil {
+		t.Fatal("UpdateRecurrence accepted a missing recurrence")
+	}
+	if _, err := repo.DeleteRecurrence(ctx, missing); err == nil {
+		t.Fatal("DeleteRecurrence accepted a missing recurrence")
+	}
+}
+
+func testListDueRecurrences(t *testing.T, repo repository.RecurrenceRepository) {
+	ctx := context.Background()
+	alice := NewRecurrence("alice", "2026-10-05")
+	bob := NewRecurrence("bob", "2026-10-17")
+	later := NewRecurrence("alice", "2026-10-18")
+	ended := NewRecurrence("bob", "")
+	for _, recurrence := range []*model.Recurrence{alice, bob, later, ended} {
+		mustCreateRecurrence(t, repo, recurrence)
+	}
+
+	due, err := repo.ListDueRecurrences(ctx, "2026-10-17")
+	if err != nil {
+		t.Fatalf("ListDueRecurrences: %v", err)
+	}
+
+	ids := make([]string, len(due))
+	for i, recurrence := range due {
+		ids[i] = recurrence.ID
+	}
+	if len(ids) != 2 || ids[0] != alice.ID || ids[1] != bob.ID {
+		t.Fatalf("ListDueRecurrences returned %v, want [%s %s]", ids, alice.ID, bob.ID)
+	}
+}
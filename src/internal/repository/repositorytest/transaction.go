@@ -0,0 +1,885 @@
+// Package repositorytest provides the conformance suite every
+// repository.TransactionRepository implementation must pass.
+//
+// Implementations wire it from their own tests:
+//
+//	func TestMemoryTransactionRepository(t *testing.T) {
+//		repositorytest.RunTransactionRepositoryTests(t, func(t *testing.T) repository.TransactionRepository {
+//			return repository.NewMemoryTransactionRepository()
+//		})
+//	}
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joaoleau/muquirango/internal/model"
+	"github.com/joaoleau/muquirango/internal/repository"
+)
+
+// Factory returns an empty repository for a single subtest.
+type Factory func(t *testing.T) repository.TransactionRepository
+
+// RunTransactionRepositoryTests exercises the behaviour shared by every
+// storage backend: owner isolation, not-found semantics and date-range
+// ordering.
+func RunTransactionRepositoryTests(t *testing.T, newRepository Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) { testCreateAndGet(t, newRepository(t)) })
+	t.Run("CreateDuplicate", func(t *testing.T) { testCreateDuplicate(t, newRepository(t)) })
+	t.Run("GetByIDOnly", func(t *testing.T) { testGetByIDOnly(t, newRepository(t)) })
+	t.Run("GetNotFound", func(t *testing.T) { testGetNotFound(t, newRepository(t)) })
+	t.Run("OwnerIsolation", func(t *testing.T) { testOwnerIsolation(t, newRepository(t)) })
+	t.Run("ListDateRange", func(t *testing.T) { testListDateRange(t, newRepository(t)) })
+	t.Run("ListPagination", func(t *testing.T) { testListPagination(t, newRepository(t)) })
+	t.Run("ListByAccount", func(t *testing.T) { testListByAccount(t, newRepository(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepository(t)) })
+	t.Run("UpdateNotFound", func(t *testing.T) { testUpdateNotFound(t, newRepository(t)) })
+	t.Run("Versioning", func(t *testing.T) { testVersioning(t, newRepository(t)) })
+	t.Run("Patch", func(t *testing.T) { testPatch(t, newRepository(t)) })
+	t.Run("MoveDate", func(t *testing.T) { testMoveDate(t, newRepository(t)) })
+	t.Run("ReassignCategory", func(t *testing.T) { testReassignCategory(t, newRepository(t)) })
+	t.Run("ReassignCategoryInChunks", func(t *testing.T) { testReassignCategoryInChunks(t, newRepository(t)) })
+	t.Run("Tags", func(t *testing.T) { testTags(t, newRepository(t)) })
+	t.Run("Transfer", func(t *testing.T) { testTransfer(t, newRepository(t)) })
+	t.Run("TransferAllOrNothing", func(t *testing.T) { testTransferAllOrNothing(t, newRepository(t)) })
+	t.Run("Idempotency", func(t *testing.T) { testIdempotency(t, newRepository(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepository(t)) })
+	t.Run("DeleteNotFound", func(t *testing.T) { testDeleteNotFound(t, newRepository(t)) })
+	t.Run("MonthlyAggregates", func(t *testing.T) { testMonthlyAggregates(t, newRepository(t)) })
+}
+
+// NewTransaction builds a keyed transaction for owner that occurred, and was
+// recorded, at the given day.
+func NewTransaction(owner string, occurredAt time.Time) *model.Transaction {
+	description := "conformance"
+	transaction := &model.Transaction{
+		OwnerID:     owner,
+		ID:          uuid.NewString(),
+		Type:        model.TransactionTypePurchase,
+		Title:       "Groceries",
+		Description: &description,
+		Money:       model.Money{Amount: 1500, Currency: "BRL"},
+		OccurredAt:  occurredAt.UTC(),
+		CreatedAt:   occurredAt.UTC(),
+	}
+	transaction.SetKeys()
+	return transaction
+}
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t.Add(12 * time.Hour)
+}
+
+func mustCreate(t *testing.T, repo repository.TransactionRepository, transaction *model.Transaction) {
+	t.Helper()
+	if _, err := repo.NewTransaction(context.Background(), transaction, nil); err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+}
+
+func testCreateAndGet(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+
+	got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "2026-10-01")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+
+	if got.ID != created.ID || got.Title != created.Title || got.Money != created.Money || got.Type != created.Type {
+		t.Fatalf("GetTransactionByID returned %+v, want %+v", got, created)
+	}
+	if got.Description == nil || *got.Description != *created.Description {
+		t.Fatalf("description = %v, want %q", got.Description, *created.Description)
+	}
+	if !got.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("created_at = %v, want %v", got.CreatedAt, created.CreatedAt)
+	}
+}
+
+func testCreateDuplicate(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+
+	duplicate := *created
+	duplicate.Title = "Overwritten"
+	if _, err := repo.NewTransaction(ctx, &duplicate, nil); !errors.Is(err, repository.ErrTransactionExists) {
+		t.Fatalf("NewTransaction with a taken key returned %v, want ErrTransactionExists", err)
+	}
+
+	got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "2026-10-01")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if got.Title != created.Title {
+		t.Fatalf("title = %q after a rejected duplicate, want %q", got.Title, created.Title)
+	}
+}
+
+func testGetByIDOnly(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-09-15"))
+	mustCreate(t, repo, created)
+
+	got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID without createdAt: %v", err)
+	}
+	if got.ID != created.ID || got.SK != created.SK {
+		t.Fatalf("GetTransactionByID returned %+v, want %+v", got, created)
+	}
+
+	if got, err := repo.GetTransactionByID(ctx, "bob", created.ID, ""); err == nil {
+		t.Fatalf("bob read alice's transaction by ID: %+v", got)
+	}
+
+	if _, err := repo.DeleteTransaction(ctx, created); err != nil {
+		t.Fatalf("DeleteTransaction: %v", err)
+	}
+	if got, err := repo.GetTransactionByID(ctx, "alice", created.ID, ""); err == nil {
+		t.Fatalf("deleted transaction still found by ID: %+v", got)
+	}
+}
+
+func testGetNotFound(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+
+	if got, err := repo.GetTransactionByID(ctx, "alice", uuid.NewString(), "2026-10-01"); err == nil {
+		t.Fatalf("GetTransactionByID of unknown ID returned %+v, want error", got)
+	}
+	if got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "2026-10-02"); err == nil {
+		t.Fatalf("GetTransactionByID with wrong date returned %+v, want error", got)
+	}
+}
+
+func testOwnerIsolation(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+
+	if got, err := repo.GetTransactionByID(ctx, "bob", created.ID, "2026-10-01"); err == nil {
+		t.Fatalf("bob read alice's transaction: %+v", got)
+	}
+
+	page, err := repo.ListTransactions(ctx, "bob", "2026-10-01", "2026-10-01", repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	if len(page.Items) != 0 {
+		t.Fatalf("bob listed %d of alice's transactions", len(page.Items))
+	}
+}
+
+func testListDateRange(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	before := NewTransaction("alice", day("2026-09-30"))
+	third := NewTransaction("alice", day("2026-10-03"))
+	first := NewTransaction("alice", day("2026-10-01"))
+	second := NewTransaction("alice", day("2026-10-02"))
+	after := NewTransaction("alice", day("2026-10-04"))
+	for _, transaction := range []*model.Transaction{before, third, first, second, after} {
+		mustCreate(t, repo, transaction)
+	}
+
+	page, err := repo.ListTransactions(ctx, "alice", "2026-10-01", "2026-10-03", repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+
+	assertIDs(t, page.Items, first.ID, second.ID, third.ID)
+}
+
+func testListByAccount(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	checking, wallet := "checking", "wallet"
+
+	first := NewTransaction("alice", day("2026-10-01"))
+	first.AccountID = &checking
+	cash := NewTransaction("alice", day("2026-10-02"))
+	cash.AccountID = &wallet
+	unassigned := NewTransaction("alice", day("2026-10-02"))
+	second := NewTransaction("alice", day("2026-10-03"))
+	second.AccountID = &checking
+	for _, transaction := range []*model.Transaction{first, cash, unassigned, second} {
+		mustCreate(t, repo, transaction)
+	}
+
+	page, err := repo.ListTransactions(ctx, "alice", "2026-10-01", "2026-10-31", repository.ListOptions{AccountID: checking})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	assertIDs(t, page.Items, first.ID, second.ID)
+
+	page, err = repo.ListTransactions(ctx, "alice", "2026-10-01", "2026-10-31", repository.ListOptions{AccountID: checking, Limit: 1})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	assertIDs(t, page.Items, first.ID)
+	if page.NextKey == nil {
+		t.Fatal("first filtered page has no next key")
+	}
+}
+
+func testListPagination(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	var want []string
+	for _, date := range []string{"2026-10-01", "2026-10-01", "2026-10-02", "2026-10-03", "2026-10-04"} {
+		transaction := NewTransaction("alice", day(date))
+		mustCreate(t, repo, transaction)
+	}
+
+	all, err := repository.ListAllTransactions(ctx, repo, "alice", "2026-10-01", "2026-10-04")
+	if err != nil {
+		t.Fatalf("ListAllTransactions: %v", err)
+	}
+	for _, transaction := range all {
+		want = append(want, transaction.ID)
+	}
+	assertIDs(t, all, want...)
+
+	var (
+		got     []model.Transaction
+		options = repository.ListOptions{Limit: 2}
+	)
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatal("pagination did not terminate")
+		}
+
+		page, err := repo.ListTransactions(ctx, "alice", "2026-10-01", "2026-10-04", options)
+		if err != nil {
+			t.Fatalf("ListTransactions: %v", err)
+		}
+		if len(page.Items) > options.Limit {
+			t.Fatalf("page has %d items, limit is %d", len(page.Items), options.Limit)
+		}
+		got = append(got, page.Items...)
+
+		if page.NextKey == nil {
+			break
+		}
+		options.StartKey = page.NextKey
+	}
+	assertIDs(t, got, want...)
+}
+
+func assertIDs(t *testing.T, transactions []model.Transaction, want ...string) {
+	t.Helper()
+	if len(transactions) != len(want) {
+		t.Fatalf("got %d transactions, want %d", len(transactions), len(want))
+	}
+	for i, transaction := range transactions {
+		if transaction.ID != want[i] {
+			t.Fatalf("item %d = %s, want %s", i, transaction.ID, want[i])
+		}
+	}
+}
+
+func testUpdate(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+
+	changed := *created
+	changed.Title = "Rent"
+	changed.Type = model.TransactionTypeIncome
+	changed.Money = model.Money{Amount: 90000, Currency: "USD"}
+	changed.Description = nil
+	changed.UpdatedAt = day("2026-10-05").UTC()
+
+	updated, err := repo.UpdateTransaction(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateTransaction: %v", err)
+	}
+	if updated.Title != "Rent" || updated.Amount != 90000 || updated.Currency != "USD" || updated.Type != model.TransactionTypeIncome {
+		t.Fatalf("UpdateTransaction returned %+v", updated)
+	}
+
+	got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "2026-10-01")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if got.Title != "Rent" || got.Amount != 90000 || got.Currency != "USD" || got.Description != nil {
+		t.Fatalf("stored transaction = %+v", got)
+	}
+	if !got.UpdatedAt.Equal(changed.UpdatedAt) {
+		t.Fatalf("updated_at = %v, want %v", got.UpdatedAt, changed.UpdatedAt)
+	}
+	if !got.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("created_at changed to %v", got.CreatedAt)
+	}
+}
+
+func testUpdateNotFound(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	missing := NewTransaction("alice", day("2026-10-01"))
+
+	if _, err := repo.UpdateTransaction(ctx, missing); err == nil {
+		t.Fatal("UpdateTransaction of unknown transaction succeeded")
+	}
+	if got, err := repo.GetTransactionByID(ctx, "alice", missing.ID, "2026-10-01"); err == nil {
+		t.Fatalf("UpdateTransaction created a phantom item: %+v", got)
+	}
+}
+
+func testVersioning(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+	if created.Version != 1 {
+		t.Fatalf("created version = %d, want 1", created.Version)
+	}
+
+	changed := *created
+	changed.Title = "Rent"
+	updated, err := repo.UpdateTransaction(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateTransaction: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Fatalf("updated version = %d, want 2", updated.Version)
+	}
+
+	stale := *created
+	stale.Title = "Lost update"
+	if _, err := repo.UpdateTransaction(ctx, &stale); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale UpdateTransaction returned %v, want ErrVersionMismatch", err)
+	}
+	if _, err := repo.DeleteTransaction(ctx, &stale); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale DeleteTransaction returned %v, want ErrVersionMismatch", err)
+	}
+	got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if got.Title != "Rent" || got.Version != 2 {
+		t.Fatalf("stored transaction = %+v", got)
+	}
+
+	if _, err := repo.DeleteTransaction(ctx, got); err != nil {
+		t.Fatalf("DeleteTransaction: %v", err)
+	}
+	if _, err := repo.DeleteTransaction(ctx, got); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("second DeleteTransaction returned %v, want ErrNotFound", err)
+	}
+}
+
+func testPatch(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	checking := "checking"
+	created := NewTransaction("alice", day("2026-10-01"))
+	created.AccountID = &checking
+	created.Tags = []string{"home"}
+	mustCreate(t, repo, created)
+
+	title, currency := "Rent", "EUR"
+	patch := &model.TransactionPatch{
+		Title:       model.Optional[string]{Set: true, Value: &title},
+		Description: model.Optional[string]{Set: true},
+		Currency:    model.Optional[string]{Set: true, Value: &currency},
+		UpdatedAt:   day("2026-10-05").UTC(),
+	}
+	patched, err := repo.PatchTransaction(ctx, created, patch)
+	if err != nil {
+		t.Fatalf("PatchTransaction: %v", err)
+	}
+	if patched.Version != 2 {
+		t.Fatalf("patched version = %d, want 2", patched.Version)
+	}
+
+	got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if got.Title != "Rent" || got.Description != nil || got.Currency != "EUR" {
+		t.Fatalf("patched fields = %q, %v, %q", got.Title, got.Description, got.Currency)
+	}
+	if got.Amount != created.Amount || got.Type != created.Type || got.AccountID == nil || *got.AccountID != checking {
+		t.Fatalf("fields left out of the patch changed: %+v", got)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "home" {
+		t.Fatalf("tags = %v, want [home]", got.Tags)
+	}
+	if !got.UpdatedAt.Equal(patch.UpdatedAt) {
+		t.Fatalf("updated_at = %v, want %v", got.UpdatedAt, patch.UpdatedAt)
+	}
+
+	if _, err := repo.PatchTransaction(ctx, created, patch); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale PatchTransaction returned %v, want ErrVersionMismatch", err)
+	}
+	missing := NewTransaction("alice", day("2026-10-01"))
+	if _, err := repo.PatchTransaction(ctx, missing, patch); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("PatchTransaction of unknown transaction returned %v, want ErrNotFound", err)
+	}
+}
+
+func testMoveDate(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+
+	changed := *created
+	changed.Title = "Rent"
+	changed.OccurredAt = day("2026-09-15").UTC()
+	changed.UpdatedAt = day("2026-10-05").UTC()
+	updated, err := repo.UpdateTransaction(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateTransaction: %v", err)
+	}
+	if updated.Version != 2 || updated.Title != "Rent" || updated.SK != model.TransactionDateKey("2026-09-15", created.ID) {
+		t.Fatalf("UpdateTransaction returned %+v", updated)
+	}
+	assertListed(t, repo, "2026-10-01", nil)
+	assertListed(t, repo, "2026-09-15", []string{created.ID})
+
+	got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "2026-09-15")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if !got.OccurredAt.Equal(changed.OccurredAt) || !got.CreatedAt.Equal(created.CreatedAt) || got.Description == nil {
+		t.Fatalf("moved transaction = %+v", got)
+	}
+
+	if _, err := repo.UpdateTransaction(ctx, &changed); err == nil {
+		t.Fatal("UpdateTransaction of a stale version under the old key succeeded")
+	}
+
+	occurredAt := day("2026-11-02").UTC()
+	patched, err := repo.PatchTransaction(ctx, got, &model.TransactionPatch{OccurredAt: &occurredAt, UpdatedAt: occurredAt})
+	if err != nil {
+		t.Fatalf("PatchTransaction: %v", err)
+	}
+	if patched.Version != 3 || patched.Title != "Rent" || !patched.OccurredAt.Equal(occurredAt) {
+		t.Fatalf("PatchTransaction returned %+v", patched)
+	}
+	assertListed(t, repo, "2026-09-15", nil)
+	assertListed(t, repo, "2026-11-02", []string{created.ID})
+}
+
+// assertListed checks which transactions of alice are listed on date.
+func assertListed(t *testing.T, repo repository.TransactionRepository, date string, want []string) {
+	t.Helper()
+	listed, err := repository.ListAllTransactions(context.Background(), repo, "alice", date, date)
+	if err != nil {
+		t.Fatalf("ListAllTransactions: %v", err)
+	}
+	if len(listed) != len(want) {
+		t.Fatalf("listed %d transactions on %s, want %d", len(listed), date, len(want))
+	}
+	for i, transaction := range listed {
+		if transaction.ID != want[i] {
+			t.Fatalf("item %d on %s = %s, want %s", i, date, transaction.ID, want[i])
+		}
+	}
+}
+
+func testTransfer(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	checking, savings := "checking", "savings"
+
+	from := NewTransaction("alice", day("2026-10-05"))
+	from.AccountID = &checking
+	transfer := &model.Transfer{From: *from, To: *NewTransaction("alice", day("2026-10-05"))}
+	transfer.To.AccountID = &savings
+	transfer.Link()
+	if _, err := repo.NewTransfer(ctx, transfer, nil); err != nil {
+		t.Fatalf("NewTransfer: %v", err)
+	}
+	if _, err := repo.NewTransfer(ctx, transfer, nil); !errors.Is(err, repository.ErrTransactionExists) {
+		t.Fatalf("second NewTransfer returned %v, want ErrTransactionExists", err)
+	}
+
+	in, err := repo.GetTransactionByID(ctx, "alice", transfer.To.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if in.Direction != model.TransferIn || in.CounterpartID == nil || *in.CounterpartID != transfer.From.ID {
+		t.Fatalf("incoming leg = %+v", in)
+	}
+
+	changed := *transfer
+	changed.From.Amount, changed.To.Amount = 700, 700
+	updated, err := repo.UpdateTransfer(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateTransfer: %v", err)
+	}
+	if _, err := repo.UpdateTransfer(ctx, &changed); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale UpdateTransfer returned %v, want ErrVersionMismatch", err)
+	}
+	page, err := repo.ListTransactions(ctx, "alice", "2026-10-05", "2026-10-05", repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	for _, leg := range page.Items {
+		if leg.Amount != 700 || leg.Type != model.TransactionTypeTransfer {
+			t.Fatalf("leg after update = %+v", leg)
+		}
+	}
+
+	if _, err := repo.DeleteTransfer(ctx, transfer); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Fatalf("stale DeleteTransfer returned %v, want ErrVersionMismatch", err)
+	}
+	if _, err := repo.DeleteTransfer(ctx, updated); err != nil {
+		t.Fatalf("DeleteTransfer: %v", err)
+	}
+	page, err = repo.ListTransactions(ctx, "alice", "2026-10-05", "2026-10-05", repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	assertIDs(t, page.Items)
+	if _, err := repo.DeleteTransfer(ctx, updated); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("DeleteTransfer of a deleted transfer returned %v, want ErrNotFound", err)
+	}
+}
+
+// testTransferAllOrNothing stores one leg of a transfer up front, so writing
+// the other one must not happen either.
+func testTransferAllOrNothing(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	transfer := &model.Transfer{From: *NewTransaction("alice", day("2026-10-05")), To: *NewTransaction("alice", day("2026-10-05"))}
+	transfer.Link()
+	taken := transfer.To
+	mustCreate(t, repo, &taken)
+
+	if _, err := repo.NewTransfer(ctx, transfer, nil); !errors.Is(err, repository.ErrTransactionExists) {
+		t.Fatalf("NewTransfer over a stored leg returned %v, want ErrTransactionExists", err)
+	}
+	if _, err := repo.GetTransactionByID(ctx, "alice", transfer.From.ID, ""); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("outgoing leg of a failed transfer: %v, want ErrNotFound", err)
+	}
+	page, err := repo.ListTransactions(ctx, "alice", "2026-10-05", "2026-10-05", repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	assertIDs(t, page.Items, taken.ID)
+}
+
+// NewIdempotencyRecord builds a keyed record for owner that expires after
+// ttl, which may be negative for one already expired.
+func NewIdempotencyRecord(owner string, key string, ttl time.Duration) *model.IdempotencyRecord {
+	now := time.Now().UTC()
+	record := &model.IdempotencyRecord{
+		OwnerID:     owner,
+		Key:         key,
+		Fingerprint: "fingerprint",
+		Status:      201,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl).Unix(),
+	}
+	record.SetKeys()
+	return record
+}
+
+func testIdempotency(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	first := NewTransaction("alice", day("2026-10-01"))
+	if _, err := repo.NewTransaction(ctx, first, NewIdempotencyRecord("alice", "retry", time.Hour)); err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+
+	record, err := repo.GetIdempotencyRecord(ctx, "alice", "retry")
+	if err != nil {
+		t.Fatalf("GetIdempotencyRecord: %v", err)
+	}
+	if record.Status != 201 || record.Fingerprint != "fingerprint" || !strings.Contains(record.Response, first.ID) {
+		t.Fatalf("stored record = %+v", record)
+	}
+	if _, err := repo.GetIdempotencyRecord(ctx, "bob", "retry"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("GetIdempotencyRecord of another owner returned %v, want ErrNotFound", err)
+	}
+
+	retry := NewTransaction("alice", day("2026-10-01"))
+	if _, err := repo.NewTransaction(ctx, retry, NewIdempotencyRecord("alice", "retry", time.Hour)); !errors.Is(err, repository.ErrIdempotencyKeyUsed) {
+		t.Fatalf("NewTransaction with a used key returned %v, want ErrIdempotencyKeyUsed", err)
+	}
+	if got, err := repo.GetTransactionByID(ctx, "alice", retry.ID, ""); err == nil {
+		t.Fatalf("rejected retry was stored: %+v", got)
+	}
+
+	transfer := &model.Transfer{From: *NewTransaction("alice", day("2026-10-02")), To: *NewTransaction("alice", day("2026-10-02"))}
+	transfer.Link()
+	if _, err := repo.NewTransfer(ctx, transfer, NewIdempotencyRecord("alice", "retry", time.Hour)); !errors.Is(err, repository.ErrIdempotencyKeyUsed) {
+		t.Fatalf("NewTransfer with a used key returned %v, want ErrIdempotencyKeyUsed", err)
+	}
+	page, err := repo.ListTransactions(ctx, "alice", "2026-10-02", "2026-10-02", repository.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	assertIDs(t, page.Items)
+
+	expired := NewTransaction("alice", day("2026-10-03"))
+	if _, err := repo.NewTransaction(ctx, expired, NewIdempotencyRecord("alice", "old", -time.Minute)); err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if _, err := repo.GetIdempotencyRecord(ctx, "alice", "old"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("GetIdempotencyRecord of an expired record returned %v, want ErrNotFound", err)
+	}
+	if _, err := repo.NewTransaction(ctx, NewTransaction("alice", day("2026-10-03")), NewIdempotencyRecord("alice", "old", time.Hour)); err != nil {
+		t.Fatalf("NewTransaction reusing an expired key: %v", err)
+	}
+}
+
+func testDelete(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	created := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, created)
+
+	deleted, err := repo.DeleteTransaction(ctx, created)
+	if err != nil {
+		t.Fatalf("DeleteTransaction: %v", err)
+	}
+	if deleted.ID != created.ID {
+		t.Fatalf("DeleteTransaction returned %s, want %s", deleted.ID, created.ID)
+	}
+
+	if got, err := repo.GetTransactionByID(ctx, "alice", created.ID, "2026-10-01"); err == nil {
+		t.Fatalf("transaction still readable after delete: %+v", got)
+	}
+}
+
+func testDeleteNotFound(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	if _, err := repo.DeleteTransaction(ctx, NewTransaction("alice", day("2026-10-01"))); err == nil {
+		t.Fatal("DeleteTransaction of unknown transaction succeeded")
+	}
+}
+
+func testReassignCategory(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	food, travel := "food", "travel"
+
+	categorized := NewTransaction("alice", day("2026-10-01"))
+	categorized.CategoryID = &food
+	other := NewTransaction("alice", day("2026-10-02"))
+	other.CategoryID = &travel
+	foreign := NewTransaction("bob", day("2026-10-01"))
+	foreign.CategoryID = &food
+	for _, transaction := range []*model.Transaction{categorized, other, foreign} {
+		mustCreate(t, repo, transaction)
+	}
+
+	count, err := repo.ReassignCategory(ctx, "alice", food, &travel)
+	if err != nil {
+		t.Fatalf("ReassignCategory: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("ReassignCategory changed %d transactions, want 1", count)
+	}
+
+	got, err := repo.GetTransactionByID(ctx, "alice", categorized.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if got.CategoryID == nil || *got.CategoryID != travel {
+		t.Fatalf("category_id = %v, want %q", got.CategoryID, travel)
+	}
+	if got.Version != 2 {
+		t.Fatalf("reassigned transaction has version %d, want 2", got.Version)
+	}
+
+	untouched, err := repo.GetTransactionByID(ctx, "bob", foreign.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if untouched.CategoryID == nil || *untouched.CategoryID != food {
+		t.Fatalf("bob's transaction was reassigned to %v", untouched.CategoryID)
+	}
+
+	if _, err := repo.ReassignCategory(ctx, "alice", travel, nil); err != nil {
+		t.Fatalf("ReassignCategory to nil: %v", err)
+	}
+	got, err = repo.GetTransactionByID(ctx, "alice", other.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if got.CategoryID != nil {
+		t.Fatalf("category_id = %q, want none", *got.CategoryID)
+	}
+}
+
+// testReassignCategoryInChunks moves more transactions than DynamoDB writes
+// in a single transaction.
+func testReassignCategoryInChunks(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	food, travel := "food", "travel"
+
+	for range 150 {
+		transaction := NewTransaction("alice", day("2026-10-01"))
+		transaction.CategoryID = &food
+		mustCreate(t, repo, transaction)
+	}
+
+	count, err := repo.ReassignCategory(ctx, "alice", food, &travel)
+	if err != nil {
+		t.Fatalf("ReassignCategory: %v", err)
+	}
+	if count != 150 {
+		t.Fatalf("ReassignCategory changed %d transactions, want 150", count)
+	}
+	if count, err := repo.ReassignCategory(ctx, "alice", food, &travel); err != nil || count != 0 {
+		t.Fatalf("second ReassignCategory changed %d transactions: %v", count, err)
+	}
+}
+
+func testTags(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+
+	trip := NewTransaction("alice", day("2026-10-01"))
+	trip.Tags = []string{"reimbursable", "trip-2026"}
+	gift := NewTransaction("alice", day("2026-10-02"))
+	gift.Tags = []string{"gift"}
+	travel := NewTransaction("alice", day("2026-10-03"))
+	travel.Tags = []string{"travel", "trip-2026"}
+	untagged := NewTransaction("alice", day("2026-10-03"))
+	foreign := NewTransaction("bob", day("2026-10-01"))
+	foreign.Tags = []string{"trip-2026"}
+	for _, transaction := range []*model.Transaction{trip, gift, travel, untagged, foreign} {
+		mustCreate(t, repo, transaction)
+	}
+
+	page, err := repo.ListTransactions(ctx, "alice", "2026-10-01", "2026-10-31", repository.ListOptions{Tag: "trip-2026"})
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	assertIDs(t, page.Items, trip.ID, travel.ID)
+
+	count, err := repo.RenameTag(ctx, "alice", "trip-2026", "travel")
+	if err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("RenameTag changed %d transactions, want 2", count)
+	}
+
+	usage, err := repo.ListTags(ctx, "alice")
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	want := []model.TagUsage{{Tag: "gift", Count: 1}, {Tag: "reimbursable", Count: 1}, {Tag: "travel", Count: 2}}
+	if len(usage) != len(want) {
+		t.Fatalf("ListTags returned %+v, want %+v", usage, want)
+	}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Fatalf("ListTags returned %+v, want %+v", usage, want)
+		}
+	}
+
+	merged, err := repo.GetTransactionByID(ctx, "alice", travel.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if len(merged.Tags) != 1 || merged.Tags[0] != "travel" {
+		t.Fatalf("merged tags = %v, want [travel]", merged.Tags)
+	}
+	if merged.Version != 2 {
+		t.Fatalf("renamed transaction has version %d, want 2", merged.Version)
+	}
+
+	untouched, err := repo.GetTransactionByID(ctx, "bob", foreign.ID, "")
+	if err != nil {
+		t.Fatalf("GetTransactionByID: %v", err)
+	}
+	if len(untouched.Tags) != 1 || untouched.Tags[0] != "trip-2026" {
+		t.Fatalf("bob's tags were renamed to %v", untouched.Tags)
+	}
+}
+
+func testMonthlyAggregates(t *testing.T, repo repository.TransactionRepository) {
+	ctx := context.Background()
+	purchase := NewTransaction("alice", day("2026-10-01"))
+	mustCreate(t, repo, purchase)
+	income := NewTransaction("alice", day("2026-10-20"))
+	income.Type = model.TransactionTypeIncome
+	income.Amount = 9000
+	mustCreate(t, repo, income)
+	investment := NewTransaction("alice", day("2026-11-03"))
+	investment.Type = model.TransactionTypeInvestment
+	investment.Currency = "USD"
+	mustCreate(t, repo, investment)
+	mustCreate(t, repo, NewTransaction("bob", day("2026-10-01")))
+
+	from := NewTransaction("alice", day("2026-10-05"))
+	transfer := &model.Transfer{From: *from, To: *NewTransaction("alice", day("2026-10-05"))}
+	transfer.Link()
+	if _, err := repo.NewTransfer(ctx, transfer, nil); err != nil {
+		t.Fatalf("NewTransfer: %v", err)
+	}
+
+	assertAggregates(t, repo, "2026-01", "2026-12",
+		"2026-10 BRL income 9000/1 purchases 1500/1 investments 0/0",
+		"2026-11 USD income 0/0 purchases 0/0 investments 1500/1",
+	)
+
+	changed := *purchase
+	changed.Amount = 2000
+	updated, err := repo.UpdateTransaction(ctx, &changed)
+	if err != nil {
+		t.Fatalf("UpdateTransaction: %v", err)
+	}
+	kind := model.TransactionTypeInvestment
+	moved := day("2026-11-10").UTC()
+	_, err = repo.PatchTransaction(ctx, updated, &model.TransactionPatch{
+		Type:       model.Optional[model.TransactionType]{Set: true, Value: &kind},
+		OccurredAt: &moved,
+		UpdatedAt:  moved,
+	})
+	if err != nil {
+		t.Fatalf("PatchTransaction: %v", err)
+	}
+	if _, err := repo.DeleteTransaction(ctx, investment); err != nil {
+		t.Fatalf("DeleteTransaction: %v", err)
+	}
+
+	assertAggregates(t, repo, "2026-01", "2026-12",
+		"2026-10 BRL income 9000/1 purchases 0/0 investments 0/0",
+		"2026-11 BRL income 0/0 purchases 0/0 investments 2000/1",
+	)
+	assertAggregates(t, repo, "2026-11", "2026-11",
+		"2026-11 BRL income 0/0 purchases 0/0 investments 2000/1",
+	)
+
+	rebuilt, err := repo.RebuildMonthlyAggregates(ctx, "alice")
+	if err != nil {
+		t.Fatalf("RebuildMonthlyAggregates: %v", err)
+	}
+	if len(rebuilt) != 2 {
+		t.Fatalf("RebuildMonthlyAggregates returned %d aggregates, want 2", len(rebuilt))
+	}
+	assertAggregates(t, repo, "2026-01", "2026-12",
+		"2026-10 BRL income 9000/1 purchases 0/0 investments 0/0",
+		"2026-11 BRL income 0/0 purchases 0/0 investments 2000/1",
+	)
+}
+
+// assertAggregates checks the monthly aggregates of alice from start
+// through end.
+func assertAggregates(t *testing.T, repo repository.TransactionRepository, start string, end string, want ...string) {
+	t.Helper()
+	aggregates, err := repo.ListMonthlyAggregates(context.Background(), "alice", start, end)
+	if err != nil {
+		t.Fatalf("ListMonthlyAggregates: %v", err)
+	}
+	got := make([]string, len(aggregates))
+	for i, a := range aggregates {
+		got[i] = fmt.Sprintf("%s %s income %d/%d purchases %d/%d investments %d/%d",
+			a.Month, a.Currency, a.Income, a.IncomeCount, a.Purchases, a.PurchaseCount, a.Investments, a.InvestmentCount)
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("ListMonthlyAggregates returned\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+const accountColumns = `owner_id, id, name, kind, currency, opening_balance, closing_day, due_day, created_at, updated_at`
+
+type SQLiteAccountRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteAccountRepository(db *sql.DB) *SQLiteAccountRepo {
+	return &SQLiteAccountRepo{
+		db: db,
+	}
+}
+
+func (r *SQLiteAccountRepo) NewAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to create new account", zap.String("account_id", account.ID))
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO accounts (`+accountColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		account.OwnerID,
+		account.ID,
+		account.Name,
+		account.Kind,
+		account.Currency,
+		account.OpeningBalance,
+		account.ClosingDay,
+		account.DueDay,
+		formatTime(account.CreatedAt),
+		formatTime(account.UpdatedAt),
+	)
+	if err != nil {
+		logger.Error("Failed to add account to SQLite", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to add account: %w", storageError(err))
+	}
+
+	logger.Info("Account successfully created", zap.String("account_id", account.ID))
+	return account, nil
+}
+
+func (r *SQLiteAccountRepo) UpdateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to update account", zap.String("account_id", account.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE accounts SET name = ?, kind = ?, currency = ?, opening_balance = ?, closing_day = ?, due_day = ?, updated_at = ?
+		WHERE owner_id = ? AND id = ?
+		RETURNING `+accountColumns,
+		account.Name,
+		account.Kind,
+		account.Currency,
+		account.OpeningBalance,
+		account.ClosingDay,
+		account.DueDay,
+		formatTime(account.UpdatedAt),
+		account.OwnerID,
+		account.ID,
+	)
+	updated, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No account found to update", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("account with ID '%s': %w", account.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to update account in SQLite", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to update account with ID '%s': %w", account.ID, storageError(err))
+	}
+
+	logger.Info("Account successfully updated", zap.String("account_id", account.ID))
+	return updated, nil
+}
+
+func (r *SQLiteAccountRepo) ListAccounts(ctx context.Context, owner string) ([]model.Account, error) {
+	logger.Info("Attempting to list accounts", zap.String("owner", owner))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+accountColumns+` FROM accounts WHERE owner_id = ? ORDER BY id`,
+		owner,
+	)
+	if err != nil {
+		logger.Error("Failed to query accounts from SQLite", err)
+		return nil, fmt.Errorf("failed to query accounts from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	accounts := []model.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			logger.Error("Failed to scan accounts list", err)
+			return nil, fmt.Errorf("failed to scan accounts list: %w", storageError(err))
+		}
+		accounts = append(accounts, *account)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate accounts list", err)
+		return nil, fmt.Errorf("failed to query accounts from table: %w", storageError(err))
+	}
+
+	logger.Info("Accounts successfully retrieved", zap.Int("count", len(accounts)))
+	return accounts, nil
+}
+
+func (r *SQLiteAccountRepo) GetAccountByID(ctx context.Context, owner string, id string) (*model.Account, error) {
+	logger.Info("Attempting to fetch account", zap.String("owner", owner), zap.String("account_id", id))
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+accountColumns+` FROM accounts WHERE owner_id = ? AND id = ?`,
+		owner, id,
+	)
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Account not found", err, zap.String("account_id", id))
+		return nil, fmt.Errorf("account with ID '%s': %w", id, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch account from SQLite", err, zap.String("account_id", id))
+		return nil, fmt.Errorf("failed to get account with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Account successfully retrieved", zap.String("account_id", id))
+	return account, nil
+}
+
+func (r *SQLiteAccountRepo) DeleteAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	logger.Info("Attempting to delete account", zap.String("account_id", account.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`DELETE FROM accounts WHERE owner_id = ? AND id = ? RETURNING `+accountColumns,
+		account.OwnerID, account.ID,
+	)
+	deleted, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No account found to delete", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("no account found to delete with ID '%s': %w", account.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to delete account from SQLite", err, zap.String("account_id", account.ID))
+		return nil, fmt.Errorf("failed to delete account with ID '%s': %w", account.ID, storageError(err))
+	}
+
+	logger.Info("Account successfully deleted", zap.String("account_id", account.ID))
+	return deleted, nil
+}
+
+func scanAccount(row rowScanner) (*model.Account, error) {
+	var (
+		account   model.Account
+		createdAt string
+		updatedAt string
+		err       error
+	)
+
+	if err := row.Scan(
+		&account.OwnerID,
+		&account.ID,
+		&account.Name,
+		&account.Kind,
+		&account.Currency,
+		&account.OpeningBalance,
+		&account.ClosingDay,
+		&account.DueDay,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if account.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if account.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	account.SetKeys()
+	return &account, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+// ListMonthlyAggregates sums the transaction rows on every call, which the
+// occurred_on index keeps cheap, so the aggregates of this backend never
+// drift.
+func (r *SQLiteTransactionRepo) ListMonthlyAggregates(ctx context.Context, owner string, startMonth string, endMonth string) ([]model.MonthlyAggregate, error) {
+	logger.Info("Attempting to list monthly aggregates", zap.String("owner", owner), zap.String("start_month", startMonth), zap.String("end_month", endMonth))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT substr(occurred_on, 1, 7) AS month, currency,
+			SUM(CASE WHEN type = 'INCOME' THEN amount ELSE 0 END),
+			SUM(type = 'INCOME'),
+			SUM(CASE WHEN type = 'PURCHASE' THEN amount ELSE 0 END),
+			SUM(type = 'PURCHASE'),
+			SUM(CASE WHEN type = 'INVESTMENT' THEN amount ELSE 0 END),
+			SUM(type = 'INVESTMENT')
+		FROM transactions
+		WHERE owner_id = ? AND occurred_on BETWEEN ? AND ?
+		AND type IN ('INCOME', 'PURCHASE', 'INVESTMENT')
+		GROUP BY month, currency
+		ORDER BY month, currency`,
+		owner, startMonth+"-01", endMonth+"-31",
+	)
+	if err != nil {
+		logger.Error("Failed to query monthly aggregates from SQLite", err)
+		return nil, fmt.Errorf("failed to query monthly aggregates from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	aggregates := []model.MonthlyAggregate{}
+	for rows.Next() {
+		aggregate := model.MonthlyAggregate{OwnerID: owner}
+		err := rows.Scan(
+			&aggregate.Month,
+			&aggregate.Currency,
+			&aggregate.Income,
+			&aggregate.IncomeCount,
+			&aggregate.Purchases,
+			&aggregate.PurchaseCount,
+			&aggregate.Investments,
+			&aggregate.InvestmentCount,
+		)
+		if err != nil {
+			logger.Error("Failed to scan monthly aggregates", err)
+			return nil, fmt.Errorf("failed to scan monthly aggregates: %w", storageError(err))
+		}
+		aggregate.SetKeys()
+		aggregates = append(aggregates, aggregate)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate monthly aggregates", err)
+		return nil, fmt.Errorf("failed to query monthly aggregates from table: %w", storageError(err))
+	}
+
+	logger.Info("Monthly aggregates successfully retrieved", zap.Int("count", len(aggregates)))
+	return aggregates, nil
+}
+
+// RebuildMonthlyAggregates has nothing to repair, the aggregates being
+// summed on read, and returns them all.
+func (r *SQLiteTransactionRepo) RebuildMonthlyAggregates(ctx context.Context, owner string) ([]model.MonthlyAggregate, error) {
+	return r.ListMonthlyAggregates(ctx, owner, "0001-01", "9999-12")
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+const budgetColumns = `owner_id, id, name, category_id, type, amount_limit, start_month, end_month, rollover, created_at, updated_at`
+
+type SQLiteBudgetRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteBudgetRepository(db *sql.DB) *SQLiteBudgetRepo {
+	return &SQLiteBudgetRepo{
+		db: db,
+	}
+}
+
+func (r *SQLiteBudgetRepo) NewBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to create new budget", zap.String("budget_id", budget.ID))
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO budgets (`+budgetColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		budget.OwnerID,
+		budget.ID,
+		budget.Name,
+		budget.CategoryID,
+		budget.Type,
+		budget.Limit,
+		budget.StartMonth,
+		budget.EndMonth,
+		budget.Rollover,
+		formatTime(budget.CreatedAt),
+		formatTime(budget.UpdatedAt),
+	)
+	if err != nil {
+		logger.Error("Failed to add budget to SQLite", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to add budget: %w", storageError(err))
+	}
+
+	logger.Info("Budget successfully created", zap.String("budget_id", budget.ID))
+	return budget, nil
+}
+
+func (r *SQLiteBudgetRepo) UpdateBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to update budget", zap.String("budget_id", budget.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE budgets SET name = ?, category_id = ?, type = ?, amount_limit = ?,
+			start_month = ?, end_month = ?, rollover = ?, updated_at = ?
+		WHERE owner_id = ? AND id = ?
+		RETURNING `+budgetColumns,
+		budget.Name,
+		budget.CategoryID,
+		budget.Type,
+		budget.Limit,
+		budget.StartMonth,
+		budget.EndMonth,
+		budget.Rollover,
+		formatTime(budget.UpdatedAt),
+		budget.OwnerID,
+		budget.ID,
+	)
+	updated, err := scanBudget(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No budget found to update", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("budget with ID '%s': %w", budget.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to update budget in SQLite", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to update budget with ID '%s': %w", budget.ID, storageError(err))
+	}
+
+	logger.Info("Budget successfully updated", zap.String("budget_id", budget.ID))
+	return updated, nil
+}
+
+func (r *SQLiteBudgetRepo) ListBudgets(ctx context.Context, owner string) ([]model.Budget, error) {
+	logger.Info("Attempting to list budgets", zap.String("owner", owner))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+budgetColumns+` FROM budgets WHERE owner_id = ? ORDER BY id`,
+		owner,
+	)
+	if err != nil {
+		logger.Error("Failed to query budgets from SQLite", err)
+		return nil, fmt.Errorf("failed to query budgets from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	budgets := []model.Budget{}
+	for rows.Next() {
+		budget, err := scanBudget(rows)
+		if err != nil {
+			logger.Error("Failed to scan budgets list", err)
+			return nil, fmt.Errorf("failed to scan budgets list: %w", storageError(err))
+		}
+		budgets = append(budgets, *budget)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate budgets list", err)
+		return nil, fmt.Errorf("failed to query budgets from table: %w", storageError(err))
+	}
+
+	logger.Info("Budgets successfully retrieved", zap.Int("count", len(budgets)))
+	return budgets, nil
+}
+
+func (r *SQLiteBudgetRepo) GetBudgetByID(ctx context.Context, owner string, id string) (*model.Budget, error) {
+	logger.Info("Attempting to fetch budget", zap.String("owner", owner), zap.String("budget_id", id))
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+budgetColumns+` FROM budgets WHERE owner_id = ? AND id = ?`,
+		owner, id,
+	)
+	budget, err := scanBudget(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Budget not found", err, zap.String("budget_id", id))
+		return nil, fmt.Errorf("budget with ID '%s': %w", id, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch budget from SQLite", err, zap.String("budget_id", id))
+		return nil, fmt.Errorf("failed to get budget with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Budget successfully retrieved", zap.String("budget_id", id))
+	return budget, nil
+}
+
+func (r *SQLiteBudgetRepo) DeleteBudget(ctx context.Context, budget *model.Budget) (*model.Budget, error) {
+	logger.Info("Attempting to delete budget", zap.String("budget_id", budget.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`DELETE FROM budgets WHERE owner_id = ? AND id = ? RETURNING `+budgetColumns,
+		budget.OwnerID, budget.ID,
+	)
+	deleted, err := scanBudget(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No budget found to delete", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("no budget found to delete with ID '%s': %w", budget.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to delete budget from SQLite", err, zap.String("budget_id", budget.ID))
+		return nil, fmt.Errorf("failed to delete budget with ID '%s': %w", budget.ID, storageError(err))
+	}
+
+	logger.Info("Budget successfully deleted", zap.String("budget_id", budget.ID))
+	return deleted, nil
+}
+
+func scanBudget(row rowScanner) (*model.Budget, error) {
+	var (
+		budget    model.Budget
+		createdAt string
+		updatedAt string
+		err       error
+	)
+
+	if err := row.Scan(
+		&budget.OwnerID,
+		&budget.ID,
+		&budget.Name,
+		&budget.CategoryID,
+		&budget.Type,
+		&budget.Limit,
+		&budget.StartMonth,
+		&budget.EndMonth,
+		&budget.Rollover,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if budget.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if budget.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	budget.SetKeys()
+	return &budget, nil
+}
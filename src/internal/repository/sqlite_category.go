@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+const categoryColumns = `owner_id, id, name, color, icon, parent_id, created_at, updated_at`
+
+type SQLiteCategoryRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteCategoryRepository(db *sql.DB) *SQLiteCategoryRepo {
+	return &SQLiteCategoryRepo{
+		db: db,
+	}
+}
+
+func (r *SQLiteCategoryRepo) NewCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to create new category", zap.String("category_id", category.ID))
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO categories (`+categoryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		category.OwnerID,
+		category.ID,
+		category.Name,
+		category.Color,
+		category.Icon,
+		category.ParentID,
+		formatTime(category.CreatedAt),
+		formatTime(category.UpdatedAt),
+	)
+	if err != nil {
+		logger.Error("Failed to add category to SQLite", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to add category: %w", storageError(err))
+	}
+
+	logger.Info("Category successfully created", zap.String("category_id", category.ID))
+	return category, nil
+}
+
+func (r *SQLiteCategoryRepo) UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to update category", zap.String("category_id", category.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE categories SET name = ?, color = ?, icon = ?, parent_id = ?, updated_at = ?
+		WHERE owner_id = ? AND id = ?
+		RETURNING `+categoryColumns,
+		category.Name,
+		category.Color,
+		category.Icon,
+		category.ParentID,
+		formatTime(category.UpdatedAt),
+		category.OwnerID,
+		category.ID,
+	)
+	updated, err := scanCategory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No category found to update", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("category with ID '%s': %w", category.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to update category in SQLite", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to update category with ID '%s': %w", category.ID, storageError(err))
+	}
+
+	logger.Info("Category successfully updated", zap.String("category_id", category.ID))
+	return updated, nil
+}
+
+func (r *SQLiteCategoryRepo) ListCategories(ctx context.Context, owner string) ([]model.Category, error) {
+	logger.Info("Attempting to list categories", zap.String("owner", owner))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+categoryColumns+` FROM categories WHERE owner_id = ? ORDER BY id`,
+		owner,
+	)
+	if err != nil {
+		logger.Error("Failed to query categories from SQLite", err)
+		return nil, fmt.Errorf("failed to query categories from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	categories := []model.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			logger.Error("Failed to scan categories list", err)
+			return nil, fmt.Errorf("failed to scan categories list: %w", storageError(err))
+		}
+		categories = append(categories, *category)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate categories list", err)
+		return nil, fmt.Errorf("failed to query categories from table: %w", storageError(err))
+	}
+
+	logger.Info("Categories successfully retrieved", zap.Int("count", len(categories)))
+	return categories, nil
+}
+
+func (r *SQLiteCategoryRepo) GetCategoryByID(ctx context.Context, owner string, id string) (*model.Category, error) {
+	logger.Info("Attempting to fetch category", zap.String("owner", owner), zap.String("category_id", id))
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+categoryColumns+` FROM categories WHERE owner_id = ? AND id = ?`,
+		owner, id,
+	)
+	category, err := scanCategory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Category not found", err, zap.String("category_id", id))
+		return nil, fmt.Errorf("category with ID '%s': %w", id, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch category from SQLite", err, zap.String("category_id", id))
+		return nil, fmt.Errorf("failed to get category with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Category successfully retrieved", zap.String("category_id", id))
+	return category, nil
+}
+
+func (r *SQLiteCategoryRepo) DeleteCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	logger.Info("Attempting to delete category", zap.String("category_id", category.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`DELETE FROM categories WHERE owner_id = ? AND id = ? RETURNING `+categoryColumns,
+		category.OwnerID, category.ID,
+	)
+	deleted, err := scanCategory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No category found to delete", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("no category found to delete with ID '%s': %w", category.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to delete category from SQLite", err, zap.String("category_id", category.ID))
+		return nil, fmt.Errorf("failed to delete category with ID '%s': %w", category.ID, storageError(err))
+	}
+
+	logger.Info("Category successfully deleted", zap.String("category_id", category.ID))
+	return deleted, nil
+}
+
+func scanCategory(row rowScanner) (*model.Category, error) {
+	var (
+		category  model.Category
+		createdAt string
+		updatedAt string
+		err       error
+	)
+
+	if err := row.Scan(
+		&category.OwnerID,
+		&category.ID,
+		&category.Name,
+		&category.Color,
+		&category.Icon,
+		&category.ParentID,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if category.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if category.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	category.SetKeys()
+	return &category, nil
+}
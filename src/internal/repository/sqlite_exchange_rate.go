@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+const exchangeRateColumns = `owner_id, base, quote, date, rate, updated_at`
+
+type SQLiteExchangeRateRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteExchangeRateRepository(db *sql.DB) *SQLiteExchangeRateRepo {
+	return &SQLiteExchangeRateRepo{
+		db: db,
+	}
+}
+
+// PutExchangeRates writes every rate in one SQLite transaction, so an
+// import is stored whole or not at all.
+func (r *SQLiteExchangeRateRepo) PutExchangeRates(ctx context.Context, rates []model.ExchangeRate) ([]model.ExchangeRate, error) {
+	logger.Info("Attempting to store exchange rates", zap.Int("count", len(rates)))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err)
+		return nil, fmt.Errorf("failed to add exchange rates: %w", storageError(err))
+	}
+	defer tx.Rollback()
+
+	for _, rate := range rates {
+		_, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO exchange_rates (`+exchangeRateColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+			rate.OwnerID,
+			rate.Base,
+			rate.Quote,
+			rate.Date,
+			rate.Rate,
+			formatTime(rate.UpdatedAt),
+		)
+		if err != nil {
+			logger.Error("Failed to add exchange rate to SQLite", err, zap.String("sk", rate.SK))
+			return nil, fmt.Errorf("failed to add exchange rates: %w", storageError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit exchange rates", err)
+		return nil, fmt.Errorf("failed to add exchange rates: %w", storageError(err))
+	}
+
+	logger.Info("Exchange rates successfully stored", zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (r *SQLiteExchangeRateRepo) ListExchangeRates(ctx context.Context, owner string) ([]model.ExchangeRate, error) {
+	logger.Info("Attempting to list exchange rates", zap.String("owner", owner))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+exchangeRateColumns+` FROM exchange_rates WHERE owner_id = ? ORDER BY base, quote, date`,
+		owner,
+	)
+	if err != nil {
+		logger.Error("Failed to query exchange rates from SQLite", err)
+		return nil, fmt.Errorf("failed to query exchange rates from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	rates := []model.ExchangeRate{}
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			logger.Error("Failed to scan exchange rates list", err)
+			return nil, fmt.Errorf("failed to scan exchange rates list: %w", storageError(err))
+		}
+		rates = append(rates, *rate)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate exchange rates list", err)
+		return nil, fmt.Errorf("failed to query exchange rates from table: %w", storageError(err))
+	}
+
+	logger.Info("Exchange rates successfully retrieved", zap.Int("count", len(rates)))
+	return rates, nil
+}
+
+func (r *SQLiteExchangeRateRepo) DeleteExchangeRate(ctx context.Context, rate *model.ExchangeRate) (*model.ExchangeRate, error) {
+	logger.Info("Attempting to delete exchange rate", zap.String("sk", rate.SK))
+
+	row := r.db.QueryRowContext(ctx,
+		`DELETE FROM exchange_rates WHERE owner_id = ? AND base = ? AND quote = ? AND date = ? RETURNING `+exchangeRateColumns,
+		rate.OwnerID, rate.Base, rate.Quote, rate.Date,
+	)
+	deleted, err := scanExchangeRate(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No exchange rate found to delete", err, zap.String("sk", rate.SK))
+		return nil, fmt.Errorf("no exchange rate found to delete for %s/%s on %s: %w", rate.Base, rate.Quote, rate.Date, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to delete exchange rate from SQLite", err, zap.String("sk", rate.SK))
+		return nil, fmt.Errorf("failed to delete exchange rate for %s/%s on %s: %w", rate.Base, rate.Quote, rate.Date, storageError(err))
+	}
+
+	logger.Info("Exchange rate successfully deleted", zap.String("sk", rate.SK))
+	return deleted, nil
+}
+
+func scanExchangeRate(row rowScanner) (*model.ExchangeRate, error) {
+	var (
+		rate      model.ExchangeRate
+		updatedAt string
+		err       error
+	)
+
+	if err := row.Scan(
+		&rate.OwnerID,
+		&rate.Base,
+		&rate.Quote,
+		&rate.Date,
+		&rate.Rate,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if rate.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	rate.SetKeys()
+	return &rate, nil
+}
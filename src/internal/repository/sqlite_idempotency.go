@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+func (r *SQLiteTransactionRepo) GetIdempotencyRecord(ctx context.Context, owner string, key string) (*model.IdempotencyRecord, error) {
+	logger.Info("Attempting to fetch idempotency record", zap.String("owner", owner), zap.String("idempotency_key", key))
+
+	record := model.IdempotencyRecord{OwnerID: owner, Key: key}
+	var createdAt string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT fingerprint, status, response, created_at, expires_at
+		FROM idempotency_records
+		WHERE owner_id = ? AND idempotency_key = ? AND expires_at > ?`,
+		owner, key, time.Now().Unix(),
+	).Scan(&record.Fingerprint, &record.Status, &record.Response, &createdAt, &record.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Info("Idempotency record not found", zap.String("idempotency_key", key))
+		return nil, fmt.Errorf("idempotency record '%s': %w", key, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch idempotency record from SQLite", err, zap.String("idempotency_key", key))
+		return nil, fmt.Errorf("failed to get idempotency record '%s': %w", key, storageError(err))
+	}
+
+	if record.CreatedAt, err = parseTime(createdAt); err != nil {
+		logger.Error("Failed to parse idempotency record", err, zap.String("idempotency_key", key))
+		return nil, fmt.Errorf("failed to parse idempotency record '%s': %w", key, storageError(err))
+	}
+	record.SetKeys()
+
+	logger.Info("Idempotency record successfully retrieved", zap.String("idempotency_key", key))
+	return &record, nil
+}
+
+// insertIdempotencyRecord stores record, when there is one, holding created
+// as the response. SQLite has no TTL, so the expired records of the owner
+// are dropped first; a live record for the same key fails with
+// ErrIdempotencyKeyUsed.
+func insertIdempotencyRecord(ctx context.Context, db execer, record *model.IdempotencyRecord, created any) error {
+	if record == nil {
+		return nil
+	}
+	if err := record.SetResponse(created); err != nil {
+		return fmt.Errorf("failed to marshal idempotent response: %w", err)
+	}
+
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM idempotency_records WHERE owner_id = ? AND expires_at <= ?`,
+		record.OwnerID, time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to drop expired idempotency records: %w", storageError(err))
+	}
+
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO idempotency_records (owner_id, idempotency_key, fingerprint, status, response, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		record.OwnerID,
+		record.Key,
+		record.Fingerprint,
+		record.Status,
+		record.Response,
+		formatTime(record.CreatedAt),
+		record.ExpiresAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("idempotency key '%s': %w", record.Key, ErrIdempotencyKeyUsed)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add idempotency record: %w", storageError(err))
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+const installmentPlanColumns = `owner_id, id, title, description, amount, currency, installments, category_id, account_id, tags, cancelled_at, occurred_at, created_at, updated_at`
+
+type SQLiteInstallmentRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteInstallmentRepository(db *sql.DB) *SQLiteInstallmentRepo {
+	return &SQLiteInstallmentRepo{
+		db: db,
+	}
+}
+
+func (r *SQLiteInstallmentRepo) MaxInstallments() int {
+	return MaxInstallments
+}
+
+func (r *SQLiteInstallmentRepo) NewInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction, record *model.IdempotencyRecord) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to create new installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to add installment plan: %w", storageError(err))
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO installment_plans (`+installmentPlanColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		plan.OwnerID,
+		plan.ID,
+		plan.Title,
+		plan.Description,
+		plan.Amount,
+		plan.Currency,
+		plan.Installments,
+		plan.CategoryID,
+		plan.AccountID,
+		formatTags(plan.Tags),
+		formatOptionalTime(plan.CancelledAt),
+		formatTime(plan.OccurredAt),
+		formatTime(plan.CreatedAt),
+		formatTime(plan.UpdatedAt),
+	)
+	if err != nil {
+		logger.Error("Failed to add installment plan to SQLite", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to add installment plan: %w", storageError(err))
+	}
+
+	for i := range installments {
+		installments[i].Version = firstVersion
+		if err := insertTransaction(ctx, tx, &installments[i]); err != nil {
+			logger.Error("Failed to add installment to SQLite", err, zap.String("transaction_id", installments[i].ID))
+			return nil, fmt.Errorf("failed to add installment plan: %w", storageError(err))
+		}
+	}
+
+	if err := insertIdempotencyRecord(ctx, tx, record, plan); err != nil {
+		logger.Error("Failed to store idempotency record", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit installment plan", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to add installment plan: %w", storageError(err))
+	}
+
+	logger.Info("Installment plan successfully created", zap.String("plan_id", plan.ID))
+	return plan, nil
+}
+
+func (r *SQLiteInstallmentRepo) UpdateInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to update installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to update installment plan with ID '%s': %w", plan.ID, storageError(err))
+	}
+	defer tx.Rollback()
+
+	for i := range installments {
+		installment := &installments[i]
+		result, err := tx.ExecContext(ctx,
+			`UPDATE transactions
+			SET type = ?, title = ?, description = ?, amount = ?, currency = ?, category_id = ?, account_id = ?, tags = ?,
+				occurred_at = ?, occurred_on = ?, updated_at = ?, version = version + 1
+			WHERE owner_id = ? AND id = ? AND version = ?`,
+			installment.Type,
+			installment.Title,
+			installment.Description,
+			installment.Amount,
+			installment.Currency,
+			installment.CategoryID,
+			installment.AccountID,
+			formatTags(installment.Tags),
+			formatTime(installment.OccurredAt),
+			installment.OccurredAt.Format("2006-01-02"),
+			formatTime(installment.UpdatedAt),
+			installment.OwnerID,
+			installment.ID,
+			installment.Version,
+		)
+		if err != nil {
+			logger.Error("Failed to update installment in SQLite", err, zap.String("transaction_id", installment.ID))
+			return nil, fmt.Errorf("failed to update installment plan with ID '%s': %w", plan.ID, storageError(err))
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err := versionConflictRow(ctx, tx, "transactions", "transaction", installment.OwnerID, installment.ID)
+			logger.Error("Installment not updated", err, zap.String("transaction_id", installment.ID))
+			return nil, err
+		}
+	}
+
+	updated, err := updateInstallmentPlan(ctx, tx, plan)
+	if err != nil {
+		logger.Error("Installment plan not updated", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit installment plan", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to update installment plan with ID '%s': %w", plan.ID, storageError(err))
+	}
+	for i := range installments {
+		installments[i].Version++
+		installments[i].SetKeys()
+	}
+
+	logger.Info("Installment plan successfully updated", zap.String("plan_id", plan.ID))
+	return updated, nil
+}
+
+func (r *SQLiteInstallmentRepo) CancelInstallmentPlan(ctx context.Context, plan *model.InstallmentPlan, installments []model.Transaction) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to cancel installment plan", zap.String("plan_id", plan.ID), zap.Int("installments", len(installments)))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to cancel installment plan with ID '%s': %w", plan.ID, storageError(err))
+	}
+	defer tx.Rollback()
+
+	for _, installment := range installments {
+		result, err := tx.ExecContext(ctx,
+			`DELETE FROM transactions WHERE owner_id = ? AND id = ? AND version = ?`,
+			installment.OwnerID, installment.ID, installment.Version,
+		)
+		if err != nil {
+			logger.Error("Failed to delete installment from SQLite", err, zap.String("transaction_id", installment.ID))
+			return nil, fmt.Errorf("failed to cancel installment plan with ID '%s': %w", plan.ID, storageError(err))
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err := versionConflictRow(ctx, tx, "transactions", "transaction", installment.OwnerID, installment.ID)
+			logger.Error("Installment not deleted", err, zap.String("transaction_id", installment.ID))
+			return nil, err
+		}
+	}
+
+	cancelled, err := updateInstallmentPlan(ctx, tx, plan)
+	if err != nil {
+		logger.Error("Installment plan not cancelled", err, zap.String("plan_id", plan.ID))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit installment plan", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to cancel installment plan with ID '%s': %w", plan.ID, storageError(err))
+	}
+
+	logger.Info("Installment plan successfully cancelled", zap.String("plan_id", plan.ID))
+	return cancelled, nil
+}
+
+func (r *SQLiteInstallmentRepo) ListInstallments(ctx context.Context, plan *model.InstallmentPlan) ([]model.Transaction, error) {
+	logger.Info("Attempting to list installments", zap.String("plan_id", plan.ID), zap.Int("installments", plan.Installments))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+transactionColumns+` FROM transactions WHERE owner_id = ? AND parent_id = ? ORDER BY installment`,
+		plan.OwnerID, plan.ID,
+	)
+	if err != nil {
+		logger.Error("Failed to query installments from SQLite", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to query installments from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	installments := []model.Transaction{}
+	for rows.Next() {
+		installment, err := scanTransaction(rows)
+		if err != nil {
+			logger.Error("Failed to scan installments list", err, zap.String("plan_id", plan.ID))
+			return nil, fmt.Errorf("failed to scan installments list: %w", storageError(err))
+		}
+		installments = append(installments, *installment)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate installments list", err, zap.String("plan_id", plan.ID))
+		return nil, fmt.Errorf("failed to query installments from table: %w", storageError(err))
+	}
+
+	logger.Info("Installments successfully retrieved", zap.String("plan_id", plan.ID), zap.Int("count", len(installments)))
+	return installments, nil
+}
+
+func (r *SQLiteInstallmentRepo) ListInstallmentPlans(ctx context.Context, owner string) ([]model.InstallmentPlan, error) {
+	logger.Info("Attempting to list installment plans", zap.String("owner", owner))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+installmentPlanColumns+` FROM installment_plans WHERE owner_id = ? ORDER BY id`,
+		owner,
+	)
+	if err != nil {
+		logger.Error("Failed to query installment plans from SQLite", err)
+		return nil, fmt.Errorf("failed to query installment plans from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	plans := []model.InstallmentPlan{}
+	for rows.Next() {
+		plan, err := scanInstallmentPlan(rows)
+		if err != nil {
+			logger.Error("Failed to scan installment plans list", err)
+			return nil, fmt.Errorf("failed to scan installment plans list: %w", storageError(err))
+		}
+		plans = append(plans, *plan)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate installment plans list", err)
+		return nil, fmt.Errorf("failed to query installment plans from table: %w", storageError(err))
+	}
+
+	logger.Info("Installment plans successfully retrieved", zap.Int("count", len(plans)))
+	return plans, nil
+}
+
+func (r *SQLiteInstallmentRepo) GetInstallmentPlanByID(ctx context.Context, owner string, id string) (*model.InstallmentPlan, error) {
+	logger.Info("Attempting to fetch installment plan", zap.String("owner", owner), zap.String("plan_id", id))
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+installmentPlanColumns+` FROM installment_plans WHERE owner_id = ? AND id = ?`,
+		owner, id,
+	)
+	plan, err := scanInstallmentPlan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Installment plan not found", err, zap.String("plan_id", id))
+		return nil, fmt.Errorf("installment plan with ID '%s': %w", id, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch installment plan from SQLite", err, zap.String("plan_id", id))
+		return nil, fmt.Errorf("failed to get installment plan with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Installment plan successfully retrieved", zap.String("plan_id", id))
+	return plan, nil
+}
+
+// updateInstallmentPlan writes the fields of plan that may change after it
+// is created and returns the stored plan.
+func updateInstallmentPlan(ctx context.Context, db rowQuerier, plan *model.InstallmentPlan) (*model.InstallmentPlan, error) {
+	row := db.QueryRowContext(ctx,
+		`UPDATE installment_plans SET title = ?, description = ?, category_id = ?, account_id = ?, tags = ?,
+			cancelled_at = ?, updated_at = ?
+		WHERE owner_id = ? AND id = ?
+		RETURNING `+installmentPlanColumns,
+		plan.Title,
+		plan.Description,
+		plan.CategoryID,
+		plan.AccountID,
+		formatTags(plan.Tags),
+		formatOptionalTime(plan.CancelledAt),
+		formatTime(plan.UpdatedAt),
+		plan.OwnerID,
+		plan.ID,
+	)
+	updated, err := scanInstallmentPlan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("installment plan with ID '%s': %w", plan.ID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update installment plan with ID '%s': %w", plan.ID, storageError(err))
+	}
+	return updated, nil
+}
+
+func scanInstallmentPlan(row rowScanner) (*model.InstallmentPlan, error) {
+	var (
+		plan        model.InstallmentPlan
+		tags        string
+		cancelledAt sql.NullString
+		occurredAt  string
+		createdAt   string
+		updatedAt   string
+		err         error
+	)
+
+	if err := row.Scan(
+		&plan.OwnerID,
+		&plan.ID,
+		&plan.Title,
+		&plan.Description,
+		&plan.Amount,
+		&plan.Currency,
+		&plan.Installments,
+		&plan.CategoryID,
+		&plan.AccountID,
+		&tags,
+		&cancelledAt,
+		&occurredAt,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if plan.Tags, err = parseTags(tags); err != nil {
+		return nil, err
+	}
+	if cancelledAt.Valid {
+		cancelled, err := parseTime(cancelledAt.String)
+		if err != nil {
+			return nil, err
+		}
+		plan.CancelledAt = &cancelled
+	}
+	if plan.OccurredAt, err = parseTime(occurredAt); err != nil {
+		return nil, err
+	}
+	if plan.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if plan.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	plan.SetKeys()
+	return &plan, nil
+}
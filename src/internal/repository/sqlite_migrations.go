@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"go.uber.org/zap"
+)
+
+// sqliteMigrations is the ordered schema history of the SQLite backend.
+// Append new steps; never edit one that has already shipped.
+var sqliteMigrations = []string{
+	// 1: transactions
+	`CREATE TABLE transactions (
+		owner_id    TEXT    NOT NULL,
+		id          TEXT    NOT NULL,
+		type        TEXT    NOT NULL,
+		title       TEXT    NOT NULL,
+		description TEXT,
+		amount      INTEGER NOT NULL,
+		created_on  TEXT    NOT NULL,
+		created_at  TEXT    NOT NULL,
+		updated_at  TEXT    NOT NULL,
+		PRIMARY KEY (owner_id, id)
+	);
+	CREATE INDEX transactions_by_date ON transactions (owner_id, created_on, id);`,
+
+	// 2: categories
+	`CREATE TABLE categories (
+		owner_id   TEXT NOT NULL,
+		id         TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		color      TEXT,
+		icon       TEXT,
+		parent_id  TEXT,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL,
+		PRIMARY KEY (owner_id, id)
+	);
+	ALTER TABLE transactions ADD COLUMN category_id TEXT;
+	CREATE INDEX transactions_by_category ON transactions (owner_id, category_id);`,
+
+	// 3: tags, stored as a sorted JSON array
+	`ALTER TABLE transactions ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';`,
+
+	// 4: budgets
+	`CREATE TABLE budgets (
+		owner_id     TEXT    NOT NULL,
+		id           TEXT    NOT NULL,
+		name         TEXT    NOT NULL,
+		category_id  TEXT,
+		type         TEXT,
+		amount_limit INTEGER NOT NULL,
+		start_month  TEXT    NOT NULL,
+		end_month    TEXT,
+		rollover     INTEGER NOT NULL DEFAULT 0,
+		created_at   TEXT    NOT NULL,
+		updated_at   TEXT    NOT NULL,
+		PRIMARY KEY (owner_id, id)
+	);`,
+
+	// 5: recurrences and the link from materialized transactions
+	`CREATE TABLE recurrences (
+		owner_id        TEXT    NOT NULL,
+		id              TEXT    NOT NULL,
+		rrule           TEXT    NOT NULL,
+		start_date      TEXT    NOT NULL,
+		next_occurrence TEXT,
+		type            TEXT    NOT NULL,
+		title           TEXT    NOT NULL,
+		description     TEXT,
+		amount          INTEGER NOT NULL,
+		category_id     TEXT,
+		tags            TEXT    NOT NULL DEFAULT '[]',
+		created_at      TEXT    NOT NULL,
+		updated_at      TEXT    NOT NULL,
+		PRIMARY KEY (owner_id, id)
+	);
+	CREATE INDEX recurrences_by_next_occurrence ON recurrences (next_occurrence);
+	ALTER TABLE transactions ADD COLUMN recurrence_id TEXT;`,
+
+	// 6: installment plans and the link from their installments
+	`CREATE TABLE installment_plans (
+		owner_id     TEXT    NOT NULL,
+		id           TEXT    NOT NULL,
+		title        TEXT    NOT NULL,
+		description  TEXT,
+		amount       INTEGER NOT NULL,
+		installments INTEGER NOT NULL,
+		category_id  TEXT,
+		tags         TEXT    NOT NULL DEFAULT '[]',
+		cancelled_at TEXT,
+		created_at   TEXT    NOT NULL,
+		updated_at   TEXT    NOT NULL,
+		PRIMARY KEY (owner_id, id)
+	);
+	ALTER TABLE transactions ADD COLUMN parent_id TEXT;
+	ALTER TABLE transactions ADD COLUMN installment INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE transactions ADD COLUMN installments INTEGER NOT NULL DEFAULT 0;`,
+
+	// 7: accounts, referenced by transactions and their templates
+	`CREATE TABLE accounts (
+		owner_id        TEXT    NOT NULL,
+		id              TEXT    NOT NULL,
+		name            TEXT    NOT NULL,
+		kind            TEXT    NOT NULL,
+		currency        TEXT    NOT NULL,
+		opening_balance INTEGER NOT NULL DEFAULT 0,
+		created_at      TEXT    NOT NULL,
+		updated_at      TEXT    NOT NULL,
+		PRIMARY KEY (owner_id, id)
+	);
+	ALTER TABLE transactions ADD COLUMN account_id TEXT;
+	CREATE INDEX transactions_by_account ON transactions (owner_id, account_id, created_on);
+	ALTER TABLE recurrences ADD COLUMN account_id TEXT;
+	ALTER TABLE installment_plans ADD COLUMN account_id TEXT;`,
+
+	// 8: transfer legs and the link between them
+	`ALTER TABLE transactions ADD COLUMN counterpart_id TEXT;
+	ALTER TABLE transactions ADD COLUMN direction TEXT NOT NULL DEFAULT '';`,
+
+	// 9: credit card billing cycle
+	`ALTER TABLE accounts ADD COLUMN closing_day INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE accounts ADD COLUMN due_day INTEGER NOT NULL DEFAULT 0;`,
+
+	// 10: transaction versions; rows written before read as version 0
+	`ALTER TABLE transactions ADD COLUMN version INTEGER NOT NULL DEFAULT 0;`,
+
+	// 11: responses to creates sent with an Idempotency-Key
+	`CREATE TABLE idempotency_records (
+		owner_id        TEXT    NOT NULL,
+		idempotency_key TEXT    NOT NULL,
+		fingerprint     TEXT    NOT NULL,
+		status          INTEGER NOT NULL,
+		response        TEXT    NOT NULL,
+		created_at      TEXT    NOT NULL,
+		expires_at      INTEGER NOT NULL,
+		PRIMARY KEY (owner_id, idempotency_key)
+	);`,
+
+	// 12: transactions dated apart from their creation; listings key on
+	// occurred_on, the day of occurred_at, and rows written before are
+	// dated when they were created
+	`ALTER TABLE transactions RENAME COLUMN created_on TO occurred_on;
+	ALTER TABLE transactions ADD COLUMN occurred_at TEXT NOT NULL DEFAULT '';
+	UPDATE transactions SET occurred_at = created_at;
+	ALTER TABLE installment_plans ADD COLUMN occurred_at TEXT NOT NULL DEFAULT '';
+	UPDATE installment_plans SET occurred_at = created_at;`,
+
+	// 13: currencies of amounts, blank on rows written before, and the
+	// exchange rates reports convert them with
+	`ALTER TABLE transactions ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE installment_plans ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE recurrences ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	CREATE TABLE exchange_rates (
+		owner_id   TEXT NOT NULL,
+		base       TEXT NOT NULL,
+		quote      TEXT NOT NULL,
+		date       TEXT NOT NULL,
+		rate       TEXT NOT NULL,
+		updated_at TEXT NOT NULL,
+		PRIMARY KEY (owner_id, base, quote, date)
+	);`,
+
+	// 14: installments looked up by the plan they belong to
+	`CREATE INDEX transactions_by_parent ON transactions (owner_id, parent_id);`,
+
+	// 15: recurrence versions; rows written before read as version 0
+	`ALTER TABLE recurrences ADD COLUMN version INTEGER NOT NULL DEFAULT 0;`,
+
+	// 16: the zone a recurrence is due in; empty means the deployment's
+	`ALTER TABLE recurrences ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';`,
+}
+
+// MigrateSQLite applies every pending migration inside its own transaction.
+func MigrateSQLite(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", storageError(err))
+	}
+
+	var current int
+	err = db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", storageError(err))
+	}
+
+	for i := current; i < len(sqliteMigrations); i++ {
+		version := i + 1
+		logger.Info("Applying SQLite migration", zap.Int("version", version))
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", version, storageError(err))
+		}
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", version, storageError(err))
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			version, time.Now().UTC().Format(time.RFC3339),
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", version, storageError(err))
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", version, storageError(err))
+		}
+	}
+
+	return nil
+}
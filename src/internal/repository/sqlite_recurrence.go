@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+const recurrenceColumns = `owner_id, id, rrule, start_date, next_occurrence, type, title, description, amount, currency, category_id, account_id, tags, created_at, updated_at, version, time_zone`
+
+type SQLiteRecurrenceRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteRecurrenceRepository(db *sql.DB) *SQLiteRecurrenceRepo {
+	return &SQLiteRecurrenceRepo{
+		db: db,
+	}
+}
+
+func (r *SQLiteRecurrenceRepo) NewRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to create new recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	recurrence.Version = firstVersion
+	_, err := r.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO recurrences (`+recurrenceColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		recurrence.OwnerID,
+		recurrence.ID,
+		recurrence.RRule,
+		recurrence.StartDate,
+		recurrence.NextOccurrence,
+		recurrence.Type,
+		recurrence.Title,
+		recurrence.Description,
+		recurrence.Amount,
+		recurrence.Currency,
+		recurrence.CategoryID,
+		recurrence.AccountID,
+		formatTags(recurrence.Tags),
+		formatTime(recurrence.CreatedAt),
+		formatTime(recurrence.UpdatedAt),
+		recurrence.Version,
+		recurrence.TimeZone,
+	)
+	if err != nil {
+		logger.Error("Failed to add recurrence to SQLite", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to add recurrence: %w", storageError(err))
+	}
+
+	logger.Info("Recurrence successfully created", zap.String("recurrence_id", recurrence.ID))
+	return recurrence, nil
+}
+
+func (r *SQLiteRecurrenceRepo) UpdateRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to update recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE recurrences SET rrule = ?, start_date = ?, next_occurrence = ?, type = ?, title = ?,
+			description = ?, amount = ?, currency = ?, category_id = ?, account_id = ?, tags = ?, updated_at = ?,
+			time_zone = ?, version = version + 1
+		WHERE owner_id = ? AND id = ? AND version = ?
+		RETURNING `+recurrenceColumns,
+		recurrence.RRule,
+		recurrence.StartDate,
+		recurrence.NextOccurrence,
+		recurrence.Type,
+		recurrence.Title,
+		recurrence.Description,
+		recurrence.Amount,
+		recurrence.Currency,
+		recurrence.CategoryID,
+		recurrence.AccountID,
+		formatTags(recurrence.Tags),
+		formatTime(recurrence.UpdatedAt),
+		recurrence.TimeZone,
+		recurrence.OwnerID,
+		recurrence.ID,
+		recurrence.Version,
+	)
+	updated, err := scanRecurrence(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err := versionConflictRow(ctx, r.db, "recurrences", "recurrence", recurrence.OwnerID, recurrence.ID)
+		logger.Error("Recurrence not updated", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to update recurrence in SQLite", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to update recurrence with ID '%s': %w", recurrence.ID, storageError(err))
+	}
+
+	logger.Info("Recurrence successfully updated", zap.String("recurrence_id", recurrence.ID))
+	return updated, nil
+}
+
+func (r *SQLiteRecurrenceRepo) ListRecurrences(ctx context.Context, owner string) ([]model.Recurrence, error) {
+	logger.Info("Attempting to list recurrences", zap.String("owner", owner))
+
+	recurrences, err := r.query(ctx,
+		`SELECT `+recurrenceColumns+` FROM recurrences WHERE owner_id = ? ORDER BY id`,
+		owner,
+	)
+	if err != nil {
+		logger.Error("Failed to query recurrences from SQLite", err)
+		return nil, fmt.Errorf("failed to query recurrences from table: %w", storageError(err))
+	}
+
+	logger.Info("Recurrences successfully retrieved", zap.Int("count", len(recurrences)))
+	return recurrences, nil
+}
+
+func (r *SQLiteRecurrenceRepo) GetRecurrenceByID(ctx context.Context, owner string, id string) (*model.Recurrence, error) {
+	logger.Info("Attempting to fetch recurrence", zap.String("owner", owner), zap.String("recurrence_id", id))
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+recurrenceColumns+` FROM recurrences WHERE owner_id = ? AND id = ?`,
+		owner, id,
+	)
+	recurrence, err := scanRecurrence(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Recurrence not found", err, zap.String("recurrence_id", id))
+		return nil, fmt.Errorf("recurrence with ID '%s': %w", id, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch recurrence from SQLite", err, zap.String("recurrence_id", id))
+		return nil, fmt.Errorf("failed to get recurrence with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Recurrence successfully retrieved", zap.String("recurrence_id", id))
+	return recurrence, nil
+}
+
+func (r *SQLiteRecurrenceRepo) DeleteRecurrence(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	logger.Info("Attempting to delete recurrence", zap.String("recurrence_id", recurrence.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`DELETE FROM recurrences WHERE owner_id = ? AND id = ? RETURNING `+recurrenceColumns,
+		recurrence.OwnerID, recurrence.ID,
+	)
+	deleted, err := scanRecurrence(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("No recurrence found to delete", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("no recurrence found to delete with ID '%s': %w", recurrence.ID, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to delete recurrence from SQLite", err, zap.String("recurrence_id", recurrence.ID))
+		return nil, fmt.Errorf("failed to delete recurrence with ID '%s': %w", recurrence.ID, storageError(err))
+	}
+
+	logger.Info("Recurrence successfully deleted", zap.String("recurrence_id", recurrence.ID))
+	return deleted, nil
+}
+
+func (r *SQLiteRecurrenceRepo) ListDueRecurrences(ctx context.Context, date string) ([]model.Recurrence, error) {
+	logger.Info("Attempting to list due recurrences", zap.String("date", date))
+
+	recurrences, err := r.query(ctx,
+		`SELECT `+recurrenceColumns+` FROM recurrences
+		WHERE next_occurrence IS NOT NULL AND next_occurrence <= ?
+		ORDER BY next_occurrence, owner_id, id`,
+		date,
+	)
+	if err != nil {
+		logger.Error("Failed to query due recurrences from SQLite", err)
+		return nil, fmt.Errorf("failed to query due recurrences from table: %w", storageError(err))
+	}
+
+	logger.Info("Due recurrences successfully retrieved", zap.Int("count", len(recurrences)))
+	return recurrences, nil
+}
+
+func (r *SQLiteRecurrenceRepo) query(ctx context.Context, query string, args ...any) ([]model.Recurrence, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recurrences := []model.Recurrence{}
+	for rows.Next() {
+		recurrence, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		recurrences = append(recurrences, *recurrence)
+	}
+	return recurrences, rows.Err()
+}
+
+func scanRecurrence(row rowScanner) (*model.Recurrence, error) {
+	var (
+		recurrence model.Recurrence
+		tags       string
+		createdAt  string
+		updatedAt  string
+		err        error
+	)
+
+	if err := row.Scan(
+		&recurrence.OwnerID,
+		&recurrence.ID,
+		&recurrence.RRule,
+		&recurrence.StartDate,
+		&recurrence.NextOccurrence,
+		&recurrence.Type,
+		&recurrence.Title,
+		&recurrence.Description,
+		&recurrence.Amount,
+		&recurrence.Currency,
+		&recurrence.CategoryID,
+		&recurrence.AccountID,
+		&tags,
+		&createdAt,
+		&updatedAt,
+		&recurrence.Version,
+		&recurrence.TimeZone,
+	); err != nil {
+		return nil, err
+	}
+
+	if recurrence.Tags, err = parseTags(tags); err != nil {
+		return nil, err
+	}
+	if recurrence.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if recurrence.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	recurrence.SetKeys()
+	return &recurrence, nil
+}
@@ -0,0 +1,556 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const transactionColumns = `owner_id, id, type, title, description, amount, currency, category_id, account_id, tags, recurrence_id, parent_id, installment, installments, counterpart_id, direction, version, occurred_at, created_at, updated_at`
+
+// SQLiteTransactionRepo stores transactions in a single SQLite file for
+// self-hosted deployments. The schema is managed by MigrateSQLite.
+type SQLiteTransactionRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteTransactionRepository(db *sql.DB) *SQLiteTransactionRepo {
+	return &SQLiteTransactionRepo{
+		db: db,
+	}
+}
+
+func (r *SQLiteTransactionRepo) NewTransaction(ctx context.Context, transaction *model.Transaction, record *model.IdempotencyRecord) (*model.Transaction, error) {
+	logger.Info("Attempting to create new transaction", zap.String("transaction_id", transaction.ID))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to add transaction: %w", storageError(err))
+	}
+	defer tx.Rollback()
+
+	transaction.Version = firstVersion
+	err = insertTransaction(ctx, tx, transaction)
+	if isUniqueViolation(err) {
+		logger.Error("Transaction already exists", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("transaction with ID '%s': %w", transaction.ID, ErrTransactionExists)
+	}
+	if err != nil {
+		logger.Error("Failed to add transaction to SQLite", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to add transaction: %w", storageError(err))
+	}
+
+	if err := insertIdempotencyRecord(ctx, tx, record, transaction); err != nil {
+		logger.Error("Failed to store idempotency record", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit transaction", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to add transaction: %w", storageError(err))
+	}
+
+	logger.Info("Transaction successfully created", zap.String("transaction_id", transaction.ID))
+	return transaction, nil
+}
+
+func (r *SQLiteTransactionRepo) UpdateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	logger.Info("Attempting to update transaction", zap.String("transaction_id", transaction.ID))
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE transactions
+		SET type = ?, title = ?, description = ?, amount = ?, currency = ?, category_id = ?, account_id = ?, tags = ?,
+			occurred_at = ?, occurred_on = ?, updated_at = ?, version = version + 1
+		WHERE owner_id = ? AND id = ? AND version = ?`,
+		transaction.Type,
+		transaction.Title,
+		transaction.Description,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.CategoryID,
+		transaction.AccountID,
+		formatTags(transaction.Tags),
+		formatTime(transaction.OccurredAt),
+		transaction.OccurredAt.Format("2006-01-02"),
+		formatTime(transaction.UpdatedAt),
+		transaction.OwnerID,
+		transaction.ID,
+		transaction.Version,
+	)
+	if err != nil {
+		logger.Error("Failed to update transaction in SQLite", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to update transaction with ID '%s': %w", transaction.ID, storageError(err))
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		err := versionConflictRow(ctx, r.db, "transactions", "transaction", transaction.OwnerID, transaction.ID)
+		logger.Error("Transaction not updated", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+transactionColumns+` FROM transactions WHERE owner_id = ? AND id = ?`,
+		transaction.OwnerID, transaction.ID,
+	)
+	updated, err := scanTransaction(row)
+	if err != nil {
+		logger.Error("Failed to read updated transaction", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to read updated transaction: %w", storageError(err))
+	}
+
+	logger.Info("Transaction successfully updated", zap.String("transaction_id", transaction.ID))
+	return updated, nil
+}
+
+func (r *SQLiteTransactionRepo) PatchTransaction(ctx context.Context, transaction *model.Transaction, patch *model.TransactionPatch) (*model.Transaction, error) {
+	logger.Info("Attempting to patch transaction", zap.String("transaction_id", transaction.ID))
+
+	assignments := []string{"updated_at = ?", "version = version + 1"}
+	args := []any{formatTime(patch.UpdatedAt)}
+	set := func(column string, value any) {
+		assignments = append(assignments, column+" = ?")
+		args = append(args, value)
+	}
+	if patch.Type.Set {
+		set("type", patch.Type.Value)
+	}
+	if patch.Title.Set {
+		set("title", patch.Title.Value)
+	}
+	if patch.Description.Set {
+		set("description", patch.Description.Value)
+	}
+	if patch.Amount.Set {
+		set("amount", patch.Amount.Value)
+	}
+	if patch.Currency.Set {
+		set("currency", patch.Currency.Or(""))
+	}
+	if patch.CategoryID.Set {
+		set("category_id", patch.CategoryID.Value)
+	}
+	if patch.AccountID.Set {
+		set("account_id", patch.AccountID.Value)
+	}
+	if patch.Tags.Set {
+		set("tags", formatTags(patch.Tags.Or(nil)))
+	}
+	if patch.OccurredAt != nil {
+		set("occurred_at", formatTime(*patch.OccurredAt))
+		set("occurred_on", patch.OccurredAt.Format("2006-01-02"))
+	}
+	args = append(args, transaction.OwnerID, transaction.ID, transaction.Version)
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE transactions SET `+strings.Join(assignments, ", ")+` WHERE owner_id = ? AND id = ? AND version = ?`,
+		args...,
+	)
+	if err != nil {
+		logger.Error("Failed to patch transaction in SQLite", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to patch transaction with ID '%s': %w", transaction.ID, storageError(err))
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		err := versionConflictRow(ctx, r.db, "transactions", "transaction", transaction.OwnerID, transaction.ID)
+		logger.Error("Transaction not patched", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+transactionColumns+` FROM transactions WHERE owner_id = ? AND id = ?`,
+		transaction.OwnerID, transaction.ID,
+	)
+	patched, err := scanTransaction(row)
+	if err != nil {
+		logger.Error("Failed to read patched transaction", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to read patched transaction: %w", storageError(err))
+	}
+
+	logger.Info("Transaction successfully patched", zap.String("transaction_id", transaction.ID))
+	return patched, nil
+}
+
+func (r *SQLiteTransactionRepo) ListTransactions(ctx context.Context, owner string, startDate string, endDate string, options ListOptions) (*TransactionPage, error) {
+	logger.Info("Attempting to list transactions", zap.String("owner", owner), zap.String("startDate", startDate), zap.String("endDate", endDate))
+
+	// One extra row tells whether another page follows.
+	limit := -1
+	if options.Limit > 0 {
+		limit = options.Limit + 1
+	}
+
+	// The sort key expression mirrors the DynamoDB SK so page keys are
+	// interchangeable between backends.
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+transactionColumns+` FROM transactions
+		WHERE owner_id = ? AND occurred_on BETWEEN ? AND ?
+		AND ('CREATEDAT#' || occurred_on || '#' || id) > ?
+		AND (? = '' OR EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?))
+		AND (? = '' OR account_id = ?)
+		ORDER BY occurred_on, id
+		LIMIT ?`,
+		owner, startDate, endDate, options.StartKey["SK"], options.Tag, options.Tag, options.AccountID, options.AccountID, limit,
+	)
+	if err != nil {
+		logger.Error("Failed to query transactions from SQLite", err)
+		return nil, fmt.Errorf("failed to query entries from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	entries := []model.Transaction{}
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			logger.Error("Failed to scan transactions list", err)
+			return nil, fmt.Errorf("failed to scan entries list: %w", storageError(err))
+		}
+		entries = append(entries, *transaction)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate transactions list", err)
+		return nil, fmt.Errorf("failed to query entries from table: %w", storageError(err))
+	}
+
+	page := &TransactionPage{Items: entries}
+	if options.Limit > 0 && len(entries) > options.Limit {
+		page.Items = entries[:options.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextKey = PageKey{"PK": last.PK, "SK": last.SK}
+	}
+
+	logger.Info("Transactions successfully retrieved", zap.Int("count", len(page.Items)))
+	return page, nil
+}
+
+func (r *SQLiteTransactionRepo) GetTransactionByID(ctx context.Context, owner string, id string, occurredAt string) (*model.Transaction, error) {
+	logger.Info("Attempting to fetch transaction",
+		zap.String("owner", owner),
+		zap.String("transaction_id", id),
+		zap.String("occurred_at", occurredAt),
+	)
+
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+transactionColumns+` FROM transactions
+		WHERE owner_id = ? AND id = ? AND (? = '' OR occurred_on = ?)`,
+		owner, id, occurredAt, occurredAt,
+	)
+	transaction, err := scanTransaction(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Transaction not found", err,
+			zap.String("transaction_id", id),
+			zap.String("occurred_at", occurredAt),
+		)
+		return nil, fmt.Errorf("transaction with ID '%s': %w", id, ErrNotFound)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch transaction from SQLite", err,
+			zap.String("transaction_id", id),
+			zap.String("occurred_at", occurredAt),
+		)
+		return nil, fmt.Errorf("failed to get transaction with ID '%s': %w", id, storageError(err))
+	}
+
+	logger.Info("Transaction successfully retrieved",
+		zap.String("transaction_id", id),
+		zap.String("occurred_at", occurredAt),
+	)
+	return transaction, nil
+}
+
+func (r *SQLiteTransactionRepo) DeleteTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	logger.Info("Attempting to delete transaction", zap.String("transaction_id", transaction.ID))
+
+	row := r.db.QueryRowContext(ctx,
+		`DELETE FROM transactions WHERE owner_id = ? AND id = ? AND version = ? RETURNING `+transactionColumns,
+		transaction.OwnerID, transaction.ID, transaction.Version,
+	)
+	deleted, err := scanTransaction(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = versionConflictRow(ctx, r.db, "transactions", "transaction", transaction.OwnerID, transaction.ID)
+		logger.Error("Transaction not deleted", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to delete transaction from SQLite", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to delete transaction with ID '%s': %w", transaction.ID, storageError(err))
+	}
+
+	logger.Info("Transaction successfully deleted", zap.String("transaction_id", transaction.ID))
+	return deleted, nil
+}
+
+func (r *SQLiteTransactionRepo) ReassignCategory(ctx context.Context, owner string, from string, to *string) (int, error) {
+	logger.Info("Attempting to reassign category", zap.String("owner", owner), zap.String("category_id", from))
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE transactions SET category_id = ?, version = version + 1 WHERE owner_id = ? AND category_id = ?`,
+		to, owner, from,
+	)
+	if err != nil {
+		logger.Error("Failed to reassign transaction category", err, zap.String("category_id", from))
+		return 0, fmt.Errorf("failed to reassign transactions of category '%s': %w", from, storageError(err))
+	}
+
+	count, _ := result.RowsAffected()
+	logger.Info("Category successfully reassigned", zap.String("category_id", from), zap.Int64("count", count))
+	return int(count), nil
+}
+
+func (r *SQLiteTransactionRepo) ListTags(ctx context.Context, owner string) ([]model.TagUsage, error) {
+	logger.Info("Attempting to list tags", zap.String("owner", owner))
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT tag.value, COUNT(*) FROM transactions, json_each(transactions.tags) AS tag
+		WHERE transactions.owner_id = ?
+		GROUP BY tag.value
+		ORDER BY tag.value`,
+		owner,
+	)
+	if err != nil {
+		logger.Error("Failed to query tags from SQLite", err)
+		return nil, fmt.Errorf("failed to query tags from table: %w", storageError(err))
+	}
+	defer rows.Close()
+
+	usage := []model.TagUsage{}
+	for rows.Next() {
+		var tag model.TagUsage
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			logger.Error("Failed to scan tags", err)
+			return nil, fmt.Errorf("failed to scan tags: %w", storageError(err))
+		}
+		usage = append(usage, tag)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate tags", err)
+		return nil, fmt.Errorf("failed to query tags from table: %w", storageError(err))
+	}
+
+	logger.Info("Tags successfully retrieved", zap.Int("count", len(usage)))
+	return usage, nil
+}
+
+func (r *SQLiteTransactionRepo) RenameTag(ctx context.Context, owner string, from string, to string) (int, error) {
+	logger.Info("Attempting to rename tag", zap.String("owner", owner), zap.String("from", from), zap.String("to", to))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename tag '%s': %w", from, storageError(err))
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, tags FROM transactions
+		WHERE owner_id = ? AND EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)`,
+		owner, from,
+	)
+	if err != nil {
+		logger.Error("Failed to query tagged transactions", err, zap.String("tag", from))
+		return 0, fmt.Errorf("failed to query transactions tagged '%s': %w", from, storageError(err))
+	}
+
+	renamed := map[string][]string{}
+	for rows.Next() {
+		var id, tags string
+		if err := rows.Scan(&id, &tags); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan tagged transactions: %w", storageError(err))
+		}
+		parsed, err := parseTags(tags)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan tagged transactions: %w", storageError(err))
+		}
+		renamed[id] = model.RenameTag(parsed, from, to)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to query transactions tagged '%s': %w", from, storageError(err))
+	}
+
+	for id, tags := range renamed {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE transactions SET tags = ?, version = version + 1 WHERE owner_id = ? AND id = ?`,
+			formatTags(tags), owner, id,
+		); err != nil {
+			logger.Error("Failed to rename tag on transaction", err, zap.String("transaction_id", id))
+			return 0, fmt.Errorf("failed to rename tag '%s': %w", from, storageError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to rename tag '%s': %w", from, storageError(err))
+	}
+
+	logger.Info("Tag successfully renamed", zap.String("from", from), zap.String("to", to), zap.Int("count", len(renamed)))
+	return len(renamed), nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// execer is the part of *sql.DB and *sql.Tx used to write rows, so inserts
+// can join a surrounding transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// rowQuerier is the part of *sql.DB and *sql.Tx used to read a single row.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// versionConflictRow explains why a versioned write of the row id in table
+// matched no row: either it is gone or it is stored with another version.
+func versionConflictRow(ctx context.Context, db rowQuerier, table string, kind string, owner string, id string) error {
+	var exists int
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM `+table+` WHERE owner_id = ? AND id = ?`,
+		owner, id,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to read %s with ID '%s': %w", kind, id, storageError(err))
+	}
+	if exists == 0 {
+		return fmt.Errorf("%s with ID '%s': %w", kind, id, ErrNotFound)
+	}
+	return fmt.Errorf("%s with ID '%s': %w", kind, id, ErrVersionMismatch)
+}
+
+func insertTransaction(ctx context.Context, db execer, transaction *model.Transaction) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO transactions (`+transactionColumns+`, occurred_on)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		transaction.OwnerID,
+		transaction.ID,
+		transaction.Type,
+		transaction.Title,
+		transaction.Description,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.CategoryID,
+		transaction.AccountID,
+		formatTags(transaction.Tags),
+		transaction.RecurrenceID,
+		transaction.ParentID,
+		transaction.Installment,
+		transaction.Installments,
+		transaction.CounterpartID,
+		transaction.Direction,
+		transaction.Version,
+		formatTime(transaction.OccurredAt),
+		formatTime(transaction.CreatedAt),
+		formatTime(transaction.UpdatedAt),
+		transaction.OccurredAt.Format("2006-01-02"),
+	)
+	return err
+}
+
+func scanTransaction(row rowScanner) (*model.Transaction, error) {
+	var (
+		transaction model.Transaction
+		tags        string
+		occurredAt  string
+		createdAt   string
+		updatedAt   string
+		err         error
+	)
+
+	if err := row.Scan(
+		&transaction.OwnerID,
+		&transaction.ID,
+		&transaction.Type,
+		&transaction.Title,
+		&transaction.Description,
+		&transaction.Amount,
+		&transaction.Currency,
+		&transaction.CategoryID,
+		&transaction.AccountID,
+		&tags,
+		&transaction.RecurrenceID,
+		&transaction.ParentID,
+		&transaction.Installment,
+		&transaction.Installments,
+		&transaction.CounterpartID,
+		&transaction.Direction,
+		&transaction.Version,
+		&occurredAt,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if transaction.Tags, err = parseTags(tags); err != nil {
+		return nil, err
+	}
+	if transaction.OccurredAt, err = parseTime(occurredAt); err != nil {
+		return nil, err
+	}
+	if transaction.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if transaction.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+
+	transaction.SetKeys()
+	return &transaction, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := formatTime(*t)
+	return &formatted
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	encoded, _ := json.Marshal(tags)
+	return string(encoded)
+}
+
+func parseTags(value string) ([]string, error) {
+	var tags []string
+	if err := json.Unmarshal([]byte(value), &tags); err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// isUniqueViolation reports whether err is SQLite rejecting a duplicate
+// primary key.
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+func (r *SQLiteTransactionRepo) NewTransfer(ctx context.Context, transfer *model.Transfer, record *model.IdempotencyRecord) (*model.Transfer, error) {
+	logger.Info("Attempting to create new transfer", zap.String("transaction_id", transfer.From.ID))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to add transfer: %w", storageError(err))
+	}
+	defer tx.Rollback()
+
+	for _, leg := range []*model.Transaction{&transfer.From, &transfer.To} {
+		leg.Version = firstVersion
+		err := insertTransaction(ctx, tx, leg)
+		if isUniqueViolation(err) {
+			logger.Error("Transfer already exists", err, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("transfer with ID '%s': %w", transfer.From.ID, ErrTransactionExists)
+		}
+		if err != nil {
+			logger.Error("Failed to add transfer leg to SQLite", err, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("failed to add transfer: %w", storageError(err))
+		}
+	}
+
+	if err := insertIdempotencyRecord(ctx, tx, record, transfer); err != nil {
+		logger.Error("Failed to store idempotency record", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit transfer", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to add transfer: %w", storageError(err))
+	}
+
+	logger.Info("Transfer successfully created", zap.String("transaction_id", transfer.From.ID))
+	return transfer, nil
+}
+
+func (r *SQLiteTransactionRepo) UpdateTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error) {
+	logger.Info("Attempting to update transfer", zap.String("transaction_id", transfer.From.ID))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to update transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+	}
+	defer tx.Rollback()
+
+	for _, leg := range []*model.Transaction{&transfer.From, &transfer.To} {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE transactions
+			SET title = ?, description = ?, amount = ?, currency = ?, category_id = ?, account_id = ?, tags = ?,
+				occurred_at = ?, occurred_on = ?, updated_at = ?, version = version + 1
+			WHERE owner_id = ? AND id = ? AND type = ? AND version = ?`,
+			leg.Title,
+			leg.Description,
+			leg.Amount,
+			leg.Currency,
+			leg.CategoryID,
+			leg.AccountID,
+			formatTags(leg.Tags),
+			formatTime(leg.OccurredAt),
+			leg.OccurredAt.Format("2006-01-02"),
+			formatTime(leg.UpdatedAt),
+			leg.OwnerID,
+			leg.ID,
+			model.TransactionTypeTransfer,
+			leg.Version,
+		)
+		if err != nil {
+			logger.Error("Failed to update transfer leg in SQLite", err, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("failed to update transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err := versionConflictRow(ctx, tx, "transactions", "transfer", leg.OwnerID, leg.ID)
+			logger.Error("Transfer not updated", err, zap.String("transaction_id", leg.ID))
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit transfer", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to update transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+	}
+
+	updated := *transfer
+	updated.From.Version++
+	updated.To.Version++
+	updated.From.SetKeys()
+	updated.To.SetKeys()
+
+	logger.Info("Transfer successfully updated", zap.String("transaction_id", transfer.From.ID))
+	return &updated, nil
+}
+
+func (r *SQLiteTransactionRepo) DeleteTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error) {
+	logger.Info("Attempting to delete transfer", zap.String("transaction_id", transfer.From.ID))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin SQLite transaction", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to delete transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+	}
+	defer tx.Rollback()
+
+	for _, leg := range []*model.Transaction{&transfer.From, &transfer.To} {
+		result, err := tx.ExecContext(ctx,
+			`DELETE FROM transactions WHERE owner_id = ? AND id = ? AND version = ?`,
+			leg.OwnerID, leg.ID, leg.Version,
+		)
+		if err != nil {
+			logger.Error("Failed to delete transfer leg from SQLite", err, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("failed to delete transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err := versionConflictRow(ctx, tx, "transactions", "transfer", leg.OwnerID, leg.ID)
+			logger.Error("Transfer not deleted", err, zap.String("transaction_id", leg.ID))
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit transfer", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to delete transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+	}
+
+	logger.Info("Transfer successfully deleted", zap.String("transaction_id", transfer.From.ID))
+	return transfer, nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"go.uber.org/zap"
+)
+
+// GSI1 indexes items by owner and ID so transactions can be fetched without
+// knowing the date encoded in their sort key.
+const GSI1 = "GSI1"
+
+// ttlAttribute holds the Unix time after which DynamoDB may delete an item.
+const ttlAttribute = "expires_at"
+
+var gsi1 = types.GlobalSecondaryIndex{
+	IndexName: aws.String(GSI1),
+	KeySchema: []types.KeySchemaElement{
+		{AttributeName: aws.String("GSI1PK"), KeyType: types.KeyTypeHash},
+		{AttributeName: aws.String("GSI1SK"), KeyType: types.KeyTypeRange},
+	},
+	Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+	ProvisionedThroughput: &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(5),
+		WriteCapacityUnits: aws.Int64(5),
+	},
+}
+
+var tableAttributes = []types.AttributeDefinition{
+	{AttributeName: aws.String("PK"), AttributeType: types.ScalarAttributeTypeS},
+	{AttributeName: aws.String("SK"), AttributeType: types.ScalarAttributeTypeS},
+	{AttributeName: aws.String("GSI1PK"), AttributeType: types.ScalarAttributeTypeS},
+	{AttributeName: aws.String("GSI1SK"), AttributeType: types.ScalarAttributeTypeS},
+}
+
+// CreateTable creates the single table used by every DynamoDB repository,
+// including its secondary indexes. Tables created before an index existed
+// get the missing index added in place.
+func CreateTable(ctx context.Context, db *dynamodb.Client, tableName string) error {
+	logger.Info("Attempting to create table", zap.String("table", tableName))
+
+	_, err := db.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:            aws.String(tableName),
+		AttributeDefinitions: tableAttributes,
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{gsi1},
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	})
+
+	var inUse *types.ResourceInUseException
+	if errors.As(err, &inUse) {
+		logger.Info("Table already exists, checking indexes", zap.String("table", tableName))
+		if err := ensureIndexes(ctx, db, tableName); err != nil {
+			return err
+		}
+		return ensureTTL(ctx, db, tableName)
+	}
+	if err != nil {
+		logger.Error("Failed to create table", err, zap.String("table", tableName))
+		return fmt.Errorf("failed to create table '%s': %w", tableName, storageError(err))
+	}
+
+	logger.Info("Table successfully created", zap.String("table", tableName))
+	return ensureTTL(ctx, db, tableName)
+}
+
+// ensureTTL lets DynamoDB delete items once the Unix time in ttlAttribute
+// has passed. Only idempotency records carry it.
+func ensureTTL(ctx context.Context, db *dynamodb.Client, tableName string) error {
+	ttl, err := db.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe time to live of table '%s': %w", tableName, storageError(err))
+	}
+	if description := ttl.TimeToLiveDescription; description != nil && description.TimeToLiveStatus != types.TimeToLiveStatusDisabled {
+		return nil
+	}
+
+	logger.Info("Enabling time to live", zap.String("table", tableName), zap.String("attribute", ttlAttribute))
+	_, err = db.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(ttlAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable time to live on table '%s': %w", tableName, storageError(err))
+	}
+	return nil
+}
+
+func ensureIndexes(ctx context.Context, db *dynamodb.Client, tableName string) error {
+	table, err := db.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe table '%s': %w", tableName, storageError(err))
+	}
+
+	for _, index := range table.Table.GlobalSecondaryIndexes {
+		if aws.ToString(index.IndexName) == GSI1 {
+			return nil
+		}
+	}
+
+	logger.Info("Adding missing index", zap.String("table", tableName), zap.String("index", GSI1))
+	_, err = db.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName:            aws.String(tableName),
+		AttributeDefinitions: tableAttributes,
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+			{Create: &types.CreateGlobalSecondaryIndexAction{
+				IndexName:             gsi1.IndexName,
+				KeySchema:             gsi1.KeySchema,
+				Projection:            gsi1.Projection,
+				ProvisionedThroughput: gsi1.ProvisionedThroughput,
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add index '%s': %w", GSI1, storageError(err))
+	}
+	return nil
+}
+
+// queryAll follows LastEvaluatedKey until input is exhausted.
+func queryAll(ctx context.Context, db *dynamodb.Client, input *dynamodb.QueryInput) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+
+	paginator := dynamodb.NewQueryPaginator(db, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+	}
+	return items, nil
+}
+
+// maxBatchWrite is the most requests a single BatchWriteItem call takes.
+const maxBatchWrite = 25
+
+// maxBatchAttempts bounds how often batchWrite resends requests DynamoDB
+// left unprocessed before giving up.
+const maxBatchAttempts = 5
+
+// batchWrite sends requests in batches of maxBatchWrite, resending with a
+// growing pause whatever DynamoDB leaves unprocessed under throttling.
+func batchWrite(ctx context.Context, db *dynamodb.Client, tableName string, requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += maxBatchWrite {
+		pending := map[string][]types.WriteRequest{
+			tableName: requests[start:min(start+maxBatchWrite, len(requests))],
+		}
+		for attempt := 1; len(pending) > 0; attempt++ {
+			if attempt > maxBatchAttempts {
+				return fmt.Errorf("%d writes left unprocessed: %w", len(pending[tableName]), ErrUnavailable)
+			}
+			if attempt > 1 {
+				time.Sleep(time.Duration(attempt-1) * 100 * time.Millisecond)
+			}
+
+			response, err := db.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return err
+			}
+			pending = response.UnprocessedItems
+		}
+	}
+	return nil
+}
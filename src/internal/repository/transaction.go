@@ -2,15 +2,18 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/joaoleau/muquirango/internal/model"
 	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
 	"go.uber.org/zap"
 )
 
@@ -26,22 +29,48 @@ func NewTransactionRepository(db *dynamodb.Client, tableName string) *Transactio
 	}
 }
 
-func (r *TransactionRepo) NewTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+func (r *TransactionRepo) NewTransaction(ctx context.Context, transaction *model.Transaction, record *model.IdempotencyRecord) (*model.Transaction, error) {
 	logger.Info("Attempting to create new transaction", zap.String("transaction_id", transaction.ID))
 
+	transaction.Version = firstVersion
 	item, err := attributevalue.MarshalMap(transaction)
 	if err != nil {
 		logger.Error("Failed to marshal transaction", err, zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("failed to marshal transaction: %w", err)
+		return nil, fmt.Errorf("failed to marshal transaction: %w", storageError(err))
 	}
 
-	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      item,
-	})
+	put := &types.Put{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	}
+	counts, err := aggregateWrites(r.tableName, model.AggregateChanges(nil, transaction))
+	if err != nil {
+		logger.Error("Failed to build aggregate writes", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	if record != nil || len(counts) > 0 {
+		writes := append([]types.TransactWriteItem{{Put: put}}, counts...)
+		err = transactWrite(ctx, r.db, r.tableName, writes, record, transaction)
+	} else {
+		_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           put.TableName,
+			Item:                put.Item,
+			ConditionExpression: put.ConditionExpression,
+		})
+	}
+	if errors.Is(err, ErrIdempotencyKeyUsed) {
+		logger.Error("Idempotency key already used", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) || isConditionCancellation(err) {
+		logger.Error("Transaction already exists", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("transaction with ID '%s': %w", transaction.ID, ErrTransactionExists)
+	}
 	if err != nil {
 		logger.Error("Failed to add transaction to DynamoDB", err, zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("failed to add transaction: %w", err)
+		return nil, fmt.Errorf("failed to add transaction: %w", storageError(err))
 	}
 
 	logger.Info("Transaction successfully created", zap.String("transaction_id", transaction.ID))
@@ -51,156 +80,580 @@ func (r *TransactionRepo) NewTransaction(ctx context.Context, transaction *model
 func (r *TransactionRepo) UpdateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
 	logger.Info("Attempting to update transaction", zap.String("transaction_id", transaction.ID))
 
-	var updateTransaction *model.Transaction
+	// The monthly aggregates need the type, amount and month the
+	// transaction had, so the stored item is read and replaced as a whole.
+	return r.rewriteTransaction(ctx, transaction, func(stored *model.Transaction) {
+		applyUpdate(stored, transaction)
+	})
+}
 
-	update := expression.Set(expression.Name("type"), expression.Value(transaction.Type))
-	update = update.Set(expression.Name("title"), expression.Value(transaction.Title))
-	update = update.Set(expression.Name("description"), expression.Value(transaction.Description))
-	update = update.Set(expression.Name("amount"), expression.Value(transaction.Amount))
+func (r *TransactionRepo) PatchTransaction(ctx context.Context, transaction *model.Transaction, patch *model.TransactionPatch) (*model.Transaction, error) {
+	logger.Info("Attempting to patch transaction", zap.String("transaction_id", transaction.ID))
 
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	// A patch moving the transaction or changing what the monthly
+	// aggregates count replaces the stored item as a whole.
+	if (patch.OccurredAt != nil && moved(transaction, *patch.OccurredAt)) || patch.Type.Set || patch.Amount.Set || patch.Currency.Set {
+		return r.rewriteTransaction(ctx, transaction, patch.ApplyTo)
+	}
+
+	var patchedTransaction *model.Transaction
+
+	update := expression.Set(expression.Name("updated_at"), expression.Value(patch.UpdatedAt))
+	update = update.Set(expression.Name("version"), expression.Value(transaction.Version+1))
+	update = setOptional(update, "type", patch.Type)
+	update = setOptional(update, "title", patch.Title)
+	update = setOptional(update, "description", patch.Description)
+	update = setOptional(update, "amount", patch.Amount)
+	update = setOptional(update, "currency", patch.Currency)
+	update = setOptional(update, "category_id", patch.CategoryID)
+	update = setOptional(update, "account_id", patch.AccountID)
+	if patch.Tags.Set {
+		update = setTags(update, patch.Tags.Or(nil))
+	}
+	if patch.OccurredAt != nil {
+		update = update.Set(expression.Name("occurred_at"), expression.Value(*patch.OccurredAt))
+	}
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(versionCondition(transaction.Version)).Build()
 	if err != nil {
 		logger.Error("Failed to build update expression", err, zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("failed to build update expression: %w", err)
+		return nil, fmt.Errorf("failed to build update expression: %w", storageError(err))
 	}
 
 	response, err := r.db.UpdateItem(
 		ctx,
 		&dynamodb.UpdateItemInput{
-			TableName:                 &r.tableName,
-			Key:                       transaction.GetKey(),
-			ExpressionAttributeNames:  expr.Names(),
-			ExpressionAttributeValues: expr.Values(),
-			UpdateExpression:          expr.Update(),
-			ReturnValues:              types.ReturnValueUpdatedNew,
+			TableName:                           &r.tableName,
+			Key:                                 transaction.GetKey(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			UpdateExpression:                    expr.Update(),
+			ConditionExpression:                 expr.Condition(),
+			ReturnValues:                        types.ReturnValueAllNew,
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 		},
 	)
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		err = versionConflict("transaction", transaction.ID, conditionErr.Item)
+		logger.Error("Transaction not patched", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
 	if err != nil {
-		logger.Error("Failed to update transaction in DynamoDB", err, zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("failed to update transaction with ID '%s': %w", transaction.ID, err)
+		logger.Error("Failed to patch transaction in DynamoDB", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to patch transaction with ID '%s': %w", transaction.ID, storageError(err))
 	}
 
-	err = attributevalue.UnmarshalMap(response.Attributes, &updateTransaction)
+	err = attributevalue.UnmarshalMap(response.Attributes, &patchedTransaction)
 	if err != nil {
-		logger.Error("Failed to unmarshal updated transaction", err, zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("failed to unmarshal updated transaction: %w", err)
+		logger.Error("Failed to unmarshal patched transaction", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to unmarshal patched transaction: %w", storageError(err))
 	}
 
-	logger.Info("Transaction successfully updated", zap.String("transaction_id", transaction.ID))
-	return updateTransaction, nil
+	logger.Info("Transaction successfully patched", zap.String("transaction_id", transaction.ID))
+	return patchedTransaction, nil
 }
 
-func (r *TransactionRepo) ListTransactions(ctx context.Context, startDate string, endDate string) (*[]model.Transaction, error) {
-    logger.Info("Attempting to list transactions", zap.String("startDate", startDate), zap.String("endDate", endDate))
-
-    startSK := fmt.Sprintf("CREATEDAT#%s#", startDate)
-    endSK := fmt.Sprintf("CREATEDAT#%s#z", endDate)
+func (r *TransactionRepo) ListTransactions(ctx context.Context, owner string, startDate string, endDate string, options ListOptions) (*TransactionPage, error) {
+	logger.Info("Attempting to list transactions", zap.String("owner", owner), zap.String("startDate", startDate), zap.String("endDate", endDate))
 
-    input := &dynamodb.QueryInput{
-        TableName: aws.String(r.tableName),
-        KeyConditionExpression: aws.String("PK = :pk AND SK BETWEEN :start AND :end"),
-        ExpressionAttributeValues: map[string]types.AttributeValue{
-            ":pk":    &types.AttributeValueMemberS{Value: "TRANSACTION"},
-            ":start": &types.AttributeValueMemberS{Value: startSK},
-            ":end":   &types.AttributeValueMemberS{Value: endSK},
-        },
-    }
+	startSK := fmt.Sprintf("CREATEDAT#%s#", startDate)
+	endSK := fmt.Sprintf("CREATEDAT#%s#z", endDate)
 
-    resp, err := r.db.Query(ctx, input)
-    if err != nil {
-        logger.Error("Failed to query transactions from DynamoDB", err)
-        return nil, fmt.Errorf("failed to query entries from table: %w", err)
-    }
-
-    var entries []model.Transaction
-    if err := attributevalue.UnmarshalListOfMaps(resp.Items, &entries); err != nil {
-        logger.Error("Failed to unmarshal transactions list", err)
-        return nil, fmt.Errorf("failed to unmarshal entries list: %w", err)
-    }
-
-    logger.Info("Transactions successfully retrieved", zap.Int("count", len(entries)))
-    return &entries, nil
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND SK BETWEEN :start AND :end"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":start": &types.AttributeValueMemberS{Value: startSK},
+			":end":   &types.AttributeValueMemberS{Value: endSK},
+		},
+		ExclusiveStartKey: toAttributeKey(options.StartKey),
+	}
+	var filters []string
+	if options.Tag != "" {
+		filters = append(filters, "contains(#tags, :tag)")
+		input.ExpressionAttributeNames = map[string]string{"#tags": "tags"}
+		input.ExpressionAttributeValues[":tag"] = &types.AttributeValueMemberS{Value: options.Tag}
+	}
+	if options.AccountID != "" {
+		filters = append(filters, "account_id = :account")
+		input.ExpressionAttributeValues[":account"] = &types.AttributeValueMemberS{Value: options.AccountID}
+	}
+	if len(filters) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+	}
+	// Limit caps the items a query reads before the filter drops those not
+	// matching, so a filtered page is topped up from where the last query
+	// stopped until it is full or the range is exhausted.
+	entries := []model.Transaction{}
+	for {
+		if options.Limit > 0 {
+			input.Limit = aws.Int32(int32(options.Limit - len(entries)))
+		}
+
+		resp, err := r.db.Query(ctx, input)
+		if err != nil {
+			logger.Error("Failed to query transactions from DynamoDB", err)
+			return nil, fmt.Errorf("failed to query entries from table: %w", storageError(err))
+		}
+
+		var items []model.Transaction
+		if err := attributevalue.UnmarshalListOfMaps(resp.Items, &items); err != nil {
+			logger.Error("Failed to unmarshal transactions list", err)
+			return nil, fmt.Errorf("failed to unmarshal entries list: %w", storageError(err))
+		}
+		entries = append(entries, items...)
+
+		if resp.LastEvaluatedKey == nil || options.Limit == 0 || len(entries) >= options.Limit {
+			logger.Info("Transactions successfully retrieved", zap.Int("count", len(entries)))
+			return &TransactionPage{
+				Items:   entries,
+				NextKey: fromAttributeKey(resp.LastEvaluatedKey),
+			}, nil
+		}
+		input.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
 }
 
-func (r *TransactionRepo) GetTransactionByID(ctx context.Context, id string, createdAt string) (*model.Transaction, error) {
-    logger.Info("Attempting to fetch transaction",
-        zap.String("transaction_id", id),
-        zap.String("created_at", createdAt),
-    )
+func (r *TransactionRepo) GetTransactionByID(ctx context.Context, owner string, id string, occurredAt string) (*model.Transaction, error) {
+	logger.Info("Attempting to fetch transaction",
+		zap.String("owner", owner),
+		zap.String("transaction_id", id),
+		zap.String("occurred_at", occurredAt),
+	)
 
-    input := &dynamodb.QueryInput{
-        TableName: aws.String(r.tableName),
-        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :id)"),
-        ExpressionAttributeValues: map[string]types.AttributeValue{
-            ":pk": &types.AttributeValueMemberS{Value: "TRANSACTION"},
-            ":id": &types.AttributeValueMemberS{Value: fmt.Sprintf("CREATEDAT#%s#%s", createdAt, id)},
-        },
-        Limit: aws.Int32(1),
-    }
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":id": &types.AttributeValueMemberS{Value: model.TransactionDateKey(occurredAt, id)},
+		},
+		Limit: aws.Int32(1),
+	}
+	if occurredAt == "" {
+		input = &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			IndexName:              aws.String(GSI1),
+			KeyConditionExpression: aws.String("GSI1PK = :pk AND GSI1SK = :id"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+				":id": &types.AttributeValueMemberS{Value: model.TransactionIDKey(id)},
+			},
+			Limit: aws.Int32(1),
+		}
+	}
 
-    resp, err := r.db.Query(ctx, input)
-    if err != nil {
-        logger.Error("Failed to fetch transaction from DynamoDB", err,
-            zap.String("transaction_id", id),
-            zap.String("created_at", createdAt),
-        )
-        return nil, fmt.Errorf("failed to get transaction with ID '%s': %w", id, err)
-    }
+	resp, err := r.db.Query(ctx, input)
+	if err != nil {
+		logger.Error("Failed to fetch transaction from DynamoDB", err,
+			zap.String("transaction_id", id),
+			zap.String("occurred_at", occurredAt),
+		)
+		return nil, fmt.Errorf("failed to get transaction with ID '%s': %w", id, storageError(err))
+	}
 
-    if len(resp.Items) == 0 {
-		err := fmt.Errorf("transaction not found: created_at %s && transaction_id %s", createdAt, id)
-		logger.Error("Transaction not found", err,
+	if len(resp.Items) == 0 {
+		logger.Error("Transaction not found", ErrNotFound,
 			zap.String("transaction_id", id),
-			zap.String("created_at", createdAt),
+			zap.String("occurred_at", occurredAt),
 		)
-		return nil, fmt.Errorf("transaction with ID '%s' not found", id)
-    }
+		return nil, fmt.Errorf("transaction with ID '%s': %w", id, ErrNotFound)
+	}
 
-    var transaction model.Transaction
-    if err := attributevalue.UnmarshalMap(resp.Items[0], &transaction); err != nil {
-        logger.Error("Failed to unmarshal transaction", err,
-            zap.String("transaction_id", id),
-            zap.String("created_at", createdAt),
-        )
-        return nil, fmt.Errorf("failed to unmarshal transaction with ID '%s': %w", id, err)
-    }
+	var transaction model.Transaction
+	if err := attributevalue.UnmarshalMap(resp.Items[0], &transaction); err != nil {
+		logger.Error("Failed to unmarshal transaction", err,
+			zap.String("transaction_id", id),
+			zap.String("occurred_at", occurredAt),
+		)
+		return nil, fmt.Errorf("failed to unmarshal transaction with ID '%s': %w", id, storageError(err))
+	}
 
-    logger.Info("Transaction successfully retrieved",
-        zap.String("transaction_id", id),
-        zap.String("created_at", createdAt),
-    )
-    return &transaction, nil
+	logger.Info("Transaction successfully retrieved",
+		zap.String("transaction_id", id),
+		zap.String("occurred_at", occurredAt),
+	)
+	return &transaction, nil
 }
 
 func (r *TransactionRepo) DeleteTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
 	logger.Info("Attempting to delete transaction", zap.String("transaction_id", transaction.ID))
 
-	var deleteTransaction *model.Transaction
+	stored, err := r.storedTransaction(ctx, transaction)
+	if err != nil {
+		logger.Error("Transaction not deleted", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
 
-	response, err := r.db.DeleteItem(
-		ctx,
-		&dynamodb.DeleteItemInput{
-			TableName:    aws.String(r.tableName),
-			Key:          transaction.GetKey(),
-			ReturnValues: types.ReturnValueAllOld,
-		},
-	)
+	expr, err := expression.NewBuilder().WithCondition(versionCondition(stored.Version)).Build()
+	if err != nil {
+		logger.Error("Failed to build condition expression", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to build condition expression: %w", storageError(err))
+	}
+	counts, err := aggregateWrites(r.tableName, model.AggregateChanges(stored, nil))
+	if err != nil {
+		logger.Error("Failed to build aggregate writes", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+
+	writes := append([]types.TransactWriteItem{{Delete: &types.Delete{
+		TableName:                           aws.String(r.tableName),
+		Key:                                 stored.GetKey(),
+		ExpressionAttributeNames:            expr.Names(),
+		ExpressionAttributeValues:           expr.Values(),
+		ConditionExpression:                 expr.Condition(),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}}}, counts...)
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	if isConditionCancellation(err) {
+		err = transactConflict(err, "transaction", transaction.ID)
+		logger.Error("Transaction not deleted", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
 	if err != nil {
 		logger.Error("Failed to delete transaction from DynamoDB", err, zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("failed to delete transaction with ID '%s': %w", transaction.ID, err)
+		return nil, fmt.Errorf("failed to delete transaction with ID '%s': %w", transaction.ID, storageError(err))
+	}
+
+	logger.Info("Transaction successfully deleted", zap.String("transaction_id", transaction.ID))
+	return stored, nil
+}
+
+func (r *TransactionRepo) ReassignCategory(ctx context.Context, owner string, from string, to *string) (int, error) {
+	logger.Info("Attempting to reassign category", zap.String("owner", owner), zap.String("category_id", from))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("category_id = :from"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: "CREATEDAT#"},
+			":from":   &types.AttributeValueMemberS{Value: from},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query categorized transactions", err, zap.String("category_id", from))
+		return 0, fmt.Errorf("failed to query transactions of category '%s': %w", from, storageError(err))
 	}
 
-	if len(response.Attributes) == 0 {
-		logger.Error("No transaction found to delete", fmt.Errorf("not found"), zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("no transaction found to delete with ID '%s'", transaction.ID)
+	var transactions []model.Transaction
+	if err := attributevalue.UnmarshalListOfMaps(items, &transactions); err != nil {
+		logger.Error("Failed to unmarshal categorized transactions", err, zap.String("category_id", from))
+		return 0, fmt.Errorf("failed to unmarshal categorized transactions: %w", storageError(err))
 	}
 
-	err = attributevalue.UnmarshalMap(response.Attributes, &deleteTransaction)
+	err = r.updateEach(ctx, transactions, func(model.Transaction) expression.UpdateBuilder {
+		if to != nil {
+			return expression.Set(expression.Name("category_id"), expression.Value(*to))
+		}
+		return expression.Remove(expression.Name("category_id"))
+	})
 	if err != nil {
-		logger.Error("Failed to unmarshal deleted transaction", err, zap.String("transaction_id", transaction.ID))
-		return nil, fmt.Errorf("failed to unmarshal deleted transaction: %w", err)
+		logger.Error("Failed to reassign transaction category", err, zap.String("category_id", from))
+		return 0, fmt.Errorf("failed to reassign transactions of category '%s': %w", from, err)
 	}
 
-	logger.Info("Transaction successfully deleted", zap.String("transaction_id", transaction.ID))
-	return deleteTransaction, nil
+	logger.Info("Category successfully reassigned", zap.String("category_id", from), zap.Int("count", len(transactions)))
+	return len(transactions), nil
+}
+
+func (r *TransactionRepo) ListTags(ctx context.Context, owner string) ([]model.TagUsage, error) {
+	logger.Info("Attempting to list tags", zap.String("owner", owner))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("attribute_exists(#tags)"),
+		ProjectionExpression:   aws.String("#tags"),
+		ExpressionAttributeNames: map[string]string{
+			"#tags": "tags",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: "CREATEDAT#"},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query tags from DynamoDB", err)
+		return nil, fmt.Errorf("failed to query tags from table: %w", storageError(err))
+	}
+
+	var transactions []model.Transaction
+	if err := attributevalue.UnmarshalListOfMaps(items, &transactions); err != nil {
+		logger.Error("Failed to unmarshal tags", err)
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", storageError(err))
+	}
+
+	usage := countTags(transactions)
+	logger.Info("Tags successfully retrieved", zap.Int("count", len(usage)))
+	return usage, nil
+}
+
+func (r *TransactionRepo) RenameTag(ctx context.Context, owner string, from string, to string) (int, error) {
+	logger.Info("Attempting to rename tag", zap.String("owner", owner), zap.String("from", from), zap.String("to", to))
+
+	items, err := queryAll(ctx, r.db, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :prefix)"),
+		FilterExpression:       aws.String("contains(#tags, :from)"),
+		ExpressionAttributeNames: map[string]string{
+			"#tags": "tags",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: model.OwnerKey(owner)},
+			":prefix": &types.AttributeValueMemberS{Value: "CREATEDAT#"},
+			":from":   &types.AttributeValueMemberS{Value: from},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to query tagged transactions", err, zap.String("tag", from))
+		return 0, fmt.Errorf("failed to query transactions tagged '%s': %w", from, storageError(err))
+	}
+
+	var transactions []model.Transaction
+	if err := attributevalue.UnmarshalListOfMaps(items, &transactions); err != nil {
+		logger.Error("Failed to unmarshal tagged transactions", err, zap.String("tag", from))
+		return 0, fmt.Errorf("failed to unmarshal tagged transactions: %w", storageError(err))
+	}
+
+	err = r.updateEach(ctx, transactions, func(transaction model.Transaction) expression.UpdateBuilder {
+		return setTags(expression.UpdateBuilder{}, model.RenameTag(transaction.Tags, from, to))
+	})
+	if err != nil {
+		logger.Error("Failed to rename tag on transactions", err, zap.String("tag", from))
+		return 0, fmt.Errorf("failed to rename tag '%s': %w", from, err)
+	}
+
+	logger.Info("Tag successfully renamed", zap.String("from", from), zap.String("to", to), zap.Int("count", len(transactions)))
+	return len(transactions), nil
+}
+
+// updateEach applies the update built for each transaction and bumps its
+// version, in TransactWriteItems calls of up to maxTransactItems writes
+// each guarded by the version the transaction was read with. A transaction
+// changed meanwhile cancels its whole call with a conflict; earlier calls
+// stay written, so callers finish the job by running again.
+func (r *TransactionRepo) updateEach(ctx context.Context, transactions []model.Transaction, update func(model.Transaction) expression.UpdateBuilder) error {
+	for start := 0; start < len(transactions); start += maxTransactItems {
+		chunk := transactions[start:min(start+maxTransactItems, len(transactions))]
+
+		writes := make([]types.TransactWriteItem, 0, len(chunk))
+		for _, transaction := range chunk {
+			expr, err := expression.NewBuilder().
+				WithUpdate(update(transaction).Add(expression.Name("version"), expression.Value(1))).
+				WithCondition(versionCondition(transaction.Version)).
+				Build()
+			if err != nil {
+				return fmt.Errorf("failed to build update expression: %w", storageError(err))
+			}
+			writes = append(writes, types.TransactWriteItem{Update: &types.Update{
+				TableName:                           aws.String(r.tableName),
+				Key:                                 transaction.GetKey(),
+				ExpressionAttributeNames:            expr.Names(),
+				ExpressionAttributeValues:           expr.Values(),
+				UpdateExpression:                    expr.Update(),
+				ConditionExpression:                 expr.Condition(),
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			}})
+		}
+
+		_, err := r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+		if isConditionCancellation(err) {
+			for i, transaction := range chunk {
+				if conditionFailedAt(err, i) {
+					return transactConflict(err, "transaction", transaction.ID)
+				}
+			}
+		}
+		if err != nil {
+			return storageError(err)
+		}
+	}
+	return nil
+}
+
+// moved reports whether dating transaction at occurredAt files it under
+// another sort key than the one it is stored under.
+func moved(transaction *model.Transaction, occurredAt time.Time) bool {
+	return model.TransactionDateKey(occurredAt.Format("2006-01-02"), transaction.ID) != transaction.SK
+}
+
+// rewriteTransaction updates a transaction by replacing its item: the
+// stored item is read, changed by apply and written back, under the key of
+// its new date when that changed, while the monthly aggregates take the
+// difference, in one transaction guarded by the version transaction names.
+func (r *TransactionRepo) rewriteTransaction(ctx context.Context, transaction *model.Transaction, apply func(*model.Transaction)) (*model.Transaction, error) {
+	logger.Info("Attempting to rewrite transaction", zap.String("transaction_id", transaction.ID))
+
+	stored, err := r.storedTransaction(ctx, transaction)
+	if err != nil {
+		logger.Error("Transaction not rewritten", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+
+	updated := *stored
+	apply(&updated)
+	updated.Version = stored.Version + 1
+	updated.SetKeys()
+
+	writes, err := r.replaceTransaction(stored, &updated)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := aggregateWrites(r.tableName, model.AggregateChanges(stored, &updated))
+	if err != nil {
+		logger.Error("Failed to build aggregate writes", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: append(writes, counts...)})
+	if isConditionCancellation(err) {
+		err = transactConflict(err, "transaction", transaction.ID)
+		logger.Error("Transaction not rewritten", err, zap.String("transaction_id", transaction.ID))
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to rewrite transaction in DynamoDB", err, zap.String("transaction_id", transaction.ID))
+		return nil, fmt.Errorf("failed to update transaction with ID '%s': %w", transaction.ID, storageError(err))
+	}
+
+	logger.Info("Transaction successfully rewritten", zap.String("transaction_id", transaction.ID), zap.String("sk", updated.SK))
+	return &updated, nil
+}
+
+// storedTransaction reads the item transaction names, consistently, and
+// fails unless it is still stored with the version transaction holds.
+func (r *TransactionRepo) storedTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	response, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            transaction.GetKey(),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction with ID '%s': %w", transaction.ID, storageError(err))
+	}
+
+	var stored model.Transaction
+	if len(response.Item) > 0 {
+		if err := attributevalue.UnmarshalMap(response.Item, &stored); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", storageError(err))
+		}
+	}
+	if len(response.Item) == 0 || stored.Version != transaction.Version {
+		return nil, versionConflict("transaction", transaction.ID, response.Item)
+	}
+	return &stored, nil
+}
+
+// replaceTransaction builds the writes that replace current, as stored, with
+// updated, guarded by the version of current. When updated is filed under
+// another sort key, current is deleted and updated put under its own key.
+func (r *TransactionRepo) replaceTransaction(current *model.Transaction, updated *model.Transaction) ([]types.TransactWriteItem, error) {
+	item, err := attributevalue.MarshalMap(updated)
+	if err != nil {
+		logger.Error("Failed to marshal transaction", err, zap.String("transaction_id", updated.ID))
+		return nil, fmt.Errorf("failed to marshal transaction: %w", storageError(err))
+	}
+	expr, err := expression.NewBuilder().WithCondition(versionCondition(current.Version)).Build()
+	if err != nil {
+		logger.Error("Failed to build condition expression", err, zap.String("transaction_id", updated.ID))
+		return nil, fmt.Errorf("failed to build condition expression: %w", storageError(err))
+	}
+
+	if updated.SK == current.SK {
+		return []types.TransactWriteItem{{Put: &types.Put{
+			TableName:                           aws.String(r.tableName),
+			Item:                                item,
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ConditionExpression:                 expr.Condition(),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}}}, nil
+	}
+	return []types.TransactWriteItem{
+		{Delete: &types.Delete{
+			TableName:                           aws.String(r.tableName),
+			Key:                                 current.GetKey(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ConditionExpression:                 expr.Condition(),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}},
+		{Put: &types.Put{
+			TableName:           aws.String(r.tableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(PK)"),
+		}},
+	}, nil
+}
+
+// versionCondition matches a stored item that still has version. Items
+// written before versioning have no version attribute and match version 0.
+func versionCondition(version int) expression.ConditionBuilder {
+	exists := expression.AttributeExists(expression.Name("PK"))
+	if version == 0 {
+		return exists.And(expression.AttributeNotExists(expression.Name("version")))
+	}
+	return exists.And(expression.Name("version").Equal(expression.Value(version)))
+}
+
+// versionConflict explains why a versioned write of the item id failed from
+// the items DynamoDB returned on the failed condition: none means the item
+// is gone, otherwise it is stored with another version.
+func versionConflict(kind string, id string, stored ...map[string]types.AttributeValue) error {
+	for _, item := range stored {
+		if len(item) == 0 {
+			return fmt.Errorf("%s with ID '%s': %w", kind, id, ErrNotFound)
+		}
+	}
+	return fmt.Errorf("%s with ID '%s': %w", kind, id, ErrVersionMismatch)
+}
+
+// setTags stores tags as a string set. DynamoDB rejects empty sets, so an
+// empty slice removes the attribute instead.
+func setTags(update expression.UpdateBuilder, tags []string) expression.UpdateBuilder {
+	if len(tags) == 0 {
+		return update.Remove(expression.Name("tags"))
+	}
+	return update.Set(expression.Name("tags"), expression.Value(&types.AttributeValueMemberSS{Value: tags}))
+}
+
+// setOptional adds one field of a patch to update: nothing when it was left
+// out, a REMOVE when it was sent as null and a SET otherwise.
+func setOptional[T any](update expression.UpdateBuilder, name string, field model.Optional[T]) expression.UpdateBuilder {
+	switch {
+	case !field.Set:
+		return update
+	case field.Value == nil:
+		return update.Remove(expression.Name(name))
+	}
+	return update.Set(expression.Name(name), expression.Value(*field.Value))
+}
+
+func toAttributeKey(key PageKey) map[string]types.AttributeValue {
+	if len(key) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]types.AttributeValue, len(key))
+	for name, value := range key {
+		attributes[name] = &types.AttributeValueMemberS{Value: value}
+	}
+	return attributes
+}
+
+func fromAttributeKey(attributes map[string]types.AttributeValue) PageKey {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	key := make(PageKey, len(attributes))
+	for name, value := range attributes {
+		if s, ok := value.(*types.AttributeValueMemberS); ok {
+			key[name] = s.Value
+		}
+	}
+	return key
 }
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/joaoleau/muquirango/internal/config/logger"
+	"github.com/joaoleau/muquirango/internal/model"
+	"go.uber.org/zap"
+)
+
+func (r *TransactionRepo) NewTransfer(ctx context.Context, transfer *model.Transfer, record *model.IdempotencyRecord) (*model.Transfer, error) {
+	logger.Info("Attempting to create new transfer", zap.String("transaction_id", transfer.From.ID))
+
+	transfer.From.Version = firstVersion
+	transfer.To.Version = firstVersion
+	writes, err := r.putTransfer(transfer, func(*model.Transaction) expression.ConditionBuilder {
+		return expression.AttributeNotExists(expression.Name("PK"))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = transactWrite(ctx, r.db, r.tableName, writes, record, transfer)
+	if errors.Is(err, ErrIdempotencyKeyUsed) {
+		logger.Error("Idempotency key already used", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+	if isConditionCancellation(err) {
+		logger.Error("Transfer already exists", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("transfer with ID '%s': %w", transfer.From.ID, ErrTransactionExists)
+	}
+	if err != nil {
+		logger.Error("Failed to add transfer to DynamoDB", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to add transfer: %w", storageError(err))
+	}
+
+	logger.Info("Transfer successfully created", zap.String("transaction_id", transfer.From.ID))
+	return transfer, nil
+}
+
+func (r *TransactionRepo) UpdateTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error) {
+	logger.Info("Attempting to update transfer", zap.String("transaction_id", transfer.From.ID))
+
+	// A leg whose date changed moves to the key of its new date.
+	updated := *transfer
+	writes := []types.TransactWriteItem{}
+	for _, legs := range [][2]*model.Transaction{{&transfer.From, &updated.From}, {&transfer.To, &updated.To}} {
+		current, leg := legs[0], legs[1]
+		leg.Version++
+		leg.SetKeys()
+		replace, err := r.replaceTransaction(current, leg)
+		if err != nil {
+			return nil, err
+		}
+		writes = append(writes, replace...)
+	}
+
+	_, err := r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	if isConditionCancellation(err) {
+		err = transactConflict(err, "transfer", transfer.From.ID)
+		logger.Error("Transfer not updated", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to update transfer in DynamoDB", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to update transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+	}
+
+	logger.Info("Transfer successfully updated", zap.String("transaction_id", transfer.From.ID))
+	return &updated, nil
+}
+
+func (r *TransactionRepo) DeleteTransfer(ctx context.Context, transfer *model.Transfer) (*model.Transfer, error) {
+	logger.Info("Attempting to delete transfer", zap.String("transaction_id", transfer.From.ID))
+
+	writes := []types.TransactWriteItem{}
+	for _, leg := range []*model.Transaction{&transfer.From, &transfer.To} {
+		expr, err := expression.NewBuilder().WithCondition(versionCondition(leg.Version)).Build()
+		if err != nil {
+			logger.Error("Failed to build condition expression", err, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("failed to build condition expression: %w", storageError(err))
+		}
+		writes = append(writes, types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                           aws.String(r.tableName),
+			Key:                                 leg.GetKey(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ConditionExpression:                 expr.Condition(),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}})
+	}
+
+	_, err := r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	if isConditionCancellation(err) {
+		err = transactConflict(err, "transfer", transfer.From.ID)
+		logger.Error("Transfer not deleted", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("Failed to delete transfer from DynamoDB", err, zap.String("transaction_id", transfer.From.ID))
+		return nil, fmt.Errorf("failed to delete transfer with ID '%s': %w", transfer.From.ID, storageError(err))
+	}
+
+	logger.Info("Transfer successfully deleted", zap.String("transaction_id", transfer.From.ID))
+	return transfer, nil
+}
+
+// putTransfer builds one Put per leg of transfer, guarded by the condition
+// built for that leg.
+func (r *TransactionRepo) putTransfer(transfer *model.Transfer, condition func(leg *model.Transaction) expression.ConditionBuilder) ([]types.TransactWriteItem, error) {
+	writes := []types.TransactWriteItem{}
+	for _, leg := range []*model.Transaction{&transfer.From, &transfer.To} {
+		item, err := attributevalue.MarshalMap(leg)
+		if err != nil {
+			logger.Error("Failed to marshal transfer leg", err, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("failed to marshal transfer leg: %w", storageError(err))
+		}
+		expr, err := expression.NewBuilder().WithCondition(condition(leg)).Build()
+		if err != nil {
+			logger.Error("Failed to build condition expression", err, zap.String("transaction_id", leg.ID))
+			return nil, fmt.Errorf("failed to build condition expression: %w", storageError(err))
+		}
+		writes = append(writes, types.TransactWriteItem{Put: &types.Put{
+			TableName:                           aws.String(r.tableName),
+			Item:                                item,
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ConditionExpression:                 expr.Condition(),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}})
+	}
+	return writes, nil
+}
+
+// transactConflict explains a versioned TransactWriteItems call cancelled
+// by a failed condition, from the items DynamoDB returned for the failures.
+func transactConflict(err error, kind string, id string) error {
+	var cancelled *types.TransactionCanceledException
+	errors.As(err, &cancelled)
+
+	var stored []map[string]types.AttributeValue
+	for _, reason := range cancelled.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			stored = append(stored, reason.Item)
+		}
+	}
+	return versionConflict(kind, id, stored...)
+}
+
+// isConditionCancellation reports whether a TransactWriteItems call was
+// cancelled because one of its condition expressions failed.
+func isConditionCancellation(err error) bool {
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) {
+		return false
+	}
+	for _, reason := range cancelled.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionFailedAt reports whether a TransactWriteItems call was cancelled
+// because the condition of the write at index failed.
+func conditionFailedAt(err error, index int) bool {
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) || index >= len(cancelled.CancellationReasons) {
+		return false
+	}
+	return aws.ToString(cancelled.CancellationReasons[index].Code) == "ConditionalCheckFailed"
+}
@@ -4,28 +4,164 @@ import (
 	"context"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/joaoleau/muquirango/internal/auth"
+	"github.com/joaoleau/muquirango/internal/cursor"
 	"github.com/joaoleau/muquirango/internal/handler"
 	"github.com/joaoleau/muquirango/internal/repository"
 )
 
 type Repositories struct {
-	TransactionRepo *repository.TransactionRepo
+	TransactionRepo  repository.TransactionRepository
+	CategoryRepo     repository.CategoryRepository
+	BudgetRepo       repository.BudgetRepository
+	RecurrenceRepo   repository.RecurrenceRepository
+	InstallmentRepo  repository.InstallmentRepository
+	AccountRepo      repository.AccountRepository
+	ExchangeRateRepo repository.ExchangeRateRepository
 }
 
-func RegisterRoutes(r *chi.Mux, repos *Repositories) () {
+func RegisterRoutes(r *chi.Mux, repos *Repositories, verifier *auth.Verifier, cursors *cursor.Codec, baseCurrency string) {
 	transactionHandler := handler.NewTransactionHandler(
 		context.Background(),
-		*repos.TransactionRepo,
+		repos.TransactionRepo,
+		repos.CategoryRepo,
+		repos.InstallmentRepo,
+		repos.AccountRepo,
+		cursors,
 	)
-	
+	categoryHandler := handler.NewCategoryHandler(
+		context.Background(),
+		repos.CategoryRepo,
+		repos.TransactionRepo,
+	)
+
+	tagHandler := handler.NewTagHandler(
+		context.Background(),
+		repos.TransactionRepo,
+	)
+
+	budgetHandler := handler.NewBudgetHandler(
+		context.Background(),
+		repos.BudgetRepo,
+		repos.CategoryRepo,
+		repos.TransactionRepo,
+		repos.ExchangeRateRepo,
+	)
+
+	recurrenceHandler := handler.NewRecurrenceHandler(
+		context.Background(),
+		repos.RecurrenceRepo,
+		repos.CategoryRepo,
+		repos.AccountRepo,
+		repos.TransactionRepo,
+	)
+
+	installmentHandler := handler.NewInstallmentHandler(
+		context.Background(),
+		repos.InstallmentRepo,
+		repos.CategoryRepo,
+	)
+
+	accountHandler := handler.NewAccountHandler(
+		context.Background(),
+		repos.AccountRepo,
+		repos.TransactionRepo,
+	)
+
+	cardHandler := handler.NewCardHandler(
+		context.Background(),
+		repos.AccountRepo,
+		repos.TransactionRepo,
+	)
+
+	exchangeRateHandler := handler.NewExchangeRateHandler(
+		context.Background(),
+		repos.ExchangeRateRepo,
+	)
+
+	reportHandler := handler.NewReportHandler(
+		context.Background(),
+		repos.TransactionRepo,
+		repos.CategoryRepo,
+		repos.ExchangeRateRepo,
+	)
+
 	r.Route("/api", func(r chi.Router) {
+		r.Use(auth.Middleware(verifier))
+		r.Use(handler.BaseCurrency(baseCurrency))
+
 		r.Route("/transaction", func(r chi.Router) {
 			r.Get("/", transactionHandler.ListTransactions)
 			r.Post("/", transactionHandler.NewTransaction)
 			r.Put("/{id}", transactionHandler.UpdateTransactionByID)
+			r.Patch("/{id}", transactionHandler.PatchTransactionByID)
 			r.Get("/{id}", transactionHandler.GetTransactionByID)
 			r.Delete("/{id}", transactionHandler.DeleteTransactionByID)
 		})
+
+		r.Route("/category", func(r chi.Router) {
+			r.Get("/", categoryHandler.ListCategories)
+			r.Post("/", categoryHandler.NewCategory)
+			r.Put("/{id}", categoryHandler.UpdateCategoryByID)
+			r.Get("/{id}", categoryHandler.GetCategoryByID)
+			r.Delete("/{id}", categoryHandler.DeleteCategoryByID)
+		})
+
+		r.Route("/account", func(r chi.Router) {
+			r.Get("/", accountHandler.ListAccounts)
+			r.Post("/", accountHandler.NewAccount)
+			r.Put("/{id}", accountHandler.UpdateAccountByID)
+			r.Get("/{id}", accountHandler.GetAccountByID)
+			r.Delete("/{id}", accountHandler.DeleteAccountByID)
+			r.Get("/{id}/balance", accountHandler.GetAccountBalance)
+			r.Get("/{id}/statement", accountHandler.GetAccountStatement)
+		})
+
+		r.Route("/card", func(r chi.Router) {
+			r.Get("/{id}/statement", cardHandler.GetCardStatement)
+			r.Post("/{id}/statement/pay", cardHandler.PayCardStatement)
+		})
+
+		r.Route("/budget", func(r chi.Router) {
+			r.Get("/", budgetHandler.ListBudgets)
+			r.Post("/", budgetHandler.NewBudget)
+			r.Put("/{id}", budgetHandler.UpdateBudgetByID)
+			r.Get("/{id}", budgetHandler.GetBudgetByID)
+			r.Delete("/{id}", budgetHandler.DeleteBudgetByID)
+			r.Get("/{id}/status", budgetHandler.GetBudgetStatus)
+		})
+
+		r.Route("/installment", func(r chi.Router) {
+			r.Get("/", installmentHandler.ListInstallmentPlans)
+			r.Put("/{id}", installmentHandler.UpdateInstallmentPlanByID)
+			r.Get("/{id}", installmentHandler.GetInstallmentPlanByID)
+			r.Delete("/{id}", installmentHandler.CancelInstallmentPlanByID)
+		})
+
+		r.Route("/recurrence", func(r chi.Router) {
+			r.Get("/", recurrenceHandler.ListRecurrences)
+			r.Post("/", recurrenceHandler.NewRecurrence)
+			r.Put("/{id}", recurrenceHandler.UpdateRecurrenceByID)
+			r.Get("/{id}", recurrenceHandler.GetRecurrenceByID)
+			r.Delete("/{id}", recurrenceHandler.DeleteRecurrenceByID)
+		})
+
+		r.Route("/tag", func(r chi.Router) {
+			r.Get("/", tagHandler.ListTags)
+			r.Put("/{tag}", tagHandler.RenameTag)
+		})
+
+		r.Route("/exchange-rate", func(r chi.Router) {
+			r.Get("/", exchangeRateHandler.ListExchangeRates)
+			r.Post("/", exchangeRateHandler.NewExchangeRate)
+			r.Post("/import", exchangeRateHandler.ImportExchangeRates)
+			r.Delete("/{base}/{quote}/{date}", exchangeRateHandler.DeleteExchangeRate)
+		})
+
+		r.Route("/report", func(r chi.Router) {
+			r.Get("/monthly", reportHandler.GetMonthlyReport)
+			r.Get("/categories", reportHandler.GetCategoryReport)
+		})
 	})
 
-}
\ No newline at end of file
+}
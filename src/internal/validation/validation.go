@@ -0,0 +1,104 @@
+// Package validation checks request input field by field, collecting every
+// problem so a client can fix them all in one round trip.
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError is one problem with one field of an input. Field is the JSON
+// name of the field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors lists every problem found in an input. Handlers answer it with 422
+// Unprocessable Entity and the list itself as the body.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = fmt.Sprintf("%s: %s", err.Field, err.Message)
+	}
+	return "invalid input: " + strings.Join(messages, "; ")
+}
+
+// Validator is implemented by inputs that check their own fields. Deserialize
+// calls it after decoding a request body.
+type Validator interface {
+	Validate() error
+}
+
+// Checker accumulates field errors. The zero value is ready to use.
+type Checker struct {
+	errors Errors
+}
+
+// Add records a problem with field.
+func (c *Checker) Add(field string, message string) {
+	c.errors = append(c.errors, FieldError{Field: field, Message: message})
+}
+
+// Check records message against field unless ok holds.
+func (c *Checker) Check(ok bool, field string, message string) {
+	if !ok {
+		c.Add(field, message)
+	}
+}
+
+// Required rejects a blank value.
+func (c *Checker) Required(field string, value string) {
+	c.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// MaxLength rejects a value longer than n characters.
+func (c *Checker) MaxLength(field string, value string, n int) {
+	c.Check(utf8.RuneCountInString(value) <= n, field, fmt.Sprintf("must be at most %d characters", n))
+}
+
+// Positive rejects zero and negative values.
+func (c *Checker) Positive(field string, value int) {
+	c.Check(value > 0, field, "must be positive")
+}
+
+// Between rejects values outside [low, high].
+func (c *Checker) Between(field string, value int, low int, high int) {
+	c.Check(value >= low && value <= high, field, fmt.Sprintf("must be between %d and %d", low, high))
+}
+
+// OneOf rejects a value that is not one of allowed.
+func (c *Checker) OneOf(field string, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	c.Add(field, fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")))
+}
+
+// Matches rejects a value that does not match pattern; example shows the
+// client what a valid value looks like.
+func (c *Checker) Matches(field string, value string, pattern *regexp.Regexp, example string) {
+	c.Check(pattern.MatchString(value), field, fmt.Sprintf("must look like %s", example))
+}
+
+// Date rejects a value that is not a date in layout; example shows the
+// client what a valid value looks like.
+func (c *Checker) Date(field string, value string, layout string, example string) {
+	_, err := time.Parse(layout, value)
+	c.Check(err == nil, field, fmt.Sprintf("must be a date like %s", example))
+}
+
+// Err returns the collected problems as Errors, or nil when there are none.
+func (c *Checker) Err() error {
+	if len(c.errors) == 0 {
+		return nil
+	}
+	return c.errors
+}